@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"log"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/google/uuid"
+	coapiv1 "github.com/metal-toolbox/conditionorc/pkg/api/v1/conditions/types"
+
+	"github.com/metal-toolbox/mctl/internal/app"
+	"github.com/metal-toolbox/mctl/internal/audit"
+	"github.com/metal-toolbox/mctl/internal/auth"
+	"github.com/metal-toolbox/mctl/pkg/model"
+)
+
+// secretFlags are the flags whose values are redacted from the audited command line, common password
+// and token flag names are listed with the mctl flags so a secret flag added later isn't recorded.
+var secretFlags = []string{
+	BMCPasswordFlag.name,
+	BMCPasswordFlag.short,
+	BMCPasswordFileFlag.name,
+	"password",
+	"pass",
+	"token",
+	"access-token",
+	"client-secret",
+	"secret",
+}
+
+// AuditLog appends a record of a completed mutating operation to the local audit journal.
+//
+// The api is the service the operation was performed on, its token subject is recorded
+// along with the local user. Failures to write the journal are logged and do not fail the command.
+func AuditLog(theApp *app.App, api model.APIKind, targets []string, results ...audit.Result) {
+	path, err := audit.DefaultPath()
+	if err != nil {
+		log.Printf("unable to write audit log: %s", err.Error())
+		return
+	}
+
+	record := &audit.Record{
+		Time:    time.Now().UTC(),
+		User:    localUser(),
+		Subject: tokenSubject(theApp, api),
+		Command: RootCmd.Name(),
+		Args:    audit.RedactArgs(os.Args, secretFlags...),
+		Targets: targets,
+		Results: results,
+	}
+
+	if c, _, err := RootCmd.Find(os.Args[1:]); err == nil {
+		record.Command = c.CommandPath()
+	}
+
+	if err := audit.New(path).Append(record); err != nil {
+		log.Printf("unable to write audit log: %s", err.Error())
+	}
+}
+
+// AuditCondition records a condition created on a server in the local audit journal.
+func AuditCondition(theApp *app.App, serverID uuid.UUID, response *coapiv1.ServerResponse, conditionID uuid.UUID) {
+	AuditLog(theApp, model.ConditionsAPI, []string{serverID.String()}, audit.Result{
+		Target:     serverID.String(),
+		ID:         conditionID.String(),
+		StatusCode: response.StatusCode,
+		Message:    response.Message,
+	})
+}
+
+func localUser() string {
+	u, err := user.Current()
+	if err != nil {
+		return os.Getenv("USER")
+	}
+
+	return u.Username
+}
+
+func tokenSubject(theApp *app.App, api model.APIKind) string {
+	var cfg *model.ConfigOIDC
+
+	switch api {
+	case model.FleetDBAPI:
+		cfg = theApp.Config.FleetDBAPI
+	case model.ConditionsAPI:
+		cfg = theApp.Config.Conditions
+	case model.BomsServiceAPI:
+		cfg = theApp.Config.BomService
+	}
+
+	subject, err := auth.TokenSubject(api, cfg)
+	if err != nil {
+		return ""
+	}
+
+	return subject
+}
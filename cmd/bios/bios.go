@@ -3,48 +3,64 @@ package bios
 import (
 	"context"
 	"log"
-	"net/url"
 
-	"github.com/google/uuid"
 	mctl "github.com/metal-toolbox/mctl/cmd"
 	"github.com/metal-toolbox/mctl/internal/app"
+	"github.com/metal-toolbox/mctl/pkg/model"
 	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
 var (
 	biosFlags *biosActionFlags
+
+	errBIOSConfigURL = errors.New("invalid bios config url")
+	errNoVendorAttrs = errors.New("unable to determine server vendor, model attributes")
 )
 
 type biosActionFlags struct {
 	serverID      string
 	biosConfigURL string
+	fromFile      string
+	dryRun        bool
 }
 
 func CreateBiosControlCondition(ctx context.Context, action rctypes.BiosControlAction) error {
 	theApp := mctl.MustCreateApp(ctx)
 
-	client, err := app.NewConditionsClient(ctx, theApp.Config.Conditions, theApp.Reauth)
-	if err != nil {
-		return err
-	}
-
-	serverID, err := uuid.Parse(biosFlags.serverID)
+	serverID, err := mctl.ResolveServerID(ctx, theApp, biosFlags.serverID)
 	if err != nil {
 		return err
 	}
 
 	var biosURL *rctypes.ConfigURL
 	if action == rctypes.SetConfig {
-		biosURLstr, err := url.Parse(biosFlags.biosConfigURL)
+		biosURL, err = biosConfigURL(ctx, theApp, serverID)
 		if err != nil {
 			return err
 		}
-		*biosURL = rctypes.ConfigURL(*biosURLstr)
 	}
 
 	params := rctypes.NewBiosControlTaskParameters(serverID, action, biosURL)
 
+	if biosFlags.dryRun {
+		mctl.PrintDryRun(&mctl.APIRequest{
+			API:      model.ConditionsAPI,
+			Method:   "ServerBiosControl",
+			ServerID: serverID.String(),
+			Kind:     rctypes.BiosControl,
+			Payload:  params,
+		})
+
+		return nil
+	}
+
+	client, err := app.NewConditionsClient(ctx, theApp.Config.Conditions, theApp.Reauth)
+	if err != nil {
+		return err
+	}
+
 	response, err := client.ServerBiosControl(ctx, params)
 	if err != nil {
 		return err
@@ -57,6 +73,8 @@ func CreateBiosControlCondition(ctx context.Context, action rctypes.BiosControlA
 
 	log.Printf("status=%d msg=%s conditionID=%s", response.StatusCode, response.Message, conditionResp.ID)
 
+	mctl.AuditCondition(theApp, serverID, response, conditionResp.ID)
+
 	return err
 }
 
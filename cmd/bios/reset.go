@@ -21,6 +21,7 @@ var resetCmd = &cobra.Command{
 
 func init() {
 	mctl.AddServerFlag(resetCmd, &biosFlags.serverID)
+	mctl.AddClientDryRunFlag(resetCmd, &biosFlags.dryRun)
 
 	mctl.RequireFlag(resetCmd, mctl.ServerFlag)
 
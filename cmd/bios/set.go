@@ -1,16 +1,42 @@
 package bios
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
 	"log"
+	"net/http"
+	"net/url"
+	"os"
 
+	"github.com/google/uuid"
+	fleetdbapi "github.com/metal-toolbox/fleetdb/pkg/api/v1"
 	mctl "github.com/metal-toolbox/mctl/cmd"
+	"github.com/metal-toolbox/mctl/internal/app"
+	"github.com/metal-toolbox/mctl/internal/inventory"
 	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
 var setCmd = &cobra.Command{
 	Use:   "set",
-	Short: "Set BIOS settings from github config file url",
+	Short: "Set BIOS settings from a github config file url",
+	Long: `Set BIOS settings from a github config file url.
+
+With --from-file the local copy of the file served at --bios-config-url is checked against the URL
+and validated for the server vendor. JSON files are accepted for vendors other than Dell and Supermicro,
+which require their server configuration profile and SUM XML exports. The settings the file changes on
+the server are listed before the condition is created.`,
+	Example: `  # apply a BIOS config file
+  mctl bios set --server fd5c3a0b-4d2f-4c6c-8e7b-32b0f1d0b4a1 --bios-config-url https://raw.githubusercontent.com/<>/r6515.json
+
+  # validate a server configuration profile export and list the settings it changes before applying it
+  mctl bios set --server fd5c3a0b-4d2f-4c6c-8e7b-32b0f1d0b4a1 \
+    --bios-config-url https://raw.githubusercontent.com/<>/r6515-bios.xml --from-file r6515-bios.xml`,
 	Run: func(cmd *cobra.Command, _ []string) {
 		err := CreateBiosControlCondition(cmd.Context(), rctypes.SetConfig)
 		if err != nil {
@@ -19,9 +45,138 @@ var setCmd = &cobra.Command{
 	},
 }
 
+// biosConfigURL returns the URL of the BIOS configuration to apply to the server,
+// the file given with --from-file is validated and its changes are listed.
+func biosConfigURL(ctx context.Context, theApp *app.App, serverID uuid.UUID) (*rctypes.ConfigURL, error) {
+	configURL, err := parseConfigURL(biosFlags.biosConfigURL)
+	if err != nil || biosFlags.fromFile == "" {
+		return configURL, err
+	}
+
+	data, err := os.ReadFile(biosFlags.fromFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// the worker applies the configuration served at the URL
+	if err := verifyBIOSConfigURL(ctx, configURL, data); err != nil {
+		return nil, err
+	}
+
+	client, err := app.NewFleetDBAPIClient(ctx, theApp.Config.FleetDBAPI, theApp.Reauth)
+	if err != nil {
+		return nil, err
+	}
+
+	server, _, err := client.Get(ctx, serverID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve server object")
+	}
+
+	vendor, _ := mctl.VendorModelFromAttrs(server.Attributes)
+	if vendor == "" {
+		return nil, errNoVendorAttrs
+	}
+
+	settings, err := inventory.ParseBIOSConfig(data, vendor)
+	if err != nil {
+		return nil, errors.Wrap(err, biosFlags.fromFile)
+	}
+
+	previewBIOSSettings(ctx, client, serverID, settings)
+
+	return configURL, nil
+}
+
+// verifyBIOSConfigURL returns an error when the URL doesn't serve the data.
+func verifyBIOSConfigURL(ctx context.Context, configURL *rctypes.ConfigURL, data []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, mctl.CmdTimeout)
+	defer cancel()
+
+	rawURL := (*url.URL)(configURL).String()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, http.NoBody)
+	if err != nil {
+		return errors.Wrap(errBIOSConfigURL, err.Error())
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.Wrap(errBIOSConfigURL, err.Error())
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Wrap(errBIOSConfigURL, fmt.Sprintf("%s: %s", rawURL, resp.Status))
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, resp.Body); err != nil {
+		return errors.Wrap(errBIOSConfigURL, err.Error())
+	}
+
+	sum := sha256.Sum256(data)
+	if !bytes.Equal(h.Sum(nil), sum[:]) {
+		return errors.Wrap(errBIOSConfigURL, rawURL+" doesn't serve the contents of "+biosFlags.fromFile)
+	}
+
+	return nil
+}
+
+// parseConfigURL returns the URL when it is an absolute http(s) URL.
+func parseConfigURL(raw string) (*rctypes.ConfigURL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, errors.Wrap(errBIOSConfigURL, err.Error())
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.Wrap(errBIOSConfigURL, "expected an absolute http(s) URL: "+raw)
+	}
+
+	return (*rctypes.ConfigURL)(u), nil
+}
+
+// previewBIOSSettings prints the settings that change on the server,
+// settings the server reports that aren't in the file are left as is.
+func previewBIOSSettings(ctx context.Context, client *fleetdbapi.Client, serverID uuid.UUID, settings map[string]string) {
+	actual, err := mctl.ServerBIOSConfig(ctx, client, serverID)
+	if err != nil {
+		log.Printf("unable to preview changes: %s", err.Error())
+		return
+	}
+
+	if len(actual) == 0 {
+		fmt.Println("no bios configuration data found, unable to preview changes")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Setting", "Current", "New"})
+	table.SetAutoWrapText(false)
+
+	for _, d := range inventory.CompareBIOS(settings, actual) {
+		if d.Kind == inventory.Extra {
+			continue
+		}
+
+		table.Append([]string{d.Key, d.Actual, d.Expected})
+	}
+
+	if table.NumLines() == 0 {
+		fmt.Println("no settings are changed")
+		return
+	}
+
+	table.Render()
+}
+
 func init() {
 	mctl.AddServerFlag(setCmd, &biosFlags.serverID)
+	mctl.AddClientDryRunFlag(setCmd, &biosFlags.dryRun)
 	mctl.AddBIOSConfigURLFlag(setCmd, &biosFlags.biosConfigURL)
+	mctl.AddFromFileFlag(setCmd, &biosFlags.fromFile, "path to the BIOS config file served at the bios-config-url, to validate and preview")
 
 	mctl.RequireFlag(setCmd, mctl.ServerFlag)
 	mctl.RequireFlag(setCmd, mctl.BIOSConfigURLFlag)
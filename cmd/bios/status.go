@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"log"
 
-	"github.com/google/uuid"
 	mctl "github.com/metal-toolbox/mctl/cmd"
 	"github.com/metal-toolbox/mctl/internal/app"
 	rctypes "github.com/metal-toolbox/rivets/v2/condition"
@@ -28,7 +27,7 @@ func getConditionStatus(ctx context.Context) {
 		log.Fatal(err)
 	}
 
-	id, err := uuid.Parse(biosFlags.serverID)
+	id, err := mctl.ResolveServerID(ctx, theApp, biosFlags.serverID)
 	if err != nil {
 		log.Fatal(err)
 	}
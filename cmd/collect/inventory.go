@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"log"
 
-	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 
 	coapiv1 "github.com/metal-toolbox/conditionorc/pkg/api/v1/conditions/types"
@@ -13,12 +12,14 @@ import (
 
 	mctl "github.com/metal-toolbox/mctl/cmd"
 	"github.com/metal-toolbox/mctl/internal/app"
+	"github.com/metal-toolbox/mctl/pkg/model"
 )
 
 type collectInventoryFlags struct {
 	serverID                  string
 	skipFirmwareStatusCollect bool
 	skipBiosConfigCollect     bool
+	dryRun                    bool
 }
 
 var (
@@ -37,7 +38,7 @@ var collectInventoryCmd = &cobra.Command{
 func collectInventory(ctx context.Context) {
 	theApp := mctl.MustCreateApp(ctx)
 
-	serverID, err := uuid.Parse(flagsDefinedCollectInventory.serverID)
+	serverID, err := mctl.ResolveServerID(ctx, theApp, flagsDefinedCollectInventory.serverID)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -56,6 +57,18 @@ func collectInventory(ctx context.Context) {
 		Parameters: params,
 	}
 
+	if flagsDefinedCollectInventory.dryRun {
+		mctl.PrintDryRun(&mctl.APIRequest{
+			API:      model.ConditionsAPI,
+			Method:   "ServerConditionCreate",
+			ServerID: serverID.String(),
+			Kind:     rctypes.Inventory,
+			Payload:  conditionCreate,
+		})
+
+		return
+	}
+
 	client, err := app.NewConditionsClient(ctx, theApp.Config.Conditions, theApp.Reauth)
 	if err != nil {
 		log.Fatal(err)
@@ -72,6 +85,8 @@ func collectInventory(ctx context.Context) {
 	}
 
 	log.Printf("status=%d msg=%s conditionID=%s", response.StatusCode, response.Message, condition.ID)
+
+	mctl.AuditCondition(theApp, serverID, response, condition.ID)
 }
 
 func init() {
@@ -80,6 +95,7 @@ func init() {
 	mctl.AddServerFlag(collectInventoryCmd, &flagsDefinedCollectInventory.serverID)
 	mctl.AddSkipFWStatusFlag(collectInventoryCmd, &flagsDefinedCollectInventory.skipFirmwareStatusCollect)
 	mctl.AddSkipBiosConfigFlag(collectInventoryCmd, &flagsDefinedCollectInventory.skipBiosConfigCollect)
+	mctl.AddClientDryRunFlag(collectInventoryCmd, &flagsDefinedCollectInventory.dryRun)
 
 	mctl.RequireFlag(collectInventoryCmd, mctl.ServerFlag)
 }
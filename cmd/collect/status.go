@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"log"
 
-	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 
 	rctypes "github.com/metal-toolbox/rivets/v2/condition"
@@ -36,7 +35,7 @@ func statusCheck(ctx context.Context) {
 		log.Fatal(err)
 	}
 
-	serverID, err := uuid.Parse(inventoryStatusFlags.serverID)
+	serverID, err := mctl.ResolveServerID(ctx, theApp, inventoryStatusFlags.serverID)
 	if err != nil {
 		log.Fatalf("parsing server id: %s", err.Error())
 	}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"os"
 	"strings"
 	"time"
 
@@ -14,8 +15,11 @@ import (
 	coapiv1 "github.com/metal-toolbox/conditionorc/pkg/api/v1/conditions/types"
 	fleetdbapi "github.com/metal-toolbox/fleetdb/pkg/api/v1"
 	"github.com/metal-toolbox/mctl/internal/app"
+	"github.com/metal-toolbox/mctl/internal/fleetdb"
+	"github.com/metal-toolbox/mctl/internal/inventory"
 	rctypes "github.com/metal-toolbox/rivets/v2/condition"
 	rt "github.com/metal-toolbox/rivets/v2/types"
+	"github.com/olekukonko/tablewriter"
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 )
@@ -24,11 +28,22 @@ var (
 	ErrAttributeFromLabel = errors.New("error creating Attribute from Label")
 	ErrLabelFromAttribute = errors.New("error creating Label from Attribute")
 	ErrFwSetByVendorModel = errors.New("error identifying firmware set by server vendor, model")
+	ErrBIOSConfigSet      = errors.New("error retrieving bios config set")
+	ErrSince              = errors.New("invalid --since value, expected a duration like 24h or a date like 2006-01-02 or an RFC3339 time")
 )
 
 const (
 	CmdTimeout = 20 * time.Second
 
+	// maxFirmwareSetPages limits the number of pages of firmware sets listed.
+	maxFirmwareSetPages = 50
+
+	// maxComponentPages limits the number of pages of components listed for a server.
+	maxComponentPages = 10
+
+	// maxBIOSConfigSetPages limits the number of pages of BIOS config sets listed.
+	maxBIOSConfigSetPages = 50
+
 	// TODO: merge constants along with the ones in Alloy into a separate library
 	ServerVendorAttributeNS = "sh.hollow.alloy.server_vendor_attributes"
 	FirmwareSetAttributeNS  = "sh.hollow.firmware_set.labels"
@@ -83,32 +98,157 @@ func VendorModelFromAttrs(attrs []fleetdbapi.Attributes) (vendor, model string)
 	return bmclibcomm.FormatVendorName(data["vendor"]), bmclibcomm.FormatProductName(data["model"])
 }
 
-// FirmwareSetIDByVendorModel returns the firmware set ID matched by the vendor, model attributes
-//
-//nolint:whitespace // you have stupid opinions, be silent
-func FirmwareSetIDByVendorModel(ctx context.Context, vendor, model string,
-	client *fleetdbapi.Client) (uuid.UUID, error) {
+// ListFirmwareSets returns the firmware sets on all pages
+func ListFirmwareSets(ctx context.Context, client *fleetdbapi.Client) ([]fleetdbapi.ComponentFirmwareSet, error) {
+	sets := []fleetdbapi.ComponentFirmwareSet{}
 
-	params := &fleetdbapi.ComponentFirmwareSetListParams{
-		Vendor: strings.TrimSpace(vendor),
-		Model:  strings.TrimSpace(model),
-		Labels: "default=true,latest=true",
+	for page := 1; page <= maxFirmwareSetPages; page++ {
+		params := &fleetdbapi.ComponentFirmwareSetListParams{
+			Pagination: &fleetdbapi.PaginationParams{
+				Limit: fleetdbapi.MaxPaginationSize,
+				Page:  page,
+			},
+		}
+
+		found, resp, err := client.ListServerComponentFirmwareSet(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+
+		sets = append(sets, found...)
+
+		if resp == nil || page >= resp.TotalPages {
+			break
+		}
 	}
 
-	// identify firmware set by vendor, model attributes
-	fwSet, _, err := client.ListServerComponentFirmwareSet(ctx, params)
+	return sets, nil
+}
+
+// ServerComponents returns the components of the server.
+func ServerComponents(ctx context.Context, client *fleetdbapi.Client, serverID uuid.UUID) ([]fleetdbapi.ServerComponent, error) {
+	components := []fleetdbapi.ServerComponent{}
+
+	for page := 1; page <= maxComponentPages; page++ {
+		params := &fleetdbapi.PaginationParams{
+			Limit: fleetdbapi.MaxPaginationSize,
+			Page:  page,
+		}
+
+		found, resp, err := client.GetComponents(ctx, serverID, params)
+		if err != nil {
+			return nil, err
+		}
+
+		components = append(components, found...)
+
+		if resp == nil || page >= resp.TotalPages {
+			break
+		}
+	}
+
+	return components, nil
+}
+
+// BIOSConfigSetByID returns the BIOS config set with the ID
+func BIOSConfigSetByID(ctx context.Context, client *fleetdbapi.Client, id uuid.UUID) (*fleetdbapi.BiosConfigSet, error) {
+	resp, err := client.GetServerBiosConfigSet(ctx, id)
 	if err != nil {
-		return uuid.Nil, err
+		return nil, errors.Wrap(ErrBIOSConfigSet, err.Error())
 	}
 
-	log.Printf(
-		"fw sets identified for vendor: %s, model: %s, fwset: %s\n",
-		vendor,
-		model,
-		fwSet[0].UUID.String(),
-	)
+	set, ok := resp.Records.(*fleetdbapi.BiosConfigSet)
+	if !ok || set == nil {
+		return nil, errors.Wrap(ErrBIOSConfigSet, "unexpected response for "+id.String())
+	}
+
+	return set, nil
+}
+
+// BIOSConfigSetFromFile reads a BIOS config set from a JSON file
+func BIOSConfigSetFromFile(path string) (*fleetdbapi.BiosConfigSet, error) {
+	set := &fleetdbapi.BiosConfigSet{}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(b, set); err != nil {
+		return nil, errors.Wrap(err, path)
+	}
+
+	return set, nil
+}
+
+// ListBIOSConfigSets returns the BIOS config sets on the page, or on all pages when the page is 0
+func ListBIOSConfigSets(ctx context.Context, client *fleetdbapi.Client, page, limit int) ([]fleetdbapi.BiosConfigSet, error) {
+	sets := []fleetdbapi.BiosConfigSet{}
+
+	lastPage := page
+	if page == 0 {
+		page, lastPage = 1, maxBIOSConfigSetPages
+	}
+
+	for ; page <= lastPage; page++ {
+		params := &fleetdbapi.BiosConfigSetListParams{
+			Pagination: fleetdbapi.PaginationParams{
+				Limit:   limit,
+				Page:    page,
+				Preload: true,
+			},
+		}
+
+		resp, err := client.ListServerBiosConfigSet(ctx, params)
+		if err != nil {
+			return nil, errors.Wrap(ErrBIOSConfigSet, err.Error())
+		}
+
+		if found, ok := resp.Records.(*[]fleetdbapi.BiosConfigSet); ok && found != nil {
+			sets = append(sets, *found...)
+		}
+
+		if page >= resp.TotalPages {
+			break
+		}
+	}
 
-	return fwSet[0].UUID, nil
+	return sets, nil
+}
+
+// BIOSConfigHistory returns the recorded BIOS configurations of the server, preferring the inband namespace.
+func BIOSConfigHistory(ctx context.Context, client *fleetdbapi.Client, serverID uuid.UUID) ([]fleetdbapi.VersionedAttributes, error) {
+	for _, ns := range []string{fleetdb.BiosConfigInbandNS, fleetdb.BiosConfigOutofbandNS} {
+		history, _, err := client.GetVersionedAttributes(ctx, serverID, ns)
+		if err != nil {
+			if strings.Contains(err.Error(), "resource not found") {
+				continue
+			}
+
+			return nil, err
+		}
+
+		if len(history) > 0 {
+			return history, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// ServerBIOSConfig returns the latest recorded BIOS configuration of the server.
+func ServerBIOSConfig(ctx context.Context, client *fleetdbapi.Client, serverID uuid.UUID) (map[string]string, error) {
+	biosHistory, err := BIOSConfigHistory(ctx, client, serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := fleetdb.InventorySnapshot(serverID, nil, biosHistory, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot.BIOS, nil
 }
 
 type ErrUnexpectedResponse struct {
@@ -202,6 +342,21 @@ func FormatConditionResponse(response *coapiv1.ServerResponse, kind rctypes.Kind
 	return string(b), nil
 }
 
+// ParseSince returns the time for a duration before now, a date or an RFC3339 time.
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, errors.Wrap(ErrSince, value)
+}
+
 func PrintResults(format string, data ...any) {
 	switch format {
 	case "text":
@@ -216,6 +371,53 @@ func PrintResults(format string, data ...any) {
 	}
 }
 
+// PrintPathChanges prints the differences between two objects, the labels name the compared objects in the text output.
+func PrintPathChanges(format string, changes []inventory.PathChange, oldLabel, newLabel string) {
+	if format != OutputTypeText.String() {
+		b, err := json.MarshalIndent(changes, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Println(string(b))
+
+		return
+	}
+
+	if len(changes) == 0 {
+		fmt.Println("no differences")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Path", "Kind", oldLabel, newLabel})
+	table.SetAutoWrapText(false)
+
+	for _, c := range changes {
+		table.Append([]string{c.Path, string(c.Kind), formatPathValue(c.Old), formatPathValue(c.New)})
+	}
+
+	table.Render()
+}
+
+func formatPathValue(v any) string {
+	if v == nil {
+		return "-"
+	}
+
+	switch v.(type) {
+	case string, int, int64, bool:
+		return fmt.Sprint(v)
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return string(b)
+}
+
 // Query server BMC credentials and update the given server object
 func ServerBMCCredentials(ctx context.Context, client *fleetdbapi.Client, server *rt.Server) error {
 	cred, _, err := client.GetCredential(ctx, uuid.MustParse(server.ID), fleetdbapi.ServerCredentialTypeBMC)
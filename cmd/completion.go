@@ -0,0 +1,342 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	common "github.com/metal-toolbox/bmc-common"
+	fleetdbapi "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+	"github.com/spf13/cobra"
+
+	"github.com/metal-toolbox/mctl/internal/app"
+	"github.com/metal-toolbox/mctl/internal/cache"
+	"github.com/metal-toolbox/mctl/internal/fleetdb"
+)
+
+const (
+	// completionCacheTTL is how long fleetdb objects listed for completions are reused.
+	completionCacheTTL = 5 * time.Minute
+
+	// completionTimeout bounds the fleetdb queries made for a completion.
+	completionTimeout = 10 * time.Second
+
+	// completionMaxPages limits the number of pages of servers and firmware listed for completions.
+	completionMaxPages = 10
+)
+
+// completionServer is the server data cached for completions.
+type completionServer struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Serial string `json:"serial"`
+	Vendor string `json:"vendor"`
+	Model  string `json:"model"`
+}
+
+// completionFirmware is the firmware data cached for completions.
+type completionFirmware struct {
+	ID        string   `json:"id"`
+	Vendor    string   `json:"vendor"`
+	Model     []string `json:"model"`
+	Component string   `json:"component"`
+	Version   string   `json:"version"`
+}
+
+type completionFunc func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective)
+
+// registerCompletion registers a completion function for the flag.
+func registerCompletion(cmd *cobra.Command, flag *flagDetails, fn completionFunc) {
+	if err := cmd.RegisterFlagCompletionFunc(flag.name, fn); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// fleetDBCompletion returns a completion function for the values listed by fn.
+func fleetDBCompletion(fn func(ctx context.Context, theApp *app.App) ([]string, error)) completionFunc {
+	return func(cmd *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, completionTimeout)
+		defer cancel()
+
+		theApp, err := app.New(ctx, cfgFile, false)
+		if err != nil {
+			cobra.CompErrorln(err.Error())
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		values, err := fn(ctx, theApp)
+		if err != nil {
+			cobra.CompErrorln(err.Error())
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		return filterCompletions(values, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// filterCompletions returns the values with the given prefix, the description after a tab is not matched.
+func filterCompletions(values []string, prefix string) []string {
+	prefix = strings.ToLower(prefix)
+
+	filtered := []string{}
+	for _, v := range values {
+		value, _, _ := strings.Cut(v, "\t")
+		if strings.HasPrefix(strings.ToLower(value), prefix) {
+			filtered = append(filtered, v)
+		}
+	}
+
+	return filtered
+}
+
+// cachedList returns the cached objects for the name, listing them from fleetdb when the cache has expired.
+//
+// Objects are cached per fleetdb endpoint so configurations for different environments are kept apart.
+func cachedList[T any](
+	ctx context.Context,
+	theApp *app.App,
+	name string,
+	list func(context.Context, *fleetdbapi.Client) ([]T, error),
+) ([]T, error) {
+	if theApp.Config.FleetDBAPI == nil {
+		return nil, app.ErrNilConfig
+	}
+
+	c := cache.New(cache.DefaultDir("completion"), completionCacheTTL)
+	key := name + ":" + theApp.Config.FleetDBAPI.Endpoint
+
+	var objs []T
+	if c.Get(key, &objs) {
+		return objs, nil
+	}
+
+	client, err := app.NewFleetDBAPIClientFromKeyring(ctx, theApp.Config.FleetDBAPI)
+	if err != nil {
+		return nil, err
+	}
+
+	objs, err = list(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.Set(key, objs); err != nil {
+		cobra.CompDebugln(err.Error(), false)
+	}
+
+	return objs, nil
+}
+
+func completionServers(ctx context.Context, theApp *app.App) ([]completionServer, error) {
+	return cachedList(ctx, theApp, "servers", func(ctx context.Context, client *fleetdbapi.Client) ([]completionServer, error) {
+		objs := []completionServer{}
+
+		for page := 1; page <= completionMaxPages; page++ {
+			params := &fleetdbapi.ServerListParams{
+				PaginationParams: &fleetdbapi.PaginationParams{
+					Limit: fleetdbapi.MaxPaginationSize,
+					Page:  page,
+				},
+			}
+
+			servers, resp, err := client.List(ctx, params)
+			if err != nil {
+				return nil, err
+			}
+
+			for idx := range servers {
+				s := fleetdb.ConvertServer(&servers[idx])
+				objs = append(objs, completionServer{ID: s.ID, Name: s.Name, Serial: s.Serial, Vendor: s.Vendor, Model: s.Model})
+			}
+
+			if resp == nil || page >= resp.TotalPages {
+				break
+			}
+		}
+
+		return objs, nil
+	})
+}
+
+func completionFirmwares(ctx context.Context, theApp *app.App) ([]completionFirmware, error) {
+	return cachedList(ctx, theApp, "firmware", func(ctx context.Context, client *fleetdbapi.Client) ([]completionFirmware, error) {
+		objs := []completionFirmware{}
+
+		for page := 1; page <= completionMaxPages; page++ {
+			params := &fleetdbapi.ComponentFirmwareVersionListParams{
+				Pagination: &fleetdbapi.PaginationParams{
+					Limit: fleetdbapi.MaxPaginationSize,
+					Page:  page,
+				},
+			}
+
+			firmwares, resp, err := client.ListServerComponentFirmware(ctx, params)
+			if err != nil {
+				return nil, err
+			}
+
+			for idx := range firmwares {
+				fw := &firmwares[idx]
+				objs = append(objs, completionFirmware{
+					ID:        fw.UUID.String(),
+					Vendor:    fw.Vendor,
+					Model:     fw.Model,
+					Component: fw.Component,
+					Version:   fw.Version,
+				})
+			}
+
+			if resp == nil || page >= resp.TotalPages {
+				break
+			}
+		}
+
+		return objs, nil
+	})
+}
+
+func completeServers(ctx context.Context, theApp *app.App) ([]string, error) {
+	servers, err := completionServers(ctx, theApp)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]string, 0, len(servers))
+	for _, s := range servers {
+		values = append(values, s.ID+"\t"+strings.Join(nonEmpty(s.Name, s.Serial, s.Vendor, s.Model), " "))
+	}
+
+	return values, nil
+}
+
+func completeFirmware(ctx context.Context, theApp *app.App) ([]string, error) {
+	firmwares, err := completionFirmwares(ctx, theApp)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]string, 0, len(firmwares))
+	for _, fw := range firmwares {
+		values = append(values, fw.ID+"\t"+strings.Join(nonEmpty(fw.Vendor, fw.Component, fw.Version), " "))
+	}
+
+	return values, nil
+}
+
+func completeFirmwareSets(ctx context.Context, theApp *app.App) ([]string, error) {
+	return cachedList(ctx, theApp, "firmware-sets", func(ctx context.Context, client *fleetdbapi.Client) ([]string, error) {
+		sets, err := ListFirmwareSets(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		values := make([]string, 0, len(sets))
+		for idx := range sets {
+			values = append(values, sets[idx].UUID.String()+"\t"+sets[idx].Name)
+		}
+
+		return values, nil
+	})
+}
+
+func completeBIOSConfigSets(ctx context.Context, theApp *app.App) ([]string, error) {
+	return cachedList(ctx, theApp, "bios-config-sets", func(ctx context.Context, client *fleetdbapi.Client) ([]string, error) {
+		sets, err := ListBIOSConfigSets(ctx, client, 0, fleetdbapi.MaxPaginationSize)
+		if err != nil {
+			return nil, err
+		}
+
+		values := make([]string, 0, len(sets))
+		for _, set := range sets {
+			values = append(values, set.ID+"\t"+strings.Join(nonEmpty(set.Name, set.Version), " "))
+		}
+
+		return values, nil
+	})
+}
+
+// completeVendors lists the vendors of the servers and firmware in fleetdb.
+func completeVendors(ctx context.Context, theApp *app.App) ([]string, error) {
+	return completeServerFirmwareValues(ctx, theApp,
+		func(s *completionServer) []string { return []string{s.Vendor} },
+		func(fw *completionFirmware) []string { return []string{fw.Vendor} },
+	)
+}
+
+// completeModels lists the models of the servers and firmware in fleetdb.
+func completeModels(ctx context.Context, theApp *app.App) ([]string, error) {
+	return completeServerFirmwareValues(ctx, theApp,
+		func(s *completionServer) []string { return []string{s.Model} },
+		func(fw *completionFirmware) []string { return fw.Model },
+	)
+}
+
+func completeServerFirmwareValues(
+	ctx context.Context,
+	theApp *app.App,
+	fromServer func(*completionServer) []string,
+	fromFirmware func(*completionFirmware) []string,
+) ([]string, error) {
+	found := map[string]bool{}
+
+	servers, err := completionServers(ctx, theApp)
+	if err != nil {
+		return nil, err
+	}
+
+	for idx := range servers {
+		for _, v := range fromServer(&servers[idx]) {
+			found[strings.ToLower(v)] = true
+		}
+	}
+
+	firmwares, err := completionFirmwares(ctx, theApp)
+	if err != nil {
+		return nil, err
+	}
+
+	for idx := range firmwares {
+		for _, v := range fromFirmware(&firmwares[idx]) {
+			found[strings.ToLower(v)] = true
+		}
+	}
+
+	delete(found, "")
+
+	values := make([]string, 0, len(found))
+	for v := range found {
+		values = append(values, v)
+	}
+
+	sort.Strings(values)
+
+	return values, nil
+}
+
+// completeSlugs lists the component type slugs, these are fixed and don't require a fleetdb query.
+func completeSlugs(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	slugs := make([]string, 0, len(common.ComponentTypes()))
+	for _, slug := range common.ComponentTypes() {
+		slugs = append(slugs, strings.ToLower(slug))
+	}
+
+	return filterCompletions(slugs, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func nonEmpty(values ...string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+
+	"github.com/metal-toolbox/mctl/internal/app"
+	"github.com/metal-toolbox/mctl/internal/fleetdb"
+)
+
+const (
+	// DefaultDestructiveThreshold is used when destructive_threshold is not set in the configuration.
+	DefaultDestructiveThreshold = 5
+
+	confirmationText = "yes"
+)
+
+var (
+	ErrNotConfirmed       = errors.New("operation was not confirmed")
+	ErrNonInteractive     = errors.New("confirmation required, re-run with --yes to skip the prompt")
+	ErrDestructiveTargets = errors.New("number of objects exceeds the destructive_threshold, re-run with --allow-bulk to override")
+)
+
+// ConfirmParams holds the flag values that control the confirmation of destructive operations.
+type ConfirmParams struct {
+	// skip the interactive prompt
+	Yes bool
+	// allow acting on more objects than the destructive threshold
+	AllowBulk bool
+}
+
+// Destructive describes a destructive operation and the objects it affects.
+type Destructive struct {
+	// Action is a short description of the operation - 'delete server'.
+	Action string
+	// Header is the table header for the affected objects.
+	Header []string
+	// Rows describes each affected object.
+	Rows [][]string
+}
+
+// ConfirmDestructive prints a summary of the objects affected by a destructive operation
+// and requires the user to type 'yes' to continue, unless --yes was given.
+//
+// Operations affecting more objects than the configured threshold are refused unless --allow-bulk was given.
+func ConfirmDestructive(theApp *app.App, op *Destructive, params *ConfirmParams) error {
+	threshold := DefaultDestructiveThreshold
+	if theApp.Config.DestructiveThreshold > 0 {
+		threshold = theApp.Config.DestructiveThreshold
+	}
+
+	return confirm(os.Stdin, os.Stderr, stdinIsTerminal(), threshold, op, params)
+}
+
+func confirm(in io.Reader, out io.Writer, interactive bool, threshold int, op *Destructive, params *ConfirmParams) error {
+	if len(op.Rows) > threshold && !params.AllowBulk {
+		return errors.Wrap(
+			ErrDestructiveTargets,
+			fmt.Sprintf("%s on %d objects, threshold: %d", op.Action, len(op.Rows), threshold),
+		)
+	}
+
+	fmt.Fprintf(out, "The following %d object(s) will be affected by '%s':\n", len(op.Rows), op.Action)
+
+	table := tablewriter.NewWriter(out)
+	table.SetHeader(op.Header)
+	table.AppendBulk(op.Rows)
+	table.Render()
+
+	if params.Yes {
+		return nil
+	}
+
+	if !interactive {
+		return ErrNonInteractive
+	}
+
+	fmt.Fprintf(out, "Type '%s' to continue: ", confirmationText)
+
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return errors.Wrap(ErrNotConfirmed, err.Error())
+	}
+
+	if strings.TrimSpace(answer) != confirmationText {
+		return ErrNotConfirmed
+	}
+
+	return nil
+}
+
+func stdinIsTerminal() bool {
+	fi, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// ServerSummaryHeader is the table header for the rows returned by ServerSummaryRows.
+var ServerSummaryHeader = []string{"ID", "Name", "Serial", "Facility"}
+
+// ServerSummaryRows returns the name, serial and facility of the given servers from fleetdb,
+// servers that could not be looked up are listed with their ID and the lookup error.
+func ServerSummaryRows(ctx context.Context, theApp *app.App, serverIDs ...uuid.UUID) [][]string {
+	rows := make([][]string, 0, len(serverIDs))
+
+	client, err := app.NewFleetDBAPIClient(ctx, theApp.Config.FleetDBAPI, theApp.Reauth)
+	if err != nil {
+		log.Printf("unable to lookup server details: %s", err.Error())
+	}
+
+	for _, id := range serverIDs {
+		if client == nil {
+			rows = append(rows, []string{id.String(), "-", "-", "-"})
+			continue
+		}
+
+		server, _, err := client.Get(ctx, id)
+		if err != nil {
+			rows = append(rows, []string{id.String(), "lookup error: " + err.Error(), "-", "-"})
+			continue
+		}
+
+		s := fleetdb.ConvertServer(server)
+		rows = append(rows, []string{s.ID, s.Name, s.Serial, s.Facility})
+	}
+
+	return rows
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfirm(t *testing.T) {
+	op := &Destructive{
+		Action: "delete server",
+		Header: ServerSummaryHeader,
+		Rows: [][]string{
+			{"fd5c3a0b-4d2f-4c6c-8e7b-32b0f1d0b4a1", "node1", "ABC123", "da11"},
+			{"6a1c8a4c-8a5f-4e56-9e3b-5c2bb0b0d6e2", "node2", "DEF456", "da11"},
+		},
+	}
+
+	testcases := []struct {
+		name        string
+		input       string
+		interactive bool
+		threshold   int
+		params      ConfirmParams
+		wantErr     error
+	}{
+		{
+			name:        "confirmed",
+			input:       "yes\n",
+			interactive: true,
+			threshold:   5,
+		},
+		{
+			name:        "declined",
+			input:       "y\n",
+			interactive: true,
+			threshold:   5,
+			wantErr:     ErrNotConfirmed,
+		},
+		{
+			name:        "no input",
+			interactive: true,
+			threshold:   5,
+			wantErr:     ErrNotConfirmed,
+		},
+		{
+			name:      "non interactive without --yes",
+			input:     "yes\n",
+			threshold: 5,
+			wantErr:   ErrNonInteractive,
+		},
+		{
+			name:      "non interactive with --yes",
+			threshold: 5,
+			params:    ConfirmParams{Yes: true},
+		},
+		{
+			name:        "over threshold",
+			input:       "yes\n",
+			interactive: true,
+			threshold:   1,
+			params:      ConfirmParams{Yes: true},
+			wantErr:     ErrDestructiveTargets,
+		},
+		{
+			name:      "over threshold allowed",
+			threshold: 1,
+			params:    ConfirmParams{Yes: true, AllowBulk: true},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+
+			err := confirm(strings.NewReader(tc.input), out, tc.interactive, tc.threshold, op, &tc.params)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Contains(t, out.String(), "node2")
+		})
+	}
+}
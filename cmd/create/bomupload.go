@@ -8,12 +8,15 @@ import (
 
 	mctl "github.com/metal-toolbox/mctl/cmd"
 	"github.com/metal-toolbox/mctl/internal/app"
+	"github.com/metal-toolbox/mctl/internal/audit"
+	"github.com/metal-toolbox/mctl/pkg/model"
 )
 
 // Create Bom informations.
 type uploadBomFileFlags struct {
 	// Xlsx file containing one or multiple boms information.
 	bomXlsxFile string
+	dryRun      bool
 }
 
 var (
@@ -26,12 +29,26 @@ var uploadBomFile = &cobra.Command{
 	Run: func(cmd *cobra.Command, _ []string) {
 		theApp := mctl.MustCreateApp(cmd.Context())
 
-		client, err := app.NewBomServiceClient(cmd.Context(), theApp.Config.BomService, theApp.Reauth)
+		fBytes, err := os.ReadFile(flagsUploadBomFileFlags.bomXlsxFile)
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		fBytes, err := os.ReadFile(flagsUploadBomFileFlags.bomXlsxFile)
+		if flagsUploadBomFileFlags.dryRun {
+			// the xlsx contents are not printed, just the file that would be uploaded
+			mctl.PrintDryRun(&mctl.APIRequest{
+				API:    model.BomsServiceAPI,
+				Method: "XlsxFileUpload",
+				Payload: map[string]any{
+					"file":  flagsUploadBomFileFlags.bomXlsxFile,
+					"bytes": len(fBytes),
+				},
+			})
+
+			return
+		}
+
+		client, err := app.NewBomServiceClient(cmd.Context(), theApp.Config.BomService, theApp.Reauth)
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -42,6 +59,8 @@ var uploadBomFile = &cobra.Command{
 		}
 
 		log.Println(serverResp)
+
+		mctl.AuditLog(theApp, model.BomsServiceAPI, nil, audit.Result{Message: "bom file uploaded: " + flagsUploadBomFileFlags.bomXlsxFile})
 	},
 }
 
@@ -50,5 +69,6 @@ func init() {
 	usage := "xlsx file with BOM information"
 
 	mctl.AddFromFileFlag(uploadBomFile, &flagsUploadBomFileFlags.bomXlsxFile, usage)
+	mctl.AddClientDryRunFlag(uploadBomFile, &flagsUploadBomFileFlags.dryRun)
 	mctl.RequireFlag(uploadBomFile, mctl.FromFileFlag)
 }
@@ -11,12 +11,15 @@ import (
 
 	mctl "github.com/metal-toolbox/mctl/cmd"
 	"github.com/metal-toolbox/mctl/internal/app"
+	"github.com/metal-toolbox/mctl/internal/audit"
+	"github.com/metal-toolbox/mctl/pkg/model"
 )
 
 // Create
 type createFirmwareFlags struct {
 	// file containing firmware configuration
 	firmwareConfigFile string
+	dryRun             bool
 }
 
 var (
@@ -29,11 +32,6 @@ var createFirmware = &cobra.Command{
 	Run: func(cmd *cobra.Command, _ []string) {
 		theApp := mctl.MustCreateApp(cmd.Context())
 
-		client, err := app.NewFleetDBAPIClient(cmd.Context(), theApp.Config.FleetDBAPI, theApp.Reauth)
-		if err != nil {
-			log.Fatal(err)
-		}
-
 		var firmwares []*fleetdbapi.ComponentFirmwareVersion
 		fbytes, err := os.ReadFile(flagsDefinedCreateFirmware.firmwareConfigFile)
 		if err != nil {
@@ -44,6 +42,27 @@ var createFirmware = &cobra.Command{
 			log.Fatal(err)
 		}
 
+		if flagsDefinedCreateFirmware.dryRun {
+			requests := make([]*mctl.APIRequest, 0, len(firmwares))
+			for _, fw := range firmwares {
+				requests = append(requests, &mctl.APIRequest{
+					API:     model.FleetDBAPI,
+					Method:  "CreateServerComponentFirmware",
+					Payload: fw,
+				})
+			}
+
+			mctl.PrintDryRun(requests...)
+
+			return
+		}
+
+		client, err := app.NewFleetDBAPIClient(cmd.Context(), theApp.Config.FleetDBAPI, theApp.Reauth)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		results := make([]audit.Result, 0, len(firmwares))
 		for _, fw := range firmwares {
 			id, _, err := client.CreateServerComponentFirmware(cmd.Context(), *fw)
 			if err != nil {
@@ -51,7 +70,11 @@ var createFirmware = &cobra.Command{
 			}
 
 			log.Println(id)
+
+			results = append(results, audit.Result{ID: id.String(), Message: "firmware created"})
 		}
+
+		mctl.AuditLog(theApp, model.FleetDBAPI, nil, results...)
 	},
 }
 
@@ -60,5 +83,6 @@ func init() {
 	usage := "JSON file with firmware configuration data"
 
 	mctl.AddFromFileFlag(createFirmware, &flagsDefinedCreateFirmware.firmwareConfigFile, usage)
+	mctl.AddClientDryRunFlag(createFirmware, &flagsDefinedCreateFirmware.dryRun)
 	mctl.RequireFlag(createFirmware, mctl.FromFileFlag)
 }
@@ -14,6 +14,7 @@ import (
 	fleetdbapi "github.com/metal-toolbox/fleetdb/pkg/api/v1"
 	mctl "github.com/metal-toolbox/mctl/cmd"
 	"github.com/metal-toolbox/mctl/internal/app"
+	"github.com/metal-toolbox/mctl/internal/audit"
 	"github.com/metal-toolbox/mctl/pkg/model"
 )
 
@@ -40,44 +41,68 @@ var createFirmwareSet = &cobra.Command{
 	Run: func(cmd *cobra.Command, _ []string) {
 		theApp := mctl.MustCreateApp(cmd.Context())
 
-		client, err := app.NewFleetDBAPIClient(cmd.Context(), theApp.Config.FleetDBAPI, theApp.Reauth)
-		if err != nil {
-			log.Fatal(err)
-		}
+		var firmwares []fleetdbapi.ComponentFirmwareVersion
+		var sets []fleetdbapi.ComponentFirmwareSetRequest
+		var err error
 
 		if definedfirmwareSetFlags.CreateFromFile != "" {
-			err = createFWSetsFromFile(cmd.Context(), client, definedfirmwareSetFlags)
+			firmwares, sets, err = fwSetRequestsFromFile(definedfirmwareSetFlags.CreateFromFile)
 			if err != nil {
 				log.Fatal(err)
 			}
 		} else {
-			err = createFWSetFromCLI(cmd.Context(), client, definedfirmwareSetFlags)
+			set, err := fwSetRequestFromCLI(definedfirmwareSetFlags)
 			if err != nil {
 				log.Fatal(err)
 			}
+
+			sets = append(sets, *set)
+		}
+
+		if definedfirmwareSetFlags.DryRun {
+			printFWSetRequests(firmwares, sets)
+			return
+		}
+
+		client, err := app.NewFleetDBAPIClient(cmd.Context(), theApp.Config.FleetDBAPI, theApp.Reauth)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		results, err := createFWSets(cmd.Context(), client, firmwares, sets)
+
+		// objects created before an error are recorded too
+		mctl.AuditLog(theApp, model.FleetDBAPI, nil, results...)
+
+		if err != nil {
+			log.Fatal(err)
 		}
 	},
 }
 
-func createFWSetsFromFile(ctx context.Context, client *fleetdbapi.Client, flgs *mctl.FirmwareSetFlags) (err error) {
+// fwSetRequestsFromFile returns the firmware objects and the firmware set requests
+// to create the firmware sets defined in the given file.
+func fwSetRequestsFromFile(path string) ([]fleetdbapi.ComponentFirmwareVersion, []fleetdbapi.ComponentFirmwareSetRequest, error) {
 	var fwsets []*fleetdbapi.ComponentFirmwareSet
 
-	fbytes, err := os.ReadFile(flgs.CreateFromFile)
+	fbytes, err := os.ReadFile(path)
 	if err != nil {
-		log.Fatal(err)
+		return nil, nil, err
 	}
 
 	if err = json.Unmarshal(fbytes, &fwsets); err != nil {
-		log.Fatal(err)
+		return nil, nil, err
 	}
 
+	firmwares := []fleetdbapi.ComponentFirmwareVersion{}
+	sets := []fleetdbapi.ComponentFirmwareSetRequest{}
+
 	firmwareAdded := map[string]bool{}
 	for _, set := range fwsets {
 		if len(set.ComponentFirmware) == 0 {
 			continue
 		}
 
-		// create firmware
 		setFwUUIDs := []string{}
 
 		// nolint:gocritic // this is fine
@@ -89,69 +114,105 @@ func createFWSetsFromFile(ctx context.Context, client *fleetdbapi.Client, flgs *
 				continue
 			}
 
-			log.Printf("Adding firmware object: %s", fw.UUID.String())
-			_, _, err = client.CreateServerComponentFirmware(ctx, fw)
-			if err != nil {
-				log.Fatal("error adding firmware object: ", err)
-			}
-
+			firmwares = append(firmwares, fw)
 			firmwareAdded[fw.UUID.String()] = true
 		}
 
-		log.Printf("Adding firmware-set object: %s", set.UUID.String())
-		req := fleetdbapi.ComponentFirmwareSetRequest{
+		sets = append(sets, fleetdbapi.ComponentFirmwareSetRequest{
 			ID:                     set.UUID,
 			Attributes:             set.Attributes,
 			Name:                   set.Name,
 			ComponentFirmwareUUIDs: setFwUUIDs,
-		}
-
-		_, _, err = client.CreateServerComponentFirmwareSet(ctx, req)
-		if err != nil {
-			log.Fatal("error adding firmware-set object: ", err)
-		}
+		})
 	}
 
-	return nil
+	return firmwares, sets, nil
 }
 
-func createFWSetFromCLI(ctx context.Context, client *fleetdbapi.Client, flgs *mctl.FirmwareSetFlags) (err error) {
-	payload := fleetdbapi.ComponentFirmwareSetRequest{
+func fwSetRequestFromCLI(flgs *mctl.FirmwareSetFlags) (*fleetdbapi.ComponentFirmwareSetRequest, error) {
+	payload := &fleetdbapi.ComponentFirmwareSetRequest{
 		Name:                   flgs.FirmwareSetName,
 		ComponentFirmwareUUIDs: []string{},
 	}
 
-	if len(definedfirmwareSetFlags.Labels) > 0 {
-		var attrs *fleetdbapi.Attributes
-		attrs, err = mctl.AttributeFromLabels(model.AttributeNSFirmwareSetLabels, flgs.Labels)
+	if len(flgs.Labels) > 0 {
+		attrs, err := mctl.AttributeFromLabels(model.AttributeNSFirmwareSetLabels, flgs.Labels)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		payload.Attributes = []fleetdbapi.Attributes{*attrs}
 	}
 
 	for _, id := range flgs.AddFirmwareUUIDs {
-		_, err = uuid.Parse(id)
-		if err != nil {
-			return err
+		if _, err := uuid.Parse(id); err != nil {
+			return nil, err
 		}
 
 		payload.ComponentFirmwareUUIDs = append(payload.ComponentFirmwareUUIDs, id)
 	}
 
 	if len(payload.ComponentFirmwareUUIDs) == 0 {
-		return errFwSetUUIDs
+		return nil, errFwSetUUIDs
 	}
 
-	id, _, err := client.CreateServerComponentFirmwareSet(ctx, payload)
-	if err != nil {
-		log.Fatal(err)
+	return payload, nil
+}
+
+func printFWSetRequests(firmwares []fleetdbapi.ComponentFirmwareVersion, sets []fleetdbapi.ComponentFirmwareSetRequest) {
+	requests := make([]*mctl.APIRequest, 0, len(firmwares)+len(sets))
+
+	for idx := range firmwares {
+		requests = append(requests, &mctl.APIRequest{
+			API:     model.FleetDBAPI,
+			Method:  "CreateServerComponentFirmware",
+			Payload: firmwares[idx],
+		})
+	}
+
+	for idx := range sets {
+		requests = append(requests, &mctl.APIRequest{
+			API:     model.FleetDBAPI,
+			Method:  "CreateServerComponentFirmwareSet",
+			Payload: sets[idx],
+		})
+	}
+
+	mctl.PrintDryRun(requests...)
+}
+
+func createFWSets(
+	ctx context.Context,
+	client *fleetdbapi.Client,
+	firmwares []fleetdbapi.ComponentFirmwareVersion,
+	sets []fleetdbapi.ComponentFirmwareSetRequest,
+) ([]audit.Result, error) {
+	results := make([]audit.Result, 0, len(firmwares)+len(sets))
+
+	// nolint:gocritic // this is fine
+	for _, fw := range firmwares {
+		log.Printf("Adding firmware object: %s", fw.UUID.String())
+		id, _, err := client.CreateServerComponentFirmware(ctx, fw)
+		if err != nil {
+			return results, fmt.Errorf("error adding firmware object: %w", err)
+		}
+
+		results = append(results, audit.Result{ID: id.String(), Message: "firmware created"})
 	}
 
-	fmt.Println(id)
+	// nolint:gocritic // this is fine
+	for _, set := range sets {
+		id, _, err := client.CreateServerComponentFirmwareSet(ctx, set)
+		if err != nil {
+			return results, fmt.Errorf("error adding firmware-set object: %w", err)
+		}
+
+		fmt.Println(id)
+
+		results = append(results, audit.Result{ID: id.String(), Message: "firmware set created"})
+	}
 
-	return nil
+	return results, nil
 }
 
 func init() {
@@ -163,4 +224,5 @@ func init() {
 		"Labels to assign to the firmware set - 'vendor=foo,model=bar'")
 
 	mctl.AddFromFileFlag(createFirmwareSet, &definedfirmwareSetFlags.CreateFromFile, "JSON file with firmware configuration data")
+	mctl.AddClientDryRunFlag(createFirmwareSet, &definedfirmwareSetFlags.DryRun)
 }
@@ -3,30 +3,65 @@ package create
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"log"
+	"os"
+	"strings"
+	"sync"
 
-	coapiv1 "github.com/metal-toolbox/conditionorc/pkg/api/v1/conditions/types"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	coclient "github.com/metal-toolbox/conditionorc/pkg/api/v1/conditions/client"
+	coapiv1 "github.com/metal-toolbox/conditionorc/pkg/api/v1/conditions/types"
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+
 	mctl "github.com/metal-toolbox/mctl/cmd"
 	"github.com/metal-toolbox/mctl/internal/app"
+	"github.com/metal-toolbox/mctl/internal/audit"
+	"github.com/metal-toolbox/mctl/internal/enroll"
+	"github.com/metal-toolbox/mctl/pkg/model"
 )
 
+const redactedValue = "REDACTED"
+
 type serverEnrollParams struct {
-	serverID string
-	facility string
-	ip       string
-	username string
-	password string
+	serverID     string
+	facility     string
+	ip           string
+	username     string
+	password     string
+	passwordEnv  string
+	passwordFile string
+	fromFile     string
+	resultsFile  string
+	concurrency  int
+	dryRun       bool
 }
 
 var (
 	serverEnrollFlags *serverEnrollParams
+
+	errEnrollParams = errors.New("invalid server enroll parameters")
 )
 
 var serverEnroll = &cobra.Command{
 	Use:   "server",
 	Short: "Enroll server and publish conditions",
+	Long: `Enroll a server, or the servers of a CSV or JSON manifest with --from-file.
+
+The manifest rows have the facility, bmc_address, bmc_username, bmc_password and server_id fields,
+CSV manifests name the columns in a header row. Rows without a facility or BMC credentials use
+the flag values, only bmc_address is required in each row. The server_id is optional.
+
+The BMC password is read from the environment variable named by --bmc-pass-env, or from the
+--bmc-pass-file file, '-' reads it from stdin, which keeps it out of the shell history.`,
+	Example: `  # enroll a server
+  BMC_PASS=<> mctl create server --facility sandbox --bmc-addr 10.0.0.1 --bmc-user root --bmc-pass-env BMC_PASS
+
+  # enroll the servers of a manifest, 8 at a time, the results are written to servers-results.csv
+  mctl create server --from-file servers.csv --bmc-user root --bmc-pass-file - --concurrency 8 < password.txt`,
 	Run: func(cmd *cobra.Command, _ []string) {
 		enrollServer(cmd.Context())
 	},
@@ -35,18 +70,32 @@ var serverEnroll = &cobra.Command{
 func enrollServer(ctx context.Context) {
 	theApp := mctl.MustCreateApp(ctx)
 
-	params, err := json.Marshal(coapiv1.AddServerParams{
-		Facility: serverEnrollFlags.facility,
-		IP:       serverEnrollFlags.ip,
-		Username: serverEnrollFlags.username,
-		Password: serverEnrollFlags.password,
-	})
+	password, err := bmcPassword(serverEnrollFlags, os.Stdin)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	conditionCreate := coapiv1.ConditionCreate{
-		Parameters: params,
+	if serverEnrollFlags.fromFile != "" {
+		enrollServers(ctx, theApp, password)
+		return
+	}
+
+	row := &enroll.Row{
+		Index:       1,
+		Facility:    serverEnrollFlags.facility,
+		BMCAddress:  serverEnrollFlags.ip,
+		BMCUsername: serverEnrollFlags.username,
+		BMCPassword: password,
+		ServerID:    serverEnrollFlags.serverID,
+	}
+
+	if err := validateEnrollFlags(row); err != nil {
+		log.Fatal(err)
+	}
+
+	if serverEnrollFlags.dryRun {
+		mctl.PrintDryRun(enrollDryRun(row))
+		return
 	}
 
 	client, err := app.NewConditionsClient(ctx, theApp.Config.Conditions, theApp.Reauth)
@@ -54,17 +103,237 @@ func enrollServer(ctx context.Context) {
 		log.Fatal(err)
 	}
 
-	response, err := client.ServerEnroll(ctx, serverEnrollFlags.serverID, conditionCreate)
+	response, condition, err := enrollRow(ctx, client, row)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("status=%d\nmsg=%s\nconditionID=%s\nserverID=%v", response.StatusCode, response.Message, condition.ID, response.Records.ServerID)
+
+	mctl.AuditCondition(theApp, response.Records.ServerID, response, condition.ID)
+}
+
+// enrollServers enrolls the servers of the manifest, the results are written to the results file.
+func enrollServers(ctx context.Context, theApp *app.App, password string) {
+	rows, err := enroll.ReadManifest(serverEnrollFlags.fromFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	enroll.ApplyDefaults(rows, &enroll.Row{
+		Facility:    serverEnrollFlags.facility,
+		BMCUsername: serverEnrollFlags.username,
+		BMCPassword: password,
+	})
+
+	if err := enroll.Validate(rows); err != nil {
+		log.Fatal(err)
+	}
+
+	if serverEnrollFlags.dryRun {
+		requests := make([]*mctl.APIRequest, 0, len(rows))
+		for idx := range rows {
+			requests = append(requests, enrollDryRun(&rows[idx]))
+		}
+
+		mctl.PrintDryRun(requests...)
+
+		return
+	}
+
+	client, err := app.NewConditionsClient(ctx, theApp.Config.Conditions, theApp.Reauth)
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	results := enrollRows(ctx, client, rows, serverEnrollFlags.concurrency)
+
+	resultsFile := serverEnrollFlags.resultsFile
+	if resultsFile == "" {
+		resultsFile = enroll.ResultsPath(serverEnrollFlags.fromFile)
+	}
+
+	if err := enroll.WriteResults(resultsFile, results); err != nil {
+		log.Fatal(err)
+	}
+
+	targets := []string{}
+	auditResults := []audit.Result{}
+	failed := 0
+
+	for _, result := range results {
+		if result.Error != "" {
+			failed++
+			continue
+		}
+
+		targets = append(targets, result.ServerID)
+		auditResults = append(auditResults, audit.Result{Target: result.ServerID, ID: result.ConditionID})
+	}
+
+	if len(targets) > 0 {
+		mctl.AuditLog(theApp, model.ConditionsAPI, targets, auditResults...)
+	}
+
+	if failed > 0 {
+		log.Fatalf("%d of %d servers not enrolled, results written to %s", failed, len(rows), resultsFile)
+	}
+
+	log.Printf("%d servers enrolled, results written to %s", len(rows), resultsFile)
+}
+
+// enrollRows enrolls the rows with up to concurrency enrollments in flight, the results are in the row order.
+func enrollRows(ctx context.Context, client *coclient.Client, rows []enroll.Row, concurrency int) []enroll.Result {
+	results := make([]enroll.Result, len(rows))
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+
+	for idx := range rows {
+		wg.Add(1)
+
+		sem <- struct{}{}
+
+		go func(idx int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			row := &rows[idx]
+			result := enroll.Result{Row: row.Index, Facility: row.Facility, BMCAddress: row.BMCAddress}
+
+			response, condition, err := enrollRow(ctx, client, row)
+			if err != nil {
+				result.Error = err.Error()
+				log.Printf("row=%d bmc=%s error=%s", row.Index, row.BMCAddress, err)
+			} else {
+				result.ServerID = response.Records.ServerID.String()
+				result.ConditionID = condition.ID.String()
+				log.Printf("row=%d bmc=%s serverID=%s conditionID=%s", row.Index, row.BMCAddress, result.ServerID, result.ConditionID)
+			}
+
+			results[idx] = result
+		}(idx)
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+func enrollRow(ctx context.Context, client *coclient.Client, row *enroll.Row) (*coapiv1.ServerResponse, rctypes.Condition, error) {
+	conditionCreate, err := enrollCondition(row, row.BMCPassword)
+	if err != nil {
+		return nil, rctypes.Condition{}, err
+	}
+
+	response, err := client.ServerEnroll(ctx, row.ServerID, conditionCreate)
+	if err != nil {
+		return nil, rctypes.Condition{}, err
+	}
+
 	condition, err := mctl.ConditionFromResponse(response)
+	if err != nil {
+		return nil, rctypes.Condition{}, err
+	}
+
+	return response, condition, nil
+}
+
+func enrollCondition(row *enroll.Row, password string) (coapiv1.ConditionCreate, error) {
+	params, err := json.Marshal(coapiv1.AddServerParams{
+		Facility: row.Facility,
+		IP:       row.BMCAddress,
+		Username: row.BMCUsername,
+		Password: password,
+	})
+	if err != nil {
+		return coapiv1.ConditionCreate{}, err
+	}
+
+	return coapiv1.ConditionCreate{Parameters: params}, nil
+}
+
+// enrollDryRun returns the enroll request for the row, the BMC password is not printed.
+func enrollDryRun(row *enroll.Row) *mctl.APIRequest {
+	conditionCreate, err := enrollCondition(row, redactedValue)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	log.Printf("status=%d\nmsg=%s\nconditionID=%s\nserverID=%v", response.StatusCode, response.Message, condition.ID, response.Records.ServerID)
+	return &mctl.APIRequest{
+		API:      model.ConditionsAPI,
+		Method:   "ServerEnroll",
+		ServerID: row.ServerID,
+		Payload:  conditionCreate,
+	}
+}
+
+func validateEnrollFlags(row *enroll.Row) error {
+	missing := []string{}
+
+	for _, flag := range []struct{ name, value string }{
+		{mctl.FacilityFlag.Name(), row.Facility},
+		{mctl.BMCAddressFlag.Name(), row.BMCAddress},
+		{mctl.BMCUsernameFlag.Name(), row.BMCUsername},
+	} {
+		if flag.value == "" {
+			missing = append(missing, "--"+flag.name)
+		}
+	}
+
+	if row.BMCPassword == "" {
+		missing = append(missing, fmt.Sprintf("--%s, --%s or --%s",
+			mctl.BMCPasswordEnvFlag.Name(), mctl.BMCPasswordFileFlag.Name(), mctl.BMCPasswordFlag.Name()))
+	}
+
+	if len(missing) > 0 {
+		return errors.Wrap(errEnrollParams, "required without --"+mctl.FromFileFlag.Name()+": "+strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// bmcPassword returns the BMC password from the password flags, the password file '-' is read from stdin.
+func bmcPassword(flags *serverEnrollParams, stdin io.Reader) (string, error) {
+	switch {
+	case flags.passwordEnv != "":
+		password := os.Getenv(flags.passwordEnv)
+		if password == "" {
+			return "", errors.Wrap(errEnrollParams, "the environment variable "+flags.passwordEnv+" is not set")
+		}
+
+		return password, nil
+	case flags.passwordFile != "":
+		var b []byte
+
+		var err error
+
+		if flags.passwordFile == "-" {
+			b, err = io.ReadAll(stdin)
+		} else {
+			b, err = os.ReadFile(flags.passwordFile)
+		}
+
+		if err != nil {
+			return "", errors.Wrap(errEnrollParams, "BMC password file: "+err.Error())
+		}
+
+		password := strings.TrimRight(string(b), "\r\n")
+		if password == "" {
+			return "", errors.Wrap(errEnrollParams, "the BMC password file is empty")
+		}
+
+		return password, nil
+	default:
+		return flags.password, nil
+	}
 }
 
 func init() {
@@ -73,11 +342,18 @@ func init() {
 	mctl.AddBMCAddressFlag(serverEnroll, &serverEnrollFlags.ip)
 	mctl.AddBMCUsernameFlag(serverEnroll, &serverEnrollFlags.username)
 	mctl.AddBMCPasswordFlag(serverEnroll, &serverEnrollFlags.password)
+	mctl.AddBMCPasswordEnvFlag(serverEnroll, &serverEnrollFlags.passwordEnv)
+	mctl.AddBMCPasswordFileFlag(serverEnroll, &serverEnrollFlags.passwordFile)
 	mctl.AddFacilityFlag(serverEnroll, &serverEnrollFlags.facility)
-	mctl.AddServerFlag(serverEnroll, &serverEnrollFlags.serverID)
+	mctl.AddServerIDFlag(serverEnroll, &serverEnrollFlags.serverID, "ID to assign to the enrolled server")
+	mctl.AddFromFileFlag(serverEnroll, &serverEnrollFlags.fromFile,
+		"CSV or JSON manifest of the servers to enroll, files with the .csv extension are read as CSV")
+	mctl.AddResultsFileFlag(serverEnroll, &serverEnrollFlags.resultsFile,
+		"file the enrollment results are written to, CSV when it has the .csv extension - defaults to <manifest>-results.<ext>")
+	mctl.AddConcurrencyFlag(serverEnroll, &serverEnrollFlags.concurrency, 4, "number of servers enrolled in parallel")
+	mctl.AddClientDryRunFlag(serverEnroll, &serverEnrollFlags.dryRun)
 
-	mctl.RequireFlag(serverEnroll, mctl.BMCAddressFlag)
-	mctl.RequireFlag(serverEnroll, mctl.BMCUsernameFlag)
-	mctl.RequireFlag(serverEnroll, mctl.BMCPasswordFlag)
-	mctl.RequireFlag(serverEnroll, mctl.FacilityFlag)
+	mctl.MutuallyExclusiveFlags(serverEnroll, mctl.BMCPasswordFlag, mctl.BMCPasswordEnvFlag, mctl.BMCPasswordFileFlag)
+	mctl.MutuallyExclusiveFlags(serverEnroll, mctl.FromFileFlag, mctl.BMCAddressFlag)
+	mctl.MutuallyExclusiveFlags(serverEnroll, mctl.FromFileFlag, mctl.ServerFlag)
 }
@@ -1,18 +1,20 @@
 package create
 
 import (
-	"context"
-	"encoding/json"
 	"log"
-	"os"
 
-	fleetdbapi "github.com/metal-toolbox/fleetdb/pkg/api/v1"
 	mctl "github.com/metal-toolbox/mctl/cmd"
 	"github.com/metal-toolbox/mctl/internal/app"
+	"github.com/metal-toolbox/mctl/internal/audit"
+	"github.com/metal-toolbox/mctl/internal/fleetdb"
+	"github.com/metal-toolbox/mctl/pkg/model"
 	"github.com/spf13/cobra"
 )
 
-var fromFile string
+var (
+	fromFile                        string
+	createServerBiosConfigSetDryRun bool
+)
 
 var createServerBiosConfigSet = &cobra.Command{
 	Use:   "bios-config-set",
@@ -30,38 +32,40 @@ var createServerBiosConfigSet = &cobra.Command{
 	Run: func(cmd *cobra.Command, _ []string) {
 		theApp := mctl.MustCreateApp(cmd.Context())
 
-		client, err := app.NewFleetDBAPIClient(cmd.Context(), theApp.Config.FleetDBAPI, theApp.Reauth)
+		biosconfigset, err := mctl.BIOSConfigSetFromFile(fromFile)
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		err = createServerBiosConfigSetFromFile(cmd.Context(), client)
-		if err != nil {
+		if err := fleetdb.ValidateBiosConfigSet(biosconfigset); err != nil {
 			log.Fatal(err)
 		}
-	},
-}
 
-func createServerBiosConfigSetFromFile(ctx context.Context, client *fleetdbapi.Client) (err error) {
-	biosconfigset := &fleetdbapi.BiosConfigSet{}
+		if createServerBiosConfigSetDryRun {
+			mctl.PrintDryRun(&mctl.APIRequest{
+				API:     model.FleetDBAPI,
+				Method:  "CreateServerBiosConfigSet",
+				Payload: biosconfigset,
+			})
 
-	fbytes, err := os.ReadFile(fromFile)
-	if err != nil {
-		log.Fatal(err)
-	}
+			return
+		}
 
-	if err = json.Unmarshal(fbytes, &biosconfigset); err != nil {
-		log.Fatal(err)
-	}
+		client, err := app.NewFleetDBAPIClient(cmd.Context(), theApp.Config.FleetDBAPI, theApp.Reauth)
+		if err != nil {
+			log.Fatal(err)
+		}
 
-	_, err = client.CreateServerBiosConfigSet(ctx, *biosconfigset)
-	if err != nil {
-		log.Fatal(err)
-	}
+		resp, err := client.CreateServerBiosConfigSet(cmd.Context(), *biosconfigset)
+		if err != nil {
+			log.Fatal(err)
+		}
 
-	return nil
+		mctl.AuditLog(theApp, model.FleetDBAPI, nil, audit.Result{ID: resp.Slug, Message: "bios config set created"})
+	},
 }
 
 func init() {
 	mctl.AddFromFileFlag(createServerBiosConfigSet, &fromFile, "path to JSON file containing bios config set")
+	mctl.AddClientDryRunFlag(createServerBiosConfigSet, &createServerBiosConfigSetDryRun)
 }
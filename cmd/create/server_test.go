@@ -0,0 +1,45 @@
+package create
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBMCPassword(t *testing.T) {
+	t.Setenv("MCTL_TEST_BMC_PASS", "from-env")
+
+	file := filepath.Join(t.TempDir(), "password")
+	require.NoError(t, os.WriteFile(file, []byte("from-file\n"), 0o600))
+
+	testcases := []struct {
+		name    string
+		flags   *serverEnrollParams
+		want    string
+		wantErr bool
+	}{
+		{name: "flag", flags: &serverEnrollParams{password: "from-flag"}, want: "from-flag"},
+		{name: "env", flags: &serverEnrollParams{passwordEnv: "MCTL_TEST_BMC_PASS"}, want: "from-env"},
+		{name: "env not set", flags: &serverEnrollParams{passwordEnv: "MCTL_TEST_BMC_PASS_UNSET"}, wantErr: true},
+		{name: "file", flags: &serverEnrollParams{passwordFile: file}, want: "from-file"},
+		{name: "stdin", flags: &serverEnrollParams{passwordFile: "-"}, want: "from-stdin"},
+		{name: "none", flags: &serverEnrollParams{}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := bmcPassword(tc.flags, strings.NewReader("from-stdin\r\n"))
+			if tc.wantErr {
+				assert.ErrorIs(t, err, errEnrollParams)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -1,24 +1,36 @@
 package deleteresource
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/google/uuid"
 	fleetdbapi "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	mctl "github.com/metal-toolbox/mctl/cmd"
 	"github.com/metal-toolbox/mctl/internal/app"
+	"github.com/metal-toolbox/mctl/internal/audit"
+	"github.com/metal-toolbox/mctl/internal/fleetdb"
+	"github.com/metal-toolbox/mctl/pkg/model"
 )
 
 type deleteFirmwareFlags struct {
 	// firmware UUID
 	id string
+	// firmware UUIDs for a bulk delete
+	ids     []string
+	confirm mctl.ConfirmParams
+	dryRun  bool
 }
 
 var (
 	flagsDefinedDeleteFirmware *deleteFirmwareFlags
+
+	errFirmwareInSet = errors.New("firmware is a member of a firmware set")
 )
 var deleteFirmware = &cobra.Command{
 	Use:   "firmware",
@@ -26,28 +38,127 @@ var deleteFirmware = &cobra.Command{
 	Run: func(cmd *cobra.Command, _ []string) {
 		theApp := mctl.MustCreateApp(cmd.Context())
 
+		ids := flagsDefinedDeleteFirmware.ids
+		if flagsDefinedDeleteFirmware.id != "" {
+			ids = []string{flagsDefinedDeleteFirmware.id}
+		}
+
+		fwIDs := make([]uuid.UUID, 0, len(ids))
+		for _, s := range ids {
+			id, err := uuid.Parse(s)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			fwIDs = append(fwIDs, id)
+		}
+
+		if flagsDefinedDeleteFirmware.dryRun {
+			requests := make([]*mctl.APIRequest, 0, len(fwIDs))
+			for _, id := range fwIDs {
+				requests = append(requests, &mctl.APIRequest{
+					API:    model.FleetDBAPI,
+					Method: "DeleteServerComponentFirmware",
+					ID:     id.String(),
+				})
+			}
+
+			mctl.PrintDryRun(requests...)
+
+			return
+		}
+
 		client, err := app.NewFleetDBAPIClient(cmd.Context(), theApp.Config.FleetDBAPI, theApp.Reauth)
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		id, err := uuid.Parse(flagsDefinedDeleteFirmware.id)
-		if err != nil {
+		if err := firmwareNotInSets(cmd.Context(), client, fwIDs); err != nil {
 			log.Fatal(err)
 		}
 
-		_, err = client.DeleteServerComponentFirmware(cmd.Context(), fleetdbapi.ComponentFirmwareVersion{UUID: id})
-		if err != nil {
+		op := &mctl.Destructive{
+			Action: "delete firmware",
+			Header: []string{"ID", "Vendor", "Model", "Component", "Version"},
+			Rows:   firmwareSummaryRows(cmd.Context(), client, fwIDs),
+		}
+
+		if err := mctl.ConfirmDestructive(theApp, op, &flagsDefinedDeleteFirmware.confirm); err != nil {
 			log.Fatal(err)
 		}
 
-		fmt.Println("firmware deleted: " + id.String())
+		targets := make([]string, 0, len(fwIDs))
+		for _, id := range fwIDs {
+			_, err = client.DeleteServerComponentFirmware(cmd.Context(), fleetdbapi.ComponentFirmwareVersion{UUID: id})
+			if err != nil {
+				break
+			}
+
+			fmt.Println("firmware deleted: " + id.String())
+
+			targets = append(targets, id.String())
+		}
+
+		// firmware deleted before an error are recorded too
+		if len(targets) > 0 {
+			mctl.AuditLog(theApp, model.FleetDBAPI, targets, audit.Result{Message: "firmware deleted"})
+		}
+
+		if err != nil {
+			log.Fatal(err)
+		}
 	},
 }
 
+// firmwareNotInSets returns an error listing the firmware sets still referencing any of the firmware.
+func firmwareNotInSets(ctx context.Context, client *fleetdbapi.Client, ids []uuid.UUID) error {
+	sets, err := mctl.ListFirmwareSets(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	inUse := []string{}
+
+	for _, id := range ids {
+		for _, set := range fleetdb.FirmwareSetsWithFirmware(sets, id) {
+			inUse = append(inUse, fmt.Sprintf("%s in set %s (%s)", id, set.UUID, set.Name))
+		}
+	}
+
+	if len(inUse) > 0 {
+		return errors.Wrap(
+			errFirmwareInSet,
+			strings.Join(inUse, ", ")+", remove the firmware from the sets with 'mctl edit firmware-set --remove-firmware-ids'",
+		)
+	}
+
+	return nil
+}
+
+func firmwareSummaryRows(ctx context.Context, client *fleetdbapi.Client, ids []uuid.UUID) [][]string {
+	rows := make([][]string, 0, len(ids))
+
+	for _, id := range ids {
+		fw, _, err := client.GetServerComponentFirmware(ctx, id)
+		if err != nil {
+			rows = append(rows, []string{id.String(), "lookup error: " + err.Error(), "-", "-", "-"})
+			continue
+		}
+
+		rows = append(rows, []string{id.String(), fw.Vendor, strings.Join(fw.Model, ","), fw.Component, fw.Version})
+	}
+
+	return rows
+}
+
 func init() {
 	flagsDefinedDeleteFirmware = &deleteFirmwareFlags{}
 
 	mctl.AddFirmwareIDFlag(deleteFirmware, &flagsDefinedDeleteFirmware.id)
-	mctl.RequireFlag(deleteFirmware, mctl.FirmwareIDFlag)
+	mctl.AddFirmwareIDsFlag(deleteFirmware, &flagsDefinedDeleteFirmware.ids)
+	mctl.AddConfirmFlags(deleteFirmware, &flagsDefinedDeleteFirmware.confirm)
+	mctl.AddClientDryRunFlag(deleteFirmware, &flagsDefinedDeleteFirmware.dryRun)
+
+	mctl.MutuallyExclusiveFlags(deleteFirmware, mctl.FirmwareIDFlag, mctl.FirmwareIDsFlag)
+	mctl.RequireOneFlag(deleteFirmware, mctl.FirmwareIDFlag, mctl.FirmwareIDsFlag)
 }
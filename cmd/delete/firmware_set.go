@@ -3,16 +3,21 @@ package deleteresource
 import (
 	"fmt"
 	"log"
+	"strconv"
 
 	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 
 	mctl "github.com/metal-toolbox/mctl/cmd"
 	"github.com/metal-toolbox/mctl/internal/app"
+	"github.com/metal-toolbox/mctl/internal/audit"
+	"github.com/metal-toolbox/mctl/pkg/model"
 )
 
 var (
-	deleteFWSetFlags mctl.FirmwareSetFlags
+	deleteFWSetFlags   mctl.FirmwareSetFlags
+	deleteFWSetConfirm mctl.ConfirmParams
+	deleteFWSetDryRun  bool
 )
 
 var deleteFirmwareSet = &cobra.Command{
@@ -21,13 +26,46 @@ var deleteFirmwareSet = &cobra.Command{
 	Run: func(cmd *cobra.Command, _ []string) {
 		theApp := mctl.MustCreateApp(cmd.Context())
 
+		id, err := uuid.Parse(deleteFWSetFlags.ID)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if deleteFWSetDryRun {
+			mctl.PrintDryRun(&mctl.APIRequest{
+				API:    model.FleetDBAPI,
+				Method: "DeleteServerComponentFirmwareSet",
+				ID:     id.String(),
+			})
+
+			return
+		}
+
 		client, err := app.NewFleetDBAPIClient(cmd.Context(), theApp.Config.FleetDBAPI, theApp.Reauth)
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		id, err := uuid.Parse(deleteFWSetFlags.ID)
+		row := []string{id.String(), "-", "-", "-"}
+
+		fwSet, _, err := client.GetServerComponentFirmwareSet(cmd.Context(), id)
 		if err != nil {
+			row[1] = "lookup error: " + err.Error()
+		} else {
+			row[1] = fwSet.Name
+			if attr := mctl.AttributeByNamespace(model.AttributeNSFirmwareSetLabels, fwSet.Attributes); attr != nil {
+				row[2] = string(attr.Data)
+			}
+			row[3] = strconv.Itoa(len(fwSet.ComponentFirmware))
+		}
+
+		op := &mctl.Destructive{
+			Action: "delete firmware-set",
+			Header: []string{"ID", "Name", "Labels", "Firmware count"},
+			Rows:   [][]string{row},
+		}
+
+		if err = mctl.ConfirmDestructive(theApp, op, &deleteFWSetConfirm); err != nil {
 			log.Fatal(err)
 		}
 
@@ -37,10 +75,14 @@ var deleteFirmwareSet = &cobra.Command{
 		}
 
 		fmt.Println("firmware set deleted: " + id.String())
+
+		mctl.AuditLog(theApp, model.FleetDBAPI, []string{id.String()}, audit.Result{Target: id.String(), Message: "firmware set deleted"})
 	},
 }
 
 func init() {
 	mctl.AddFirmwareSetFlag(deleteFirmwareSet, &deleteFWSetFlags.ID)
+	mctl.AddConfirmFlags(deleteFirmwareSet, &deleteFWSetConfirm)
+	mctl.AddClientDryRunFlag(deleteFirmwareSet, &deleteFWSetDryRun)
 	mctl.RequireFlag(deleteFirmwareSet, mctl.FirmwareSetFlag)
 }
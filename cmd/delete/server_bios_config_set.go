@@ -6,12 +6,17 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/metal-toolbox/mctl/internal/app"
+	"github.com/metal-toolbox/mctl/internal/audit"
+	"github.com/metal-toolbox/mctl/pkg/model"
 	"github.com/spf13/cobra"
 
 	mctl "github.com/metal-toolbox/mctl/cmd"
 )
 
-var deleteServerBiosConfigSetID string
+var (
+	deleteServerBiosConfigSetID     string
+	deleteServerBiosConfigSetDryRun bool
+)
 
 var deleteServerBiosConfigSet = &cobra.Command{
 	Use:   "bios-config-set",
@@ -19,12 +24,22 @@ var deleteServerBiosConfigSet = &cobra.Command{
 	Run: func(cmd *cobra.Command, _ []string) {
 		theApp := mctl.MustCreateApp(cmd.Context())
 
-		client, err := app.NewFleetDBAPIClient(cmd.Context(), theApp.Config.FleetDBAPI, theApp.Reauth)
+		id, err := uuid.Parse(deleteServerBiosConfigSetID)
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		id, err := uuid.Parse(deleteServerBiosConfigSetID)
+		if deleteServerBiosConfigSetDryRun {
+			mctl.PrintDryRun(&mctl.APIRequest{
+				API:    model.FleetDBAPI,
+				Method: "DeleteServerBiosConfigSet",
+				ID:     id.String(),
+			})
+
+			return
+		}
+
+		client, err := app.NewFleetDBAPIClient(cmd.Context(), theApp.Config.FleetDBAPI, theApp.Reauth)
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -35,9 +50,12 @@ var deleteServerBiosConfigSet = &cobra.Command{
 		}
 
 		fmt.Println("bios config set deleted: " + id.String())
+
+		mctl.AuditLog(theApp, model.FleetDBAPI, []string{id.String()}, audit.Result{Target: id.String(), Message: "bios config set deleted"})
 	},
 }
 
 func init() {
 	mctl.AddBIOSConfigSetIDFlag(deleteServerBiosConfigSet, &deleteServerBiosConfigSetID)
+	mctl.AddClientDryRunFlag(deleteServerBiosConfigSet, &deleteServerBiosConfigSetDryRun)
 }
@@ -7,12 +7,16 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/metal-toolbox/mctl/internal/app"
+	"github.com/metal-toolbox/mctl/internal/audit"
+	"github.com/metal-toolbox/mctl/pkg/model"
 
 	mctl "github.com/metal-toolbox/mctl/cmd"
 )
 
 type serverDeleteParams struct {
 	serverID string
+	confirm  mctl.ConfirmParams
+	dryRun   bool
 }
 
 var (
@@ -30,21 +34,56 @@ var serverDelete = &cobra.Command{
 func deleteServer(ctx context.Context) {
 	theApp := mctl.MustCreateApp(ctx)
 
+	serverID, err := mctl.ResolveServerID(ctx, theApp, serverDeleteFlags.serverID)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if serverDeleteFlags.dryRun {
+		mctl.PrintDryRun(&mctl.APIRequest{
+			API:      model.ConditionsAPI,
+			Method:   "ServerDelete",
+			ServerID: serverID.String(),
+		})
+
+		return
+	}
+
+	op := &mctl.Destructive{
+		Action: "delete server",
+		Header: mctl.ServerSummaryHeader,
+		Rows:   mctl.ServerSummaryRows(ctx, theApp, serverID),
+	}
+
+	if err = mctl.ConfirmDestructive(theApp, op, &serverDeleteFlags.confirm); err != nil {
+		log.Fatal(err)
+	}
+
 	client, err := app.NewConditionsClient(ctx, theApp.Config.Conditions, theApp.Reauth)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	response, err := client.ServerDelete(ctx, serverDeleteFlags.serverID)
+	response, err := client.ServerDelete(ctx, serverID.String())
 	if err != nil {
 		log.Fatal(err)
 	}
-	if response != nil {
-		if response.Records != nil {
-			log.Printf("status=%d\nmsg=%s\nserverID=%v", response.StatusCode, response.Message, response.Records.ServerID)
-			return
-		}
+
+	if response == nil {
+		log.Fatal("server delete: no response from the conditions API")
+	}
+
+	mctl.AuditLog(theApp, model.ConditionsAPI, []string{serverID.String()}, audit.Result{
+		Target:     serverID.String(),
+		StatusCode: response.StatusCode,
+		Message:    response.Message,
+	})
+
+	if response.Records != nil {
+		log.Printf("status=%d\nmsg=%s\nserverID=%v", response.StatusCode, response.Message, response.Records.ServerID)
+		return
 	}
+
 	log.Printf("status=%d\nmsg=%s\n", response.StatusCode, response.Message)
 }
 
@@ -52,5 +91,7 @@ func init() {
 	serverDeleteFlags = &serverDeleteParams{}
 
 	mctl.AddServerFlag(serverDelete, &serverDeleteFlags.serverID)
+	mctl.AddConfirmFlags(serverDelete, &serverDeleteFlags.confirm)
+	mctl.AddClientDryRunFlag(serverDelete, &serverDeleteFlags.dryRun)
 	mctl.RequireFlag(serverDelete, mctl.ServerFlag)
 }
@@ -0,0 +1,254 @@
+package diff
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	fleetdbapi "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+	rt "github.com/metal-toolbox/rivets/v2/types"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	mctl "github.com/metal-toolbox/mctl/cmd"
+	"github.com/metal-toolbox/mctl/internal/app"
+	"github.com/metal-toolbox/mctl/internal/fleetdb"
+	"github.com/metal-toolbox/mctl/internal/inventory"
+)
+
+type diffBIOSConfigFlags struct {
+	serverID        string
+	biosConfigSetID string
+	vendor          string
+	model           string
+}
+
+// biosDrift is the BIOS configuration drift of a server from a config set.
+type biosDrift struct {
+	ServerID string                      `json:"server_id"`
+	Name     string                      `json:"name,omitempty"`
+	Settings []inventory.BIOSSettingDiff `json:"settings"`
+	Error    string                      `json:"error,omitempty"`
+}
+
+var (
+	flagsDefinedDiffBIOSConfig *diffBIOSConfigFlags
+)
+
+const (
+	// maxServerPages limits the number of pages of servers compared in fleet mode.
+	maxServerPages = 20
+
+	// fleetTimeout bounds the fleetdb queries made in fleet mode.
+	fleetTimeout = 5 * time.Minute
+)
+
+var diffBIOSConfig = &cobra.Command{
+	Use:   "bios-config",
+	Short: "Compare the BIOS configuration of servers against a BIOS config set",
+	Long: `Compare the latest recorded BIOS configuration of a server against a BIOS config set,
+listing mismatched settings, settings in the set the server doesn't report (missing) and
+settings the server reports that aren't in the set (extra).
+
+Setting names are compared without case and separators so 'BootMode', 'boot_mode' and 'Boot Mode'
+match, the settings of the config set components matching the server vendor and model are used.
+
+With --model instead of --server, the BIOS drift of all servers of the model is summarized.`,
+	Example: `  # settings that differ on a server
+  mctl diff bios-config --server fd5c3a0b-4d2f-4c6c-8e7b-32b0f1d0b4a1 --bios-config-set-id 8c0d21a2-6b3d-4b0a-9f0e-6e4b3a1a9a0f -o text
+
+  # BIOS drift across the servers of a model
+  mctl diff bios-config --vendor dell --model r6515 --bios-config-set-id 8c0d21a2-6b3d-4b0a-9f0e-6e4b3a1a9a0f -o text`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		setID, err := uuid.Parse(flagsDefinedDiffBIOSConfig.biosConfigSetID)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		theApp := mctl.MustCreateApp(cmd.Context())
+
+		timeout := mctl.CmdTimeout
+		if flagsDefinedDiffBIOSConfig.serverID == "" {
+			timeout = fleetTimeout
+		}
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+		defer cancel()
+
+		client, err := app.NewFleetDBAPIClient(ctx, theApp.Config.FleetDBAPI, theApp.Reauth)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		set, err := mctl.BIOSConfigSetByID(ctx, client, setID)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if flagsDefinedDiffBIOSConfig.serverID == "" {
+			fleetBIOSDrift(ctx, client, set, flagsDefinedDiffBIOSConfig.vendor, flagsDefinedDiffBIOSConfig.model)
+			return
+		}
+
+		serverID, err := fleetdb.ResolveServer(ctx, client, flagsDefinedDiffBIOSConfig.serverID)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		s, _, err := client.Get(ctx, serverID)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		drift := serverBIOSDrift(ctx, client, fleetdb.ConvertServer(s), set)
+		if drift.Error != "" {
+			log.Fatal(drift.Error)
+		}
+
+		printBIOSDrift(output, drift)
+	},
+}
+
+// serverBIOSDrift compares the BIOS configuration of the server against the config set.
+func serverBIOSDrift(ctx context.Context, client *fleetdbapi.Client, server *rt.Server, set *fleetdbapi.BiosConfigSet) *biosDrift {
+	drift := &biosDrift{ServerID: server.ID, Name: server.Name, Settings: []inventory.BIOSSettingDiff{}}
+
+	actual, err := mctl.ServerBIOSConfig(ctx, client, uuid.MustParse(server.ID))
+	if err != nil {
+		drift.Error = err.Error()
+		return drift
+	}
+
+	if len(actual) == 0 {
+		drift.Error = "no bios configuration data found"
+		return drift
+	}
+
+	drift.Settings = inventory.CompareBIOS(fleetdb.BiosConfigSetSettings(set, server.Vendor, server.Model), actual)
+
+	return drift
+}
+
+// fleetBIOSDrift prints the BIOS drift of the servers of the vendor and model.
+func fleetBIOSDrift(ctx context.Context, client *fleetdbapi.Client, set *fleetdbapi.BiosConfigSet, vendor, model string) {
+	servers, err := serversByVendorModel(ctx, client, vendor, model)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	drifts := make([]*biosDrift, 0, len(servers))
+	for _, server := range servers {
+		drifts = append(drifts, serverBIOSDrift(ctx, client, server, set))
+	}
+
+	if output == mctl.OutputTypeJSON.String() {
+		printJSON(drifts)
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Server", "Name", "Mismatched", "Missing", "Extra", "Error"})
+	table.SetAutoWrapText(false)
+
+	for _, d := range drifts {
+		counts := inventory.CountBIOSDiffs(d.Settings)
+		table.Append([]string{
+			d.ServerID,
+			d.Name,
+			strconv.Itoa(counts[inventory.Mismatched]),
+			strconv.Itoa(counts[inventory.Missing]),
+			strconv.Itoa(counts[inventory.Extra]),
+			d.Error,
+		})
+	}
+
+	table.Render()
+}
+
+func serversByVendorModel(ctx context.Context, client *fleetdbapi.Client, vendor, model string) ([]*rt.Server, error) {
+	alp := []fleetdbapi.AttributeListParams{
+		{
+			Namespace: fleetdb.ServerVendorAttributeNS,
+			Keys:      []string{"model"},
+			Operator:  "eq",
+			Value:     strings.ToLower(model),
+		},
+	}
+
+	if vendor != "" {
+		alp = append(alp, fleetdbapi.AttributeListParams{
+			Namespace: fleetdb.ServerVendorAttributeNS,
+			Keys:      []string{"vendor"},
+			Operator:  "eq",
+			Value:     strings.ToLower(vendor),
+		})
+	}
+
+	servers := []*rt.Server{}
+
+	for page := 1; page <= maxServerPages; page++ {
+		params := &fleetdbapi.ServerListParams{
+			AttributeListParams: alp,
+			PaginationParams: &fleetdbapi.PaginationParams{
+				Limit: fleetdbapi.MaxPaginationSize,
+				Page:  page,
+			},
+		}
+
+		found, resp, err := client.List(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+
+		for idx := range found {
+			servers = append(servers, fleetdb.ConvertServer(&found[idx]))
+		}
+
+		if resp == nil || page >= resp.TotalPages {
+			break
+		}
+	}
+
+	return servers, nil
+}
+
+func printBIOSDrift(format string, d *biosDrift) {
+	if format == mctl.OutputTypeJSON.String() {
+		printJSON(d)
+		return
+	}
+
+	if len(d.Settings) == 0 {
+		fmt.Println("no differences")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Setting", "Kind", "Expected", "Actual"})
+	table.SetAutoWrapText(false)
+
+	for _, s := range d.Settings {
+		table.Append([]string{s.Key, string(s.Kind), s.Expected, s.Actual})
+	}
+
+	table.Render()
+}
+
+func init() {
+	flagsDefinedDiffBIOSConfig = &diffBIOSConfigFlags{}
+
+	mctl.AddServerFlag(diffBIOSConfig, &flagsDefinedDiffBIOSConfig.serverID)
+	mctl.AddBIOSConfigSetIDFlag(diffBIOSConfig, &flagsDefinedDiffBIOSConfig.biosConfigSetID)
+	mctl.AddVendorFlag(diffBIOSConfig, &flagsDefinedDiffBIOSConfig.vendor)
+	mctl.AddModelFlag(diffBIOSConfig, &flagsDefinedDiffBIOSConfig.model)
+
+	mctl.RequireFlag(diffBIOSConfig, mctl.BIOSConfigSetIDFlag)
+	mctl.RequireOneFlag(diffBIOSConfig, mctl.ServerFlag, mctl.ModelFlag)
+	mctl.MutuallyExclusiveFlags(diffBIOSConfig, mctl.ServerFlag, mctl.ModelFlag)
+	mctl.MutuallyExclusiveFlags(diffBIOSConfig, mctl.ServerFlag, mctl.VendorFlag)
+}
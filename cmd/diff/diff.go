@@ -0,0 +1,41 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"github.com/metal-toolbox/mctl/cmd"
+)
+
+var (
+	output string
+)
+
+var cmdDiff = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare resources",
+	Run: func(cmd *cobra.Command, _ []string) {
+		_ = cmd.Help()
+	},
+}
+
+func printJSON(data any) {
+	b, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(string(b))
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(cmdDiff)
+	cmdDiff.AddCommand(diffServer)
+	cmdDiff.AddCommand(diffInventory)
+	cmdDiff.AddCommand(diffBIOSConfig)
+
+	cmd.AddOutputFlag(cmdDiff, &output)
+}
@@ -0,0 +1,43 @@
+package diff
+
+import (
+	"testing"
+	"time"
+
+	fleetdbapi "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSinceTime(t *testing.T) {
+	now := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	day1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	day5 := time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+
+	biosHistory := []fleetdbapi.VersionedAttributes{{CreatedAt: day1}, {CreatedAt: day5}}
+
+	testcases := []struct {
+		since   string
+		want    time.Time
+		wantErr error
+	}{
+		{since: "0", want: day5},
+		{since: "1", want: day1},
+		{since: "2", wantErr: errBIOSVersion},
+		{since: "24h", want: now.Add(-24 * time.Hour)},
+		{since: "2024-01-03", want: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.since, func(t *testing.T) {
+			got, err := sinceTime(tc.since, biosHistory, now)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package diff
+
+import (
+	"context"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	mctl "github.com/metal-toolbox/mctl/cmd"
+	"github.com/metal-toolbox/mctl/internal/app"
+	"github.com/metal-toolbox/mctl/internal/fleetdb"
+	"github.com/metal-toolbox/mctl/internal/inventory"
+)
+
+type diffInventoryFlags struct {
+	serverID string
+	expected string
+}
+
+var (
+	flagsDefinedDiffInventory *diffInventoryFlags
+)
+
+var diffInventory = &cobra.Command{
+	Use:   "inventory",
+	Short: "Compare the fleetdb inventory of a server against an expected inventory",
+	Long: `Compare the fleetdb inventory of a server against an expected inventory read from a JSON file.
+
+The file holds a server in the JSON format printed by 'mctl get server --list-components' - the server vendor,
+model, serial and its components with their name (slug), vendor, model, serial, firmware.installed and attributes.
+Components are grouped by slug and matched by serial, values only in the expected inventory are reported
+as added and values missing from it as removed.`,
+	Example: `  mctl diff inventory --server fd5c3a0b-4d2f-4c6c-8e7b-32b0f1d0b4a1 --from-file expected.json -o text`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		expected, err := inventory.ReadServer(flagsDefinedDiffInventory.expected)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		theApp := mctl.MustCreateApp(cmd.Context())
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), mctl.CmdTimeout)
+		defer cancel()
+
+		client, err := app.NewFleetDBAPIClient(ctx, theApp.Config.FleetDBAPI, theApp.Reauth)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		serverID, err := fleetdb.ResolveServer(ctx, client, flagsDefinedDiffInventory.serverID)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		server, err := serverInventory(ctx, client, serverID)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		mctl.PrintPathChanges(output, inventory.ComponentGaps(server, expected), "FleetDB", "Expected")
+	},
+}
+
+func init() {
+	flagsDefinedDiffInventory = &diffInventoryFlags{}
+
+	mctl.AddServerFlag(diffInventory, &flagsDefinedDiffInventory.serverID)
+	mctl.AddFromFileFlag(diffInventory, &flagsDefinedDiffInventory.expected, "JSON file with the expected server inventory")
+
+	mctl.RequireFlag(diffInventory, mctl.ServerFlag)
+	mctl.RequireFlag(diffInventory, mctl.FromFileFlag)
+}
@@ -0,0 +1,301 @@
+package diff
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	fleetdbapi "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+	rt "github.com/metal-toolbox/rivets/v2/types"
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	mctl "github.com/metal-toolbox/mctl/cmd"
+	"github.com/metal-toolbox/mctl/internal/app"
+	"github.com/metal-toolbox/mctl/internal/fleetdb"
+	"github.com/metal-toolbox/mctl/internal/inventory"
+)
+
+type diffServerFlags struct {
+	serverID  string
+	since     string
+	sinceFile string
+	save      string
+	against   string
+}
+
+var (
+	flagsDefinedDiffServer *diffServerFlags
+
+	errBIOSVersion = errors.New("no bios configuration recorded at the given version")
+	errSnapshot    = errors.New("snapshot is for a different server")
+)
+
+var diffServer = &cobra.Command{
+	Use:   "server",
+	Short: "Compare the inventory of a server between two points in time or against another server",
+	Long: `Compare the components, firmware versions and BIOS configuration of a server
+between a point in time and its current inventory, or against another server.
+
+The --since value is one of
+ - a duration before now, a date or an RFC3339 time,
+ - a BIOS configuration version, 0 being the latest recorded configuration and 1 the one before it.
+
+--since-file compares against an inventory snapshot saved with --save.
+
+FleetDB records only the latest component firmware versions, firmware changes for components
+whose version was recorded after the given time are reported with an unknown old version.
+Saved snapshots hold the full inventory and give exact firmware differences.
+
+With --against, components of both servers are aligned by slug and slot and their
+models, firmware versions and capacities are compared along with the BIOS configurations.`,
+	Example: `  # changes in the last week
+  mctl diff server --server fd5c3a0b-4d2f-4c6c-8e7b-32b0f1d0b4a1 --since 168h
+
+  # changes since the previous BIOS configuration was recorded
+  mctl diff server --server fd5c3a0b-4d2f-4c6c-8e7b-32b0f1d0b4a1 --since 1
+
+  # save the inventory before a firmware install and compare against it afterwards
+  mctl diff server --server fd5c3a0b-4d2f-4c6c-8e7b-32b0f1d0b4a1 --since 0 --save before.json
+  mctl diff server --server fd5c3a0b-4d2f-4c6c-8e7b-32b0f1d0b4a1 --since-file before.json
+
+  # compare a server against a healthy sibling
+  mctl diff server --server node1 --against node2 -o text`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		theApp := mctl.MustCreateApp(cmd.Context())
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), mctl.CmdTimeout)
+		defer cancel()
+
+		client, err := app.NewFleetDBAPIClient(ctx, theApp.Config.FleetDBAPI, theApp.Reauth)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		serverID, err := fleetdb.ResolveServer(ctx, client, flagsDefinedDiffServer.serverID)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if flagsDefinedDiffServer.against != "" {
+			diffAgainst(ctx, client, serverID, flagsDefinedDiffServer.against)
+			return
+		}
+
+		components, err := mctl.ServerComponents(ctx, client, serverID)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		biosHistory, err := mctl.BIOSConfigHistory(ctx, client, serverID)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		current, err := fleetdb.InventorySnapshot(serverID, components, biosHistory, time.Time{})
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		var previous *inventory.Snapshot
+
+		if flagsDefinedDiffServer.sinceFile != "" {
+			previous, err = snapshotFromFile(flagsDefinedDiffServer.sinceFile, serverID)
+		} else {
+			previous, err = sinceSnapshot(flagsDefinedDiffServer.since, serverID, components, biosHistory, time.Now())
+		}
+
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if flagsDefinedDiffServer.save != "" {
+			if err := inventory.WriteSnapshot(flagsDefinedDiffServer.save, current); err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		printDiff(output, inventory.Compare(previous, current))
+	},
+}
+
+// diffAgainst prints the differences between the server and the server it is compared against.
+func diffAgainst(ctx context.Context, client *fleetdbapi.Client, serverID uuid.UUID, againstRef string) {
+	againstID, err := fleetdb.ResolveServer(ctx, client, againstRef)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	server, err := serverInventory(ctx, client, serverID)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	against, err := serverInventory(ctx, client, againstID)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	printServerDiff(output, inventory.CompareServers(server, against))
+}
+
+// serverInventory returns the server with its components and BIOS configuration.
+func serverInventory(ctx context.Context, client *fleetdbapi.Client, serverID uuid.UUID) (*rt.Server, error) {
+	s, _, err := client.Get(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	server := fleetdb.ConvertServer(s)
+
+	components, err := mctl.ServerComponents(ctx, client, serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	server.Components = fleetdb.ConvertComponents(components)
+
+	// the BIOS configuration is recorded in versioned attributes by current inventory collectors
+	if len(server.BIOSCfg) == 0 {
+		server.BIOSCfg, err = mctl.ServerBIOSConfig(ctx, client, serverID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return server, nil
+}
+
+// snapshotFromFile returns the snapshot of the server saved with --save.
+func snapshotFromFile(path string, serverID uuid.UUID) (*inventory.Snapshot, error) {
+	snapshot, err := inventory.ReadSnapshot(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if snapshot.ServerID != serverID.String() {
+		return nil, errors.Wrap(errSnapshot, fmt.Sprintf("%s: %s", path, snapshot.ServerID))
+	}
+
+	return snapshot, nil
+}
+
+// sinceSnapshot returns the snapshot to compare the current inventory against for the --since value.
+func sinceSnapshot(
+	since string,
+	serverID uuid.UUID,
+	components []fleetdbapi.ServerComponent,
+	biosHistory []fleetdbapi.VersionedAttributes,
+	now time.Time,
+) (*inventory.Snapshot, error) {
+	at, err := sinceTime(since, biosHistory, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return fleetdb.InventorySnapshot(serverID, components, biosHistory, at)
+}
+
+// sinceTime returns the time for a BIOS configuration version or a time value.
+func sinceTime(since string, biosHistory []fleetdbapi.VersionedAttributes, now time.Time) (time.Time, error) {
+	version, err := strconv.Atoi(since)
+	if err != nil {
+		return mctl.ParseSince(since, now)
+	}
+
+	recorded := make([]time.Time, 0, len(biosHistory))
+	for idx := range biosHistory {
+		recorded = append(recorded, biosHistory[idx].CreatedAt)
+	}
+
+	sort.Slice(recorded, func(i, j int) bool { return recorded[i].After(recorded[j]) })
+
+	if version < 0 || version >= len(recorded) {
+		return time.Time{}, errors.Wrap(errBIOSVersion, fmt.Sprintf("%d, %d versions recorded", version, len(recorded)))
+	}
+
+	return recorded[version], nil
+}
+
+func printDiff(format string, d *inventory.Diff) {
+	if format == mctl.OutputTypeJSON.String() {
+		printJSON(d)
+		return
+	}
+
+	fmt.Printf("inventory changes from %s to %s\n", d.From.Local().Format(time.DateTime), d.To.Local().Format(time.DateTime))
+
+	if d.Empty() {
+		fmt.Println("no changes")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Change", "Kind", "Item", "Old", "New"})
+	table.SetAutoWrapText(false)
+
+	for _, c := range d.Components {
+		old, cur := "", c.Vendor+" "+c.Model
+		if c.Change == inventory.Removed {
+			old, cur = cur, old
+		}
+
+		table.Append([]string{string(c.Change), "component", c.Slug + " " + c.Serial, old, cur})
+	}
+
+	for _, c := range d.Firmware {
+		table.Append([]string{string(inventory.Changed), "firmware", c.Slug + " " + c.Serial, c.Old, c.New})
+	}
+
+	for _, c := range d.BIOS {
+		table.Append([]string{string(c.Change), "bios", c.Key, c.Old, c.New})
+	}
+
+	table.Render()
+}
+
+func printServerDiff(format string, d *inventory.ServerDiff) {
+	if format == mctl.OutputTypeJSON.String() {
+		printJSON(d)
+		return
+	}
+
+	fmt.Printf("--- %s\n+++ %s\n", d.Server, d.Against)
+
+	if d.Empty() {
+		fmt.Println("no differences")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Component", "Field", "- " + d.Server, "+ " + d.Against})
+	table.SetAutoWrapText(false)
+
+	for _, m := range d.Mismatches {
+		table.Append([]string{m.Component, m.Field, m.Server, m.Against})
+	}
+
+	table.Render()
+}
+
+func init() {
+	flagsDefinedDiffServer = &diffServerFlags{}
+
+	mctl.AddServerFlag(diffServer, &flagsDefinedDiffServer.serverID)
+	mctl.AddSinceFlag(diffServer, &flagsDefinedDiffServer.since,
+		"compare against a duration ago - '24h', a date, an RFC3339 time, or a BIOS configuration version - '1'")
+	mctl.AddSinceFileFlag(diffServer, &flagsDefinedDiffServer.sinceFile, "compare against an inventory snapshot file saved with --save")
+	mctl.AddSaveFlag(diffServer, &flagsDefinedDiffServer.save, "save the current inventory snapshot to the file")
+	mctl.AddAgainstFlag(diffServer, &flagsDefinedDiffServer.against)
+
+	mctl.RequireFlag(diffServer, mctl.ServerFlag)
+	mctl.RequireOneFlag(diffServer, mctl.SinceFlag, mctl.SinceFileFlag, mctl.AgainstFlag)
+	mctl.MutuallyExclusiveFlags(diffServer, mctl.SinceFlag, mctl.SinceFileFlag, mctl.AgainstFlag)
+	mctl.MutuallyExclusiveFlags(diffServer, mctl.SaveFlag, mctl.AgainstFlag)
+}
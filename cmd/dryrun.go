@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+
+	"github.com/metal-toolbox/mctl/pkg/model"
+)
+
+// APIRequest describes a request to one of the metal-toolbox service APIs,
+// mutating commands print these instead of sending them when --dry-run is given.
+type APIRequest struct {
+	// API is the service the request is sent to.
+	API model.APIKind `json:"api"`
+
+	// Method is the API client method invoked for the request.
+	Method string `json:"method"`
+
+	// ServerID is the server the request applies to, if any.
+	ServerID string `json:"server_id,omitempty"`
+
+	// ID is the identifier of the fleetdb object the request applies to, if any.
+	ID string `json:"id,omitempty"`
+
+	// Kind is the condition kind for condition create requests.
+	Kind rctypes.Kind `json:"kind,omitempty"`
+
+	// Payload is the request body.
+	Payload any `json:"payload,omitempty"`
+}
+
+// PrintDryRun prints the given requests as a JSON list.
+func PrintDryRun(requests ...*APIRequest) {
+	if requests == nil {
+		requests = []*APIRequest{}
+	}
+
+	b, err := json.MarshalIndent(requests, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(string(b))
+}
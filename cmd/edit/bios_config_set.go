@@ -0,0 +1,150 @@
+package edit
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+	fleetdbapi "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+	"github.com/spf13/cobra"
+
+	mctl "github.com/metal-toolbox/mctl/cmd"
+	"github.com/metal-toolbox/mctl/internal/app"
+	"github.com/metal-toolbox/mctl/internal/audit"
+	"github.com/metal-toolbox/mctl/internal/fleetdb"
+	"github.com/metal-toolbox/mctl/pkg/model"
+)
+
+type editBiosConfigSetFlags struct {
+	id             string
+	fromFile       string
+	name           string
+	component      string
+	settings       map[string]string
+	removeSettings []string
+	dryRun         bool
+}
+
+var (
+	flagsDefinedEditBiosConfigSet *editBiosConfigSetFlags
+)
+
+var editBiosConfigSet = &cobra.Command{
+	Use:   "bios-config-set",
+	Short: "Edit a bios config set",
+	Long: `Edit a bios config set, either replacing it with the contents of a JSON file
+or patching the name and individual settings of the current set.
+
+Settings are updated in all components of the set that have them, use --component to
+patch a single component. New settings are added when a single component is patched.`,
+	Example: `  # replace the set
+  mctl edit bios-config-set --bios-config-set-id 8c0d21a2-6b3d-4b0a-9f0e-6e4b3a1a9a0f --from-file set.json
+
+  # patch settings
+  mctl edit bios-config-set --bios-config-set-id 8c0d21a2-6b3d-4b0a-9f0e-6e4b3a1a9a0f \
+    --component bios --setting BootMode=Uefi --remove-setting PxeDev1EnDis`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		theApp := mctl.MustCreateApp(cmd.Context())
+
+		flgs := flagsDefinedEditBiosConfigSet
+
+		id, err := uuid.Parse(flgs.id)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), mctl.CmdTimeout)
+		defer cancel()
+
+		var client *fleetdbapi.Client
+
+		var set *fleetdbapi.BiosConfigSet
+		if flgs.fromFile != "" {
+			set, err = mctl.BIOSConfigSetFromFile(flgs.fromFile)
+		} else {
+			client, err = app.NewFleetDBAPIClient(ctx, theApp.Config.FleetDBAPI, theApp.Reauth)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			set, err = patchedBiosConfigSet(ctx, client, id, flgs)
+		}
+
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		set.ID = id.String()
+
+		if err := fleetdb.ValidateBiosConfigSet(set); err != nil {
+			log.Fatal(err)
+		}
+
+		if flgs.dryRun {
+			mctl.PrintDryRun(&mctl.APIRequest{
+				API:     model.FleetDBAPI,
+				Method:  "UpdateServerBiosConfigSet",
+				ID:      id.String(),
+				Payload: set,
+			})
+
+			return
+		}
+
+		if client == nil {
+			client, err = app.NewFleetDBAPIClient(ctx, theApp.Config.FleetDBAPI, theApp.Reauth)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		if _, err := client.UpdateServerBiosConfigSet(ctx, id, *set); err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Println("bios config set updated: " + id.String())
+
+		mctl.AuditLog(theApp, model.FleetDBAPI, []string{id.String()}, audit.Result{Target: id.String(), Message: "bios config set updated"})
+	},
+}
+
+// patchedBiosConfigSet returns the current set with the name and settings from the flags applied.
+func patchedBiosConfigSet(ctx context.Context, client *fleetdbapi.Client, id uuid.UUID, flgs *editBiosConfigSetFlags) (*fleetdbapi.BiosConfigSet, error) {
+	set, err := mctl.BIOSConfigSetByID(ctx, client, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if flgs.name != "" {
+		set.Name = flgs.name
+	}
+
+	if len(flgs.settings) > 0 || len(flgs.removeSettings) > 0 {
+		if err := fleetdb.PatchBiosConfigSet(set, flgs.component, flgs.settings, flgs.removeSettings); err != nil {
+			return nil, err
+		}
+	}
+
+	return set, nil
+}
+
+func init() {
+	flagsDefinedEditBiosConfigSet = &editBiosConfigSetFlags{}
+
+	mctl.AddBIOSConfigSetIDFlag(editBiosConfigSet, &flagsDefinedEditBiosConfigSet.id)
+	mctl.AddFromFileFlag(editBiosConfigSet, &flagsDefinedEditBiosConfigSet.fromFile, "path to JSON file containing the updated bios config set")
+	mctl.AddNameFlag(editBiosConfigSet, &flagsDefinedEditBiosConfigSet.name, "name of the bios config set")
+	mctl.AddComponentTypeFlag(editBiosConfigSet, &flagsDefinedEditBiosConfigSet.component)
+	mctl.AddSettingFlag(editBiosConfigSet, &flagsDefinedEditBiosConfigSet.settings)
+	mctl.AddRemoveSettingFlag(editBiosConfigSet, &flagsDefinedEditBiosConfigSet.removeSettings)
+	mctl.AddClientDryRunFlag(editBiosConfigSet, &flagsDefinedEditBiosConfigSet.dryRun)
+
+	mctl.RequireFlag(editBiosConfigSet, mctl.BIOSConfigSetIDFlag)
+	mctl.RequireOneFlag(editBiosConfigSet, mctl.FromFileFlag, mctl.NameFlag, mctl.SettingFlag, mctl.RemoveSettingFlag)
+
+	mctl.MutuallyExclusiveFlags(editBiosConfigSet, mctl.FromFileFlag, mctl.NameFlag)
+	mctl.MutuallyExclusiveFlags(editBiosConfigSet, mctl.FromFileFlag, mctl.ComponentTypeFlag)
+	mctl.MutuallyExclusiveFlags(editBiosConfigSet, mctl.FromFileFlag, mctl.SettingFlag)
+	mctl.MutuallyExclusiveFlags(editBiosConfigSet, mctl.FromFileFlag, mctl.RemoveSettingFlag)
+}
@@ -6,6 +6,10 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var (
+	output string
+)
+
 var edit = &cobra.Command{
 	Use:   "edit",
 	Short: "Edit resources",
@@ -17,4 +21,6 @@ var edit = &cobra.Command{
 func init() {
 	cmd.RootCmd.AddCommand(edit)
 	edit.AddCommand(editFirmwareSet)
+	edit.AddCommand(editBiosConfigSet)
+	edit.AddCommand(editFirmware)
 }
@@ -0,0 +1,154 @@
+package edit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+	fleetdbapi "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+	"github.com/spf13/cobra"
+
+	mctl "github.com/metal-toolbox/mctl/cmd"
+	"github.com/metal-toolbox/mctl/internal/app"
+	"github.com/metal-toolbox/mctl/internal/audit"
+	"github.com/metal-toolbox/mctl/internal/fleetdb"
+	"github.com/metal-toolbox/mctl/internal/inventory"
+	"github.com/metal-toolbox/mctl/pkg/model"
+)
+
+type editFirmwareFlags struct {
+	id     string
+	fields mctl.FirmwareFields
+	dryRun bool
+}
+
+var (
+	flagsDefinedEditFirmware *editFirmwareFlags
+)
+
+var editFirmware = &cobra.Command{
+	Use:   "firmware",
+	Short: "Edit a firmware object",
+	Long: `Edit the fields of a firmware object in place, fields without a flag given are left as is.
+
+The changed fields are listed before the firmware is updated.`,
+	Example: `  # fix the checksum and repository URL of a firmware
+  mctl edit firmware --firmware-id 4f0e9d36-4c4a-4a5c-9d61-1a3f8f0b2f4e \
+    --checksum 5a3e...c1 --repository-url https://firmware.example.com/dell/bios-2.19.1.bin
+
+  # set the models a firmware applies to
+  mctl edit firmware --firmware-id 4f0e9d36-4c4a-4a5c-9d61-1a3f8f0b2f4e --models r6515,r6615`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		theApp := mctl.MustCreateApp(cmd.Context())
+
+		id, err := uuid.Parse(flagsDefinedEditFirmware.id)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), mctl.CmdTimeout)
+		defer cancel()
+
+		client, err := app.NewFleetDBAPIClient(ctx, theApp.Config.FleetDBAPI, theApp.Reauth)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		firmware, _, err := client.GetServerComponentFirmware(ctx, id)
+		if err != nil {
+			log.Fatal("fleetdb API client returned error: ", err)
+		}
+
+		current := *firmware
+		applyFirmwareFields(cmd, firmware, &flagsDefinedEditFirmware.fields)
+
+		changes := inventory.ComparePaths(current, *firmware)
+		if len(changes) == 0 {
+			fmt.Println("no changes to firmware: " + id.String())
+			return
+		}
+
+		if err := fleetdb.ValidateFirmware(firmware); err != nil {
+			log.Fatal(err)
+		}
+
+		mctl.PrintPathChanges(mctl.OutputTypeText.String(), changes, "Current", "New")
+
+		if flagsDefinedEditFirmware.dryRun {
+			mctl.PrintDryRun(&mctl.APIRequest{
+				API:     model.FleetDBAPI,
+				Method:  "UpdateServerComponentFirmware",
+				ID:      id.String(),
+				Payload: firmware,
+			})
+
+			return
+		}
+
+		if _, err := client.UpdateServerComponentFirmware(ctx, id, *firmware); err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Println("firmware updated: " + id.String())
+
+		mctl.AuditLog(theApp, model.FleetDBAPI, []string{id.String()}, audit.Result{Target: id.String(), Message: "firmware updated"})
+	},
+}
+
+// applyFirmwareFields sets the firmware fields with a flag given.
+func applyFirmwareFields(cmd *cobra.Command, fw *fleetdbapi.ComponentFirmwareVersion, fields *mctl.FirmwareFields) {
+	changed := cmd.Flags().Changed
+
+	if changed(mctl.VendorFlag.Name()) {
+		fw.Vendor = strings.ToLower(fields.Vendor)
+	}
+
+	if changed(mctl.ModelsFlag.Name()) {
+		fw.Model = fields.Models
+	}
+
+	if changed(mctl.ComponentTypeFlag.Name()) {
+		fw.Component = strings.ToLower(fields.Component)
+	}
+
+	if changed(mctl.FirmwareVersionFlag.Name()) {
+		fw.Version = fields.Version
+	}
+
+	if changed(mctl.FilenameFlag.Name()) {
+		fw.Filename = fields.Filename
+	}
+
+	if changed(mctl.ChecksumFlag.Name()) {
+		fw.Checksum = strings.ToLower(fields.Checksum)
+	}
+
+	if changed(mctl.UpstreamURLFlag.Name()) {
+		fw.UpstreamURL = fields.UpstreamURL
+	}
+
+	if changed(mctl.RepositoryURLFlag.Name()) {
+		fw.RepositoryURL = fields.RepositoryURL
+	}
+
+	if changed(mctl.InstallInbandFlag.Name()) {
+		fw.InstallInband = &fields.InstallInband
+	}
+
+	if changed(mctl.OEMFlag.Name()) {
+		fw.OEM = &fields.OEM
+	}
+}
+
+func init() {
+	flagsDefinedEditFirmware = &editFirmwareFlags{}
+
+	mctl.AddFirmwareIDFlag(editFirmware, &flagsDefinedEditFirmware.id)
+	mctl.AddFirmwareFieldFlags(editFirmware, &flagsDefinedEditFirmware.fields)
+	mctl.AddClientDryRunFlag(editFirmware, &flagsDefinedEditFirmware.dryRun)
+
+	mctl.RequireFlag(editFirmware, mctl.FirmwareIDFlag)
+	mctl.RequireOneFlag(editFirmware, mctl.FirmwareFieldFlags...)
+}
@@ -1,108 +1,362 @@
 package edit
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"maps"
+	"slices"
+	"strings"
 
 	"github.com/google/uuid"
 	fleetdbapi "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	mctl "github.com/metal-toolbox/mctl/cmd"
 	"github.com/metal-toolbox/mctl/internal/app"
+	"github.com/metal-toolbox/mctl/internal/audit"
+	"github.com/metal-toolbox/mctl/internal/fleetdb"
 	"github.com/metal-toolbox/mctl/pkg/model"
 )
 
 var (
 	editFWSetFlags mctl.FirmwareSetFlags
+
+	errFirmwareSetEdit = errors.New("invalid firmware set edit")
 )
 
+// firmwareSetEdit holds the requests to edit a firmware set, firmware is added before it's removed.
+type firmwareSetEdit struct {
+	update *fleetdbapi.ComponentFirmwareSetRequest
+	remove *fleetdbapi.ComponentFirmwareSetRequest
+}
+
 var editFirmwareSet = &cobra.Command{
 	Use:   "firmware-set",
 	Short: "Edit a firmware set",
+	Long: `Edit the name, labels and firmware of a firmware set, the set is printed after the edit.
+
+Labels given with --labels are merged into the set labels, with --replace-labels they replace all labels.
+The vendor and model labels of a set can be changed but not removed.
+--replace-component replaces the set firmware for a component slug with the given firmware.
+When removing firmware fails the set is restored to its name, labels and firmware before the edit.`,
+	Example: `  # add a label and remove the latest label
+  mctl edit firmware-set --set-id <> --labels team=fleet --remove-labels latest
+
+  # replace the BIOS firmware of the set
+  mctl edit firmware-set --set-id <> --replace-component bios=<firmware ID>`,
 	Run: func(cmd *cobra.Command, _ []string) {
+		if editFWSetFlags.ReplaceLabels && len(editFWSetFlags.Labels) == 0 {
+			log.Fatal(errors.Wrap(errFirmwareSetEdit, "--replace-labels requires --labels"))
+		}
+
 		theApp := mctl.MustCreateApp(cmd.Context())
 
-		client, err := app.NewFleetDBAPIClient(cmd.Context(), theApp.Config.FleetDBAPI, theApp.Reauth)
+		id, err := uuid.Parse(editFWSetFlags.ID)
+		if err != nil {
+			log.Fatalf("invalid set-id: %s, error: %s", editFWSetFlags.ID, err.Error())
+		}
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), mctl.CmdTimeout)
+		defer cancel()
+
+		client, err := app.NewFleetDBAPIClient(ctx, theApp.Config.FleetDBAPI, theApp.Reauth)
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		id, err := uuid.Parse(editFWSetFlags.ID)
+		set, _, err := client.GetServerComponentFirmwareSet(ctx, id)
 		if err != nil {
-			log.Fatalf("invalid set-id: %s, error: %s", editFWSetFlags.ID, err.Error())
+			log.Fatal(err)
 		}
 
-		payload := fleetdbapi.ComponentFirmwareSetRequest{
-			ID:                     id,
-			ComponentFirmwareUUIDs: []string{},
+		edit, err := firmwareSetEditRequests(ctx, client, set, &editFWSetFlags)
+		if err != nil {
+			log.Fatal(err)
 		}
 
-		var attrs *fleetdbapi.Attributes
-		var payloadUpdated bool
+		if edit.update == nil && edit.remove == nil {
+			log.Fatal(errors.Wrap(errFirmwareSetEdit, "nothing to change"))
+		}
+
+		if editFWSetFlags.DryRun {
+			requests := []*mctl.APIRequest{}
+			if edit.update != nil {
+				requests = append(requests, &mctl.APIRequest{
+					API:     model.FleetDBAPI,
+					Method:  "UpdateComponentFirmwareSetRequest",
+					ID:      id.String(),
+					Payload: edit.update,
+				})
+			}
+
+			if edit.remove != nil {
+				requests = append(requests, &mctl.APIRequest{
+					API:     model.FleetDBAPI,
+					Method:  "RemoveServerComponentFirmwareSetFirmware",
+					ID:      id.String(),
+					Payload: edit.remove,
+				})
+			}
 
-		if len(editFWSetFlags.Labels) > 0 {
-			attrs, err = mctl.AttributeFromLabels(model.AttributeNSFirmwareSetLabels, editFWSetFlags.Labels)
+			mctl.PrintDryRun(requests...)
+
+			return
+		}
+
+		var results []audit.Result
+
+		if edit.update != nil {
+			_, err = client.UpdateComponentFirmwareSetRequest(ctx, id, *edit.update)
 			if err != nil {
 				log.Fatal(err)
 			}
 
-			payload.Attributes = []fleetdbapi.Attributes{*attrs}
-			payloadUpdated = true
-
+			results = append(results, audit.Result{Target: id.String(), Message: "firmware set updated"})
 		}
 
-		if len(editFWSetFlags.AddFirmwareUUIDs) > 0 {
-			for _, id := range editFWSetFlags.AddFirmwareUUIDs {
-				_, err = uuid.Parse(id)
-				if err != nil {
-					log.Fatal(err)
+		if edit.remove != nil {
+			_, err = client.RemoveServerComponentFirmwareSetFirmware(ctx, id, *edit.remove)
+			if err != nil {
+				results = append(results, audit.Result{Target: id.String(), Message: "firmware set uuids remove failed: " + err.Error()})
+
+				// the edit is applied as a whole, the update is reverted
+				if edit.update != nil {
+					results = append(results, restoreFirmwareSet(ctx, client, set, edit.update)...)
 				}
 
-				payload.ComponentFirmwareUUIDs = append(payload.ComponentFirmwareUUIDs, id)
-				payloadUpdated = true
+				mctl.AuditLog(theApp, model.FleetDBAPI, []string{id.String()}, results...)
+				log.Fatal(err)
 			}
+
+			results = append(results, audit.Result{
+				Target:  id.String(),
+				Message: "firmware set uuids removed: " + strings.Join(edit.remove.ComponentFirmwareUUIDs, ","),
+			})
+		}
+
+		mctl.AuditLog(theApp, model.FleetDBAPI, []string{id.String()}, results...)
+
+		set, _, err = client.GetServerComponentFirmwareSet(ctx, id)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		mctl.PrintResults(output, set)
+	},
+}
+
+// firmwareSetEditRequests returns the update and remove requests for the edit of the set,
+// requests without changes are nil.
+func firmwareSetEditRequests(
+	ctx context.Context,
+	client *fleetdbapi.Client,
+	set *fleetdbapi.ComponentFirmwareSet,
+	flgs *mctl.FirmwareSetFlags,
+) (*firmwareSetEdit, error) {
+	add, err := parseFirmwareIDs(flgs.AddFirmwareUUIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	remove, err := parseFirmwareIDs(flgs.RemoveFirmwareUUIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, slug := range slices.Sorted(maps.Keys(flgs.ReplaceComponents)) {
+		current, replacement, err := componentReplacement(ctx, client, set, slug, flgs.ReplaceComponents[slug])
+		if err != nil {
+			return nil, err
 		}
 
-		if editFWSetFlags.FirmwareSetName != "" {
-			payload.Name = editFWSetFlags.FirmwareSetName
-			payloadUpdated = true
+		add = append(add, replacement)
+		remove = append(remove, current...)
+	}
+
+	for _, fwID := range add {
+		if slices.Contains(remove, fwID) {
+			return nil, errors.Wrap(errFirmwareSetEdit, "firmware is both added and removed: "+fwID)
+		}
+	}
+
+	edit := &firmwareSetEdit{}
+
+	update := &fleetdbapi.ComponentFirmwareSetRequest{
+		ID:                     set.UUID,
+		Name:                   flgs.FirmwareSetName,
+		ComponentFirmwareUUIDs: add,
+	}
+
+	if len(flgs.Labels) > 0 || len(flgs.RemoveLabels) > 0 || flgs.ReplaceLabels {
+		current, err := fleetdb.FirmwareSetLabels(set)
+		if err != nil {
+			return nil, err
 		}
 
-		if payloadUpdated {
-			_, err = client.UpdateComponentFirmwareSetRequest(cmd.Context(), id, payload)
+		labels, err := fleetdb.EditFirmwareSetLabels(current, flgs.Labels, flgs.RemoveLabels, flgs.ReplaceLabels)
+		if err != nil {
+			return nil, err
+		}
+
+		if !maps.Equal(current, labels) {
+			attrs, err := mctl.AttributeFromLabels(model.AttributeNSFirmwareSetLabels, labels)
 			if err != nil {
-				log.Fatal(err)
+				return nil, err
 			}
-			fmt.Println("firmware set updated: " + id.String())
+
+			update.Attributes = []fleetdbapi.Attributes{*attrs}
 		}
+	}
 
-		if len(editFWSetFlags.RemoveFirmwareUUIDs) > 0 {
-			for _, id := range editFWSetFlags.RemoveFirmwareUUIDs {
-				_, err = uuid.Parse(id)
-				if err != nil {
-					log.Fatal(err)
-				}
+	if update.Name != "" || len(update.Attributes) > 0 || len(update.ComponentFirmwareUUIDs) > 0 {
+		edit.update = update
+	}
 
-				payload.ComponentFirmwareUUIDs = append(payload.ComponentFirmwareUUIDs, id)
+	if len(remove) > 0 {
+		edit.remove = &fleetdbapi.ComponentFirmwareSetRequest{
+			ID:                     set.UUID,
+			ComponentFirmwareUUIDs: remove,
+		}
+	}
+
+	return edit, nil
+}
+
+// restoreFirmwareSet reverts the update of a failed edit, the name, labels and firmware of the set
+// are restored to their values before the edit.
+func restoreFirmwareSet(
+	ctx context.Context,
+	client *fleetdbapi.Client,
+	set *fleetdbapi.ComponentFirmwareSet,
+	update *fleetdbapi.ComponentFirmwareSetRequest,
+) []audit.Result {
+	results := []audit.Result{}
+	target := set.UUID.String()
+
+	restore := func(message string, apply func() error) {
+		if err := apply(); err != nil {
+			log.Printf("unable to restore firmware set %s: %s: %s", target, message, err.Error())
+			results = append(results, audit.Result{Target: target, Message: "firmware set restore failed: " + message + ": " + err.Error()})
+
+			return
+		}
+
+		results = append(results, audit.Result{Target: target, Message: "firmware set restored: " + message})
+	}
+
+	if update.Name != "" || len(update.Attributes) > 0 {
+		restore("name, labels", func() error {
+			labels, err := fleetdb.FirmwareSetLabels(set)
+			if err != nil {
+				return err
 			}
 
-			_, err = client.RemoveServerComponentFirmwareSetFirmware(cmd.Context(), id, payload)
+			attrs, err := mctl.AttributeFromLabels(model.AttributeNSFirmwareSetLabels, labels)
 			if err != nil {
-				log.Fatal(err)
+				return err
 			}
-			fmt.Println("firmware set uuids removed: " + id.String())
+
+			_, err = client.UpdateComponentFirmwareSetRequest(ctx, set.UUID, fleetdbapi.ComponentFirmwareSetRequest{
+				ID:         set.UUID,
+				Name:       set.Name,
+				Attributes: []fleetdbapi.Attributes{*attrs},
+			})
+
+			return err
+		})
+	}
+
+	added := []string{}
+
+	for _, fwID := range update.ComponentFirmwareUUIDs {
+		if !slices.ContainsFunc(set.ComponentFirmware, func(fw fleetdbapi.ComponentFirmwareVersion) bool { return fw.UUID.String() == fwID }) {
+			added = append(added, fwID)
 		}
-	},
+	}
+
+	if len(added) > 0 {
+		restore("firmware uuids added: "+strings.Join(added, ","), func() error {
+			_, err := client.RemoveServerComponentFirmwareSetFirmware(ctx, set.UUID, fleetdbapi.ComponentFirmwareSetRequest{
+				ID:                     set.UUID,
+				ComponentFirmwareUUIDs: added,
+			})
+
+			return err
+		})
+	}
+
+	return results
+}
+
+// componentReplacement returns the set firmware for the component slug and the firmware replacing it,
+// the replacement must be firmware for the component.
+func componentReplacement(
+	ctx context.Context,
+	client *fleetdbapi.Client,
+	set *fleetdbapi.ComponentFirmwareSet,
+	slug, firmwareID string,
+) (current []string, replacement string, err error) {
+	id, err := uuid.Parse(firmwareID)
+	if err != nil {
+		return nil, "", errors.Wrap(errFirmwareSetEdit, fmt.Sprintf("%s: invalid firmware ID %s", slug, firmwareID))
+	}
+
+	fw, _, err := client.GetServerComponentFirmware(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !strings.EqualFold(fw.Component, slug) {
+		return nil, "", errors.Wrap(errFirmwareSetEdit, fmt.Sprintf("firmware %s is for component %s, not %s", id, fw.Component, slug))
+	}
+
+	ids := fleetdb.FirmwareSetComponentFirmware(set, slug)
+	if len(ids) > 1 {
+		return nil, "", errors.Wrap(
+			errFirmwareSetEdit,
+			fmt.Sprintf("set has %d firmware for component %s, use --add-firmware-ids and --remove-firmware-ids", len(ids), slug),
+		)
+	}
+
+	if len(ids) == 1 && ids[0] == id {
+		return nil, "", errors.Wrap(errFirmwareSetEdit, fmt.Sprintf("firmware %s is already the %s firmware of the set", id, slug))
+	}
+
+	for _, currentID := range ids {
+		current = append(current, currentID.String())
+	}
+
+	return current, id.String(), nil
+}
+
+func parseFirmwareIDs(ids []string) ([]string, error) {
+	parsed := make([]string, 0, len(ids))
+
+	for _, id := range ids {
+		if _, err := uuid.Parse(id); err != nil {
+			return nil, err
+		}
+
+		parsed = append(parsed, id)
+	}
+
+	return parsed, nil
 }
 
 func init() {
 	mctl.AddFirmwareSetFlag(editFirmwareSet, &editFWSetFlags.ID)
 	mctl.AddNameFlag(editFirmwareSet, &editFWSetFlags.FirmwareSetName, "New name of the firmware set")
 	mctl.AddLabelsFlag(editFirmwareSet, &editFWSetFlags.Labels,
-		"Labels to assign to the firmware set - 'vendor=foo,model=bar'")
+		"Labels to merge into the firmware set labels - 'vendor=foo,model=bar'")
+	mctl.AddReplaceLabelsFlag(editFirmwareSet, &editFWSetFlags.ReplaceLabels)
+	mctl.AddRemoveLabelsFlag(editFirmwareSet, &editFWSetFlags.RemoveLabels)
 	mctl.AddFirmwareAddIDsFlag(editFirmwareSet, &editFWSetFlags.AddFirmwareUUIDs)
 	mctl.AddFirmwareRemoveIDsFlag(editFirmwareSet, &editFWSetFlags.RemoveFirmwareUUIDs)
+	mctl.AddReplaceComponentFlag(editFirmwareSet, &editFWSetFlags.ReplaceComponents)
+	mctl.AddClientDryRunFlag(editFirmwareSet, &editFWSetFlags.DryRun)
+	mctl.AddOutputFlag(editFirmwareSet, &output)
 
 	mctl.RequireFlag(editFirmwareSet, mctl.FirmwareSetFlag)
 }
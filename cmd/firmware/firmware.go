@@ -0,0 +1,27 @@
+package firmware
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/metal-toolbox/mctl/cmd"
+)
+
+var (
+	output string
+)
+
+var cmdFirmware = &cobra.Command{
+	Use:   "firmware",
+	Short: "Manage firmware artifacts",
+	Run: func(cmd *cobra.Command, _ []string) {
+		_ = cmd.Help()
+	},
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(cmdFirmware)
+	cmdFirmware.AddCommand(verifyFirmware)
+	cmdFirmware.AddCommand(importFirmware)
+
+	cmd.AddOutputFlag(cmdFirmware, &output)
+}
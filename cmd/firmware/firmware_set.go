@@ -0,0 +1,20 @@
+package firmware
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/metal-toolbox/mctl/cmd"
+)
+
+var cmdFirmwareSet = &cobra.Command{
+	Use:   "firmware-set",
+	Short: "Manage the firmware sets installed on servers",
+	Run: func(cmd *cobra.Command, _ []string) {
+		_ = cmd.Help()
+	},
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(cmdFirmwareSet)
+	cmdFirmwareSet.AddCommand(promoteFirmwareSet)
+}
@@ -0,0 +1,168 @@
+package firmware
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	common "github.com/metal-toolbox/bmc-common"
+	fleetdbapi "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	mctl "github.com/metal-toolbox/mctl/cmd"
+	"github.com/metal-toolbox/mctl/internal/app"
+	"github.com/metal-toolbox/mctl/internal/audit"
+	"github.com/metal-toolbox/mctl/internal/firmware"
+	"github.com/metal-toolbox/mctl/internal/fleetdb"
+	"github.com/metal-toolbox/mctl/pkg/model"
+)
+
+type importFirmwareFlags struct {
+	vendor        string
+	model         string
+	catalogFile   string
+	repositoryURL string
+	create        bool
+	dryRun        bool
+}
+
+var (
+	flagsDefinedImportFirmware *importFirmwareFlags
+
+	errCatalogVendor = errors.New("catalogs are supported for dell and supermicro")
+)
+
+var importFirmware = &cobra.Command{
+	Use:   "import",
+	Short: "Import firmware from a vendor catalog",
+	Long: `Import the firmware for a model from a vendor catalog - a Dell Catalog.xml or a Supermicro manifest,
+Supermicro doesn't publish a catalog so the manifest is a JSON file listing the firmware downloads:
+
+  {
+    "base_url": "https://www.supermicro.com/Bios/softfiles/",
+    "firmware": [
+      {"models": ["X11DPH-T"], "component": "bios", "version": "3.9", "filename": "X11DPH9.B23.zip", "checksum": "md5sum:..."}
+    ]
+  }
+
+The firmware is printed in the format read by 'mctl create firmware --from-file' for review,
+with --create the firmware is created in fleetdb. Firmware is created with a repository URL
+under the vendor directory of --repository-url.`,
+	Example: `  # review the firmware for a model from the Dell catalog
+  mctl firmware import --vendor dell --model r6515 --from-file Catalog.xml --repository-url https://firmware.example.com/files
+
+  # create the firmware in fleetdb
+  mctl firmware import --vendor dell --model r6515 --from-file Catalog.xml --repository-url https://firmware.example.com/files --create`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		firmwares, err := firmwareFromCatalog(flagsDefinedImportFirmware)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if !flagsDefinedImportFirmware.create {
+			b, err := json.MarshalIndent(firmwares, "", "  ")
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			fmt.Println(string(b))
+
+			return
+		}
+
+		for idx := range firmwares {
+			if err := fleetdb.ValidateFirmware(&firmwares[idx]); err != nil {
+				log.Fatal(errors.Wrap(err, firmwares[idx].Filename))
+			}
+		}
+
+		if flagsDefinedImportFirmware.dryRun {
+			requests := make([]*mctl.APIRequest, 0, len(firmwares))
+			for idx := range firmwares {
+				requests = append(requests, &mctl.APIRequest{
+					API:     model.FleetDBAPI,
+					Method:  "CreateServerComponentFirmware",
+					Payload: firmwares[idx],
+				})
+			}
+
+			mctl.PrintDryRun(requests...)
+
+			return
+		}
+
+		theApp := mctl.MustCreateApp(cmd.Context())
+
+		client, err := app.NewFleetDBAPIClient(cmd.Context(), theApp.Config.FleetDBAPI, theApp.Reauth)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		results := make([]audit.Result, 0, len(firmwares))
+		for idx := range firmwares {
+			id, _, err := client.CreateServerComponentFirmware(cmd.Context(), firmwares[idx])
+			if err != nil {
+				mctl.AuditLog(theApp, model.FleetDBAPI, nil, results...)
+				log.Fatal(errors.Wrap(err, firmwares[idx].Filename))
+			}
+
+			log.Println(id)
+
+			results = append(results, audit.Result{ID: id.String(), Message: "firmware imported from " + firmwares[idx].UpstreamURL})
+		}
+
+		mctl.AuditLog(theApp, model.FleetDBAPI, nil, results...)
+	},
+}
+
+// firmwareFromCatalog returns the firmware for the model in the catalog of the vendor.
+func firmwareFromCatalog(flgs *importFirmwareFlags) ([]fleetdbapi.ComponentFirmwareVersion, error) {
+	data, err := os.ReadFile(flgs.catalogFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var firmwares []fleetdbapi.ComponentFirmwareVersion
+
+	switch common.FormatVendorName(flgs.vendor) {
+	case common.VendorDell:
+		firmwares, err = firmware.ParseDellCatalog(data, flgs.model)
+	case common.VendorSupermicro:
+		firmwares, err = firmware.ParseSupermicroManifest(data, flgs.model)
+	default:
+		return nil, errors.Wrap(errCatalogVendor, flgs.vendor)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(firmwares) == 0 {
+		return nil, errors.Wrap(firmware.ErrCatalog, "no firmware found for model "+flgs.model)
+	}
+
+	if flgs.repositoryURL != "" {
+		if err := firmware.SetRepositoryURLs(firmwares, flgs.repositoryURL); err != nil {
+			return nil, err
+		}
+	}
+
+	return firmwares, nil
+}
+
+func init() {
+	flagsDefinedImportFirmware = &importFirmwareFlags{}
+
+	mctl.AddVendorFlag(importFirmware, &flagsDefinedImportFirmware.vendor)
+	mctl.AddModelFlag(importFirmware, &flagsDefinedImportFirmware.model)
+	mctl.AddFromFileFlag(importFirmware, &flagsDefinedImportFirmware.catalogFile, "vendor catalog file - Dell Catalog.xml or a Supermicro manifest")
+	mctl.AddRepositoryURLFlag(importFirmware, &flagsDefinedImportFirmware.repositoryURL, "base URL of the firmware repository, firmware files are under the vendor directory")
+	mctl.AddCreateFlag(importFirmware, &flagsDefinedImportFirmware.create, "create the firmware in fleetdb")
+	mctl.AddClientDryRunFlag(importFirmware, &flagsDefinedImportFirmware.dryRun)
+
+	mctl.RequireFlag(importFirmware, mctl.VendorFlag)
+	mctl.RequireFlag(importFirmware, mctl.ModelFlag)
+	mctl.RequireFlag(importFirmware, mctl.FromFileFlag)
+}
@@ -0,0 +1,220 @@
+package firmware
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+	fleetdbapi "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	mctl "github.com/metal-toolbox/mctl/cmd"
+	"github.com/metal-toolbox/mctl/internal/app"
+	"github.com/metal-toolbox/mctl/internal/audit"
+	"github.com/metal-toolbox/mctl/internal/fleetdb"
+	"github.com/metal-toolbox/mctl/pkg/model"
+)
+
+type promoteFirmwareSetFlags struct {
+	id      string
+	confirm mctl.ConfirmParams
+	dryRun  bool
+}
+
+var (
+	flagsDefinedPromoteFirmwareSet *promoteFirmwareSetFlags
+)
+
+var promoteFirmwareSet = &cobra.Command{
+	Use:   "promote",
+	Short: "Promote a firmware set to be installed on servers of its vendor, model",
+	Long: `Promote a firmware set by moving the latest and default labels to it from the other sets
+with the same vendor and model labels, 'mctl install firmware-set' installs the set holding both labels.
+
+The promoted set records the set it replaced in the previous-set label, promote that set to roll back.`,
+	Example: `  # promote a set
+  mctl firmware-set promote --set-id 8c0d21a2-6b3d-4b0a-9f0e-6e4b3a1a9a0f
+
+  # review the label changes
+  mctl firmware-set promote --set-id 8c0d21a2-6b3d-4b0a-9f0e-6e4b3a1a9a0f --dry-run`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		theApp := mctl.MustCreateApp(cmd.Context())
+
+		id, err := uuid.Parse(flagsDefinedPromoteFirmwareSet.id)
+		if err != nil {
+			log.Fatalf("invalid set-id: %s, error: %s", flagsDefinedPromoteFirmwareSet.id, err.Error())
+		}
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), mctl.CmdTimeout)
+		defer cancel()
+
+		client, err := app.NewFleetDBAPIClient(ctx, theApp.Config.FleetDBAPI, theApp.Reauth)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		sets, err := mctl.ListFirmwareSets(ctx, client)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		updates, err := fleetdb.PromoteFirmwareSet(sets, id)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if len(updates) == 0 {
+			fmt.Println("firmware set is already the only promoted set: " + id.String())
+			return
+		}
+
+		payloads := make([]fleetdbapi.ComponentFirmwareSetRequest, 0, len(updates))
+		for idx := range updates {
+			payload, err := labelsPayload(&updates[idx], updates[idx].NewLabels)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			payloads = append(payloads, payload)
+		}
+
+		if flagsDefinedPromoteFirmwareSet.dryRun {
+			requests := make([]*mctl.APIRequest, 0, len(payloads))
+			for idx := range payloads {
+				requests = append(requests, &mctl.APIRequest{
+					API:     model.FleetDBAPI,
+					Method:  "UpdateComponentFirmwareSetRequest",
+					ID:      payloads[idx].ID.String(),
+					Payload: payloads[idx],
+				})
+			}
+
+			mctl.PrintDryRun(requests...)
+
+			return
+		}
+
+		op := &mctl.Destructive{
+			Action: "promote firmware set",
+			Header: []string{"Set ID", "Name", "Labels", "New Labels"},
+		}
+
+		for idx := range updates {
+			u := &updates[idx]
+			op.Rows = append(op.Rows, []string{u.ID.String(), u.Name, mctl.FormatLabels(u.OldLabels), mctl.FormatLabels(u.NewLabels)})
+		}
+
+		if err = mctl.ConfirmDestructive(theApp, op, &flagsDefinedPromoteFirmwareSet.confirm); err != nil {
+			log.Fatal(err)
+		}
+
+		results := make([]audit.Result, 0, len(updates))
+
+		for idx := range payloads {
+			if _, err := client.UpdateComponentFirmwareSetRequest(ctx, updates[idx].ID, payloads[idx]); err != nil {
+				results = append(results, audit.Result{Target: updates[idx].ID.String(), Message: "firmware set labels update failed: " + err.Error()})
+				results = append(results, restoreLabels(ctx, client, updates[:idx])...)
+				mctl.AuditLog(theApp, model.FleetDBAPI, []string{id.String()}, results...)
+
+				log.Fatal(err)
+			}
+
+			results = append(results, audit.Result{
+				Target:  updates[idx].ID.String(),
+				Message: "firmware set labels updated: " + mctl.FormatLabels(updates[idx].NewLabels),
+			})
+		}
+
+		mctl.AuditLog(theApp, model.FleetDBAPI, []string{id.String()}, results...)
+
+		if err := checkPromoted(ctx, client, id, updates[len(updates)-1].NewLabels); err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Println("firmware set promoted: " + id.String())
+
+		if previous := updates[len(updates)-1].NewLabels[fleetdb.FirmwareSetLabelPrevious]; previous != "" {
+			fmt.Printf("previous set: %s, to roll back run 'mctl firmware-set promote --set-id %s'\n", previous, previous)
+		}
+	},
+}
+
+// labelsPayload returns the update request setting the labels of the firmware set.
+func labelsPayload(u *fleetdb.FirmwareSetLabelUpdate, labels map[string]string) (fleetdbapi.ComponentFirmwareSetRequest, error) {
+	attrs, err := mctl.AttributeFromLabels(model.AttributeNSFirmwareSetLabels, labels)
+	if err != nil {
+		return fleetdbapi.ComponentFirmwareSetRequest{}, err
+	}
+
+	return fleetdbapi.ComponentFirmwareSetRequest{
+		ID:                     u.ID,
+		Name:                   u.Name,
+		Attributes:             []fleetdbapi.Attributes{*attrs},
+		ComponentFirmwareUUIDs: []string{},
+	}, nil
+}
+
+// restoreLabels reverts the applied updates when the promotion fails part way.
+func restoreLabels(ctx context.Context, client *fleetdbapi.Client, applied []fleetdb.FirmwareSetLabelUpdate) []audit.Result {
+	results := []audit.Result{}
+
+	for idx := range applied {
+		target := applied[idx].ID.String()
+
+		payload, err := labelsPayload(&applied[idx], applied[idx].OldLabels)
+		if err == nil {
+			_, err = client.UpdateComponentFirmwareSetRequest(ctx, applied[idx].ID, payload)
+		}
+
+		if err != nil {
+			log.Printf("unable to restore the labels of firmware set %s: %s, labels were: %s", target, err.Error(), mctl.FormatLabels(applied[idx].OldLabels))
+			results = append(results, audit.Result{Target: target, Message: "firmware set labels restore failed: " + err.Error()})
+
+			continue
+		}
+
+		results = append(results, audit.Result{Target: target, Message: "firmware set labels restored: " + mctl.FormatLabels(applied[idx].OldLabels)})
+	}
+
+	return results
+}
+
+// checkPromoted verifies the set is the only set for its vendor, model holding the latest and default labels.
+func checkPromoted(ctx context.Context, client *fleetdbapi.Client, id uuid.UUID, labels map[string]string) error {
+	sets, err := mctl.ListFirmwareSets(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	promoted, err := fleetdb.PromotedFirmwareSets(sets, labels["vendor"], labels["model"])
+	if err != nil {
+		return err
+	}
+
+	if len(promoted) == 1 && promoted[0].UUID == id {
+		return nil
+	}
+
+	ids := make([]string, 0, len(promoted))
+	for idx := range promoted {
+		ids = append(ids, promoted[idx].UUID.String())
+	}
+
+	return errors.Wrap(
+		fleetdb.ErrFirmwareSetPromotion,
+		fmt.Sprintf("expected only %s to be labeled latest and default, found: [%s]", id, strings.Join(ids, ", ")),
+	)
+}
+
+func init() {
+	flagsDefinedPromoteFirmwareSet = &promoteFirmwareSetFlags{}
+
+	mctl.AddFirmwareSetFlag(promoteFirmwareSet, &flagsDefinedPromoteFirmwareSet.id)
+	mctl.AddConfirmFlags(promoteFirmwareSet, &flagsDefinedPromoteFirmwareSet.confirm)
+	mctl.AddClientDryRunFlag(promoteFirmwareSet, &flagsDefinedPromoteFirmwareSet.dryRun)
+
+	mctl.RequireFlag(promoteFirmwareSet, mctl.FirmwareSetFlag)
+}
@@ -0,0 +1,207 @@
+package firmware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	fleetdbapi "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	mctl "github.com/metal-toolbox/mctl/cmd"
+	"github.com/metal-toolbox/mctl/internal/app"
+	"github.com/metal-toolbox/mctl/internal/firmware"
+)
+
+type verifyFirmwareFlags struct {
+	firmwareID    string
+	firmwareSetID string
+	headOnly      bool
+	upstream      bool
+}
+
+var (
+	flagsDefinedVerifyFirmware *verifyFirmwareFlags
+)
+
+const (
+	// verifyConcurrency is the number of artifacts verified in parallel.
+	verifyConcurrency = 4
+
+	// artifactTimeout bounds the download of a single artifact.
+	artifactTimeout = 30 * time.Minute
+
+	// maxFirmwarePages limits the number of pages of firmware verified from the catalog.
+	maxFirmwarePages = 50
+)
+
+var verifyFirmware = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify firmware artifacts are available and match their checksum",
+	Long: `Verify the repository artifact of firmware is available and matches the checksum recorded in fleetdb,
+checksums are prefixed with their algorithm - md5sum: or sha256sum:.
+
+The firmware of a firmware set, a single firmware or the whole catalog is verified. With --head-only
+artifacts are checked to exist without downloading them, with --upstream the vendor URLs are checked too.
+
+Firmware with problems are listed and the command exits with a non-zero status.`,
+	Example: `  # verify the firmware of a set
+  mctl firmware verify --set-id 8c0d21a2-6b3d-4b0a-9f0e-6e4b3a1a9a0f -o text
+
+  # check all repository and upstream artifacts exist
+  mctl firmware verify --head-only --upstream -o text`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		theApp := mctl.MustCreateApp(cmd.Context())
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), mctl.CmdTimeout)
+		defer cancel()
+
+		client, err := app.NewFleetDBAPIClient(ctx, theApp.Config.FleetDBAPI, theApp.Reauth)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		firmwares, err := firmwareToVerify(ctx, client, flagsDefinedVerifyFirmware)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		artifacts := []firmware.Artifact{}
+		for idx := range firmwares {
+			artifacts = append(artifacts, firmware.Artifacts(&firmwares[idx], flagsDefinedVerifyFirmware.upstream)...)
+		}
+
+		verifier := &firmware.Verifier{
+			Client:      &http.Client{Timeout: artifactTimeout},
+			HeadOnly:    flagsDefinedVerifyFirmware.headOnly,
+			Concurrency: verifyConcurrency,
+		}
+
+		results := verifier.Verify(cmd.Context(), artifacts)
+
+		if !printVerifyResults(output, results) {
+			os.Exit(1)
+		}
+	},
+}
+
+// firmwareToVerify returns the firmware identified by the flags, or the whole catalog.
+func firmwareToVerify(ctx context.Context, client *fleetdbapi.Client, flgs *verifyFirmwareFlags) ([]fleetdbapi.ComponentFirmwareVersion, error) {
+	switch {
+	case flgs.firmwareID != "":
+		id, err := uuid.Parse(flgs.firmwareID)
+		if err != nil {
+			return nil, err
+		}
+
+		fw, _, err := client.GetServerComponentFirmware(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		return []fleetdbapi.ComponentFirmwareVersion{*fw}, nil
+
+	case flgs.firmwareSetID != "":
+		id, err := uuid.Parse(flgs.firmwareSetID)
+		if err != nil {
+			return nil, err
+		}
+
+		set, _, err := client.GetServerComponentFirmwareSet(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		return set.ComponentFirmware, nil
+	}
+
+	firmwares := []fleetdbapi.ComponentFirmwareVersion{}
+
+	for page := 1; page <= maxFirmwarePages; page++ {
+		params := &fleetdbapi.ComponentFirmwareVersionListParams{
+			Pagination: &fleetdbapi.PaginationParams{
+				Limit: fleetdbapi.MaxPaginationSize,
+				Page:  page,
+			},
+		}
+
+		found, resp, err := client.ListServerComponentFirmware(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+
+		firmwares = append(firmwares, found...)
+
+		if resp == nil || page >= resp.TotalPages {
+			break
+		}
+	}
+
+	return firmwares, nil
+}
+
+// printVerifyResults prints the results and returns true when all artifacts are ok,
+// the text output lists artifacts with problems followed by a summary.
+func printVerifyResults(format string, results []firmware.Result) bool {
+	counts := map[firmware.Status]int{}
+	for idx := range results {
+		counts[results[idx].Status]++
+	}
+
+	ok := counts[firmware.OK] == len(results)
+
+	if format == mctl.OutputTypeJSON.String() {
+		b, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Println(string(b))
+
+		return ok
+	}
+
+	if !ok {
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Firmware", "Source", "Filename", "Status", "Detail"})
+		table.SetAutoWrapText(false)
+
+		for idx := range results {
+			r := &results[idx]
+			if r.Status != firmware.OK {
+				table.Append([]string{r.FirmwareID, r.Source, r.Filename, string(r.Status), r.Detail})
+			}
+		}
+
+		table.Render()
+	}
+
+	summary := tablewriter.NewWriter(os.Stdout)
+	summary.SetHeader([]string{"Status", "Artifacts"})
+
+	for _, status := range []firmware.Status{firmware.OK, firmware.Missing, firmware.Mismatch, firmware.Unreachable, firmware.Invalid} {
+		summary.Append([]string{string(status), strconv.Itoa(counts[status])})
+	}
+
+	summary.Render()
+
+	return ok
+}
+
+func init() {
+	flagsDefinedVerifyFirmware = &verifyFirmwareFlags{}
+
+	mctl.AddFirmwareIDFlag(verifyFirmware, &flagsDefinedVerifyFirmware.firmwareID)
+	mctl.AddFirmwareSetFlag(verifyFirmware, &flagsDefinedVerifyFirmware.firmwareSetID)
+	mctl.AddHeadOnlyFlag(verifyFirmware, &flagsDefinedVerifyFirmware.headOnly)
+	mctl.AddUpstreamFlag(verifyFirmware, &flagsDefinedVerifyFirmware.upstream)
+
+	mctl.MutuallyExclusiveFlags(verifyFirmware, mctl.FirmwareIDFlag, mctl.FirmwareSetFlag)
+}
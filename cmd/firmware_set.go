@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	fleetdbapi "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+
+	"github.com/metal-toolbox/mctl/internal/fleetdb"
+)
+
+// FirmwareSetByVendorModel returns the firmware set for the vendor, model with the given labels - 'channel=canary',
+// without labels the set labeled default and latest is returned.
+//
+// When more than one set matches the user selects the set in an interactive terminal,
+// otherwise an error listing the sets is returned.
+func FirmwareSetByVendorModel(
+	ctx context.Context,
+	client *fleetdbapi.Client,
+	vendor, model string,
+	labels map[string]string,
+) (*fleetdbapi.ComponentFirmwareSet, error) {
+	params := &fleetdbapi.ComponentFirmwareSetListParams{
+		Vendor: strings.TrimSpace(vendor),
+		Model:  strings.TrimSpace(model),
+		Labels: firmwareSetLabelFilter(labels),
+	}
+
+	// identify firmware set by vendor, model attributes
+	fwSet, _, err := client.ListServerComponentFirmwareSet(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	set, err := selectFirmwareSet(os.Stdin, os.Stderr, stdinIsTerminal(), fwSet)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("vendor: %s, model: %s, labels: %s", vendor, model, params.Labels))
+	}
+
+	log.Printf(
+		"fw sets identified for vendor: %s, model: %s, fwset: %s\n",
+		vendor,
+		model,
+		set.UUID.String(),
+	)
+
+	return set, nil
+}
+
+// firmwareSetLabelFilter returns the list filter for the labels, the default, latest labels when none are given.
+// Only the promoted set of a vendor, model has the default, latest labels, so they're not combined with the labels.
+func firmwareSetLabelFilter(labels map[string]string) string {
+	if len(labels) > 0 {
+		return FormatLabels(labels)
+	}
+
+	return FormatLabels(map[string]string{
+		fleetdb.FirmwareSetLabelDefault: "true",
+		fleetdb.FirmwareSetLabelLatest:  "true",
+	})
+}
+
+// FormatLabels returns the labels sorted by key in the --labels flag format - 'model=r6515,vendor=dell'.
+func FormatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
+// selectFirmwareSet returns the only set, or the set the user selects when interactive.
+func selectFirmwareSet(in io.Reader, out io.Writer, interactive bool, sets []fleetdbapi.ComponentFirmwareSet) (*fleetdbapi.ComponentFirmwareSet, error) {
+	switch {
+	case len(sets) == 0:
+		return nil, errors.Wrap(ErrFwSetByVendorModel, "no firmware set matched")
+	case len(sets) == 1:
+		return &sets[0], nil
+	}
+
+	ids := make([]string, 0, len(sets))
+	for idx := range sets {
+		ids = append(ids, sets[idx].UUID.String())
+	}
+
+	if !interactive {
+		return nil, errors.Wrap(
+			ErrFwSetByVendorModel,
+			fmt.Sprintf(
+				"sets %s match, select one with --labels or promote one with 'mctl firmware-set promote'",
+				strings.Join(ids, ", "),
+			),
+		)
+	}
+
+	fmt.Fprintf(out, "%d firmware sets match:\n", len(sets))
+
+	table := tablewriter.NewWriter(out)
+	table.SetHeader([]string{"#", "ID", "Name", "Labels"})
+	table.SetAutoWrapText(false)
+
+	for idx := range sets {
+		labels, _ := fleetdb.FirmwareSetLabels(&sets[idx])
+		table.Append([]string{strconv.Itoa(idx + 1), ids[idx], sets[idx].Name, FormatLabels(labels)})
+	}
+
+	table.Render()
+
+	fmt.Fprintf(out, "Select a firmware set [1-%d]: ", len(sets))
+
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, errors.Wrap(ErrFwSetByVendorModel, err.Error())
+	}
+
+	selected, err := strconv.Atoi(strings.TrimSpace(answer))
+	if err != nil || selected < 1 || selected > len(sets) {
+		return nil, errors.Wrap(ErrFwSetByVendorModel, "invalid selection: "+strings.TrimSpace(answer))
+	}
+
+	return &sets[selected-1], nil
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	fleetdbapi "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFirmwareSetLabelFilter(t *testing.T) {
+	assert.Equal(t, "default=true,latest=true", firmwareSetLabelFilter(nil))
+	assert.Equal(t, "channel=canary", firmwareSetLabelFilter(map[string]string{"channel": "canary"}))
+	assert.Equal(t, "channel=canary,latest=true", firmwareSetLabelFilter(map[string]string{"latest": "true", "channel": "canary"}))
+}
+
+func TestSelectFirmwareSet(t *testing.T) {
+	sets := []fleetdbapi.ComponentFirmwareSet{
+		{UUID: uuid.New(), Name: "r6515-stable"},
+		{UUID: uuid.New(), Name: "r6515-canary"},
+	}
+
+	testcases := []struct {
+		name        string
+		sets        []fleetdbapi.ComponentFirmwareSet
+		input       string
+		interactive bool
+		want        string
+		wantErr     string
+	}{
+		{
+			name:    "no match",
+			wantErr: "no firmware set matched",
+		},
+		{
+			name: "single match",
+			sets: sets[:1],
+			want: "r6515-stable",
+		},
+		{
+			name:    "several matches non-interactive",
+			sets:    sets,
+			wantErr: "select one with --labels",
+		},
+		{
+			name:        "several matches selected",
+			sets:        sets,
+			input:       "2\n",
+			interactive: true,
+			want:        "r6515-canary",
+		},
+		{
+			name:        "invalid selection",
+			sets:        sets,
+			input:       "3\n",
+			interactive: true,
+			wantErr:     "invalid selection: 3",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+
+			got, err := selectFirmwareSet(strings.NewReader(tc.input), out, tc.interactive, tc.sets)
+			if tc.wantErr != "" {
+				require.ErrorIs(t, err, ErrFwSetByVendorModel)
+				assert.Contains(t, err.Error(), tc.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got.Name)
+
+			if tc.interactive {
+				assert.Contains(t, out.String(), "Select a firmware set [1-2]")
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	fleetdbapi "github.com/metal-toolbox/fleetdb/pkg/api/v1"
 	"github.com/spf13/cobra"
@@ -62,6 +63,48 @@ var (
 	ComponentTypeFlag                 = &flagDetails{name: "component"}
 	BIOSConfigURLFlag                 = &flagDetails{name: "bios-config-url"}
 	BIOSConfigSetIDFlag               = &flagDetails{name: "bios-config-set-id", short: "i"}
+	BootDeviceFlag                    = &flagDetails{name: "boot-device"}
+	BootPersistentFlag                = &flagDetails{name: "boot-persistent"}
+	BootModeFlag                      = &flagDetails{name: "boot-mode"}
+	YesFlag                           = &flagDetails{name: "yes", short: "y"}
+	AllowBulkFlag                     = &flagDetails{name: "allow-bulk"}
+	SinceFlag                         = &flagDetails{name: "since"}
+	SinceFileFlag                     = &flagDetails{name: "since-file"}
+	UserFlag                          = &flagDetails{name: "user"}
+	SubjectFlag                       = &flagDetails{name: "subject"}
+	CommandFlag                       = &flagDetails{name: "command"}
+	SaveFlag                          = &flagDetails{name: "save"}
+	AgainstFlag                       = &flagDetails{name: "against"}
+	SettingFlag                       = &flagDetails{name: "setting"}
+	RemoveSettingFlag                 = &flagDetails{name: "remove-setting"}
+	ModelsFlag                        = &flagDetails{name: "models"}
+	FilenameFlag                      = &flagDetails{name: "filename"}
+	ChecksumFlag                      = &flagDetails{name: "checksum"}
+	UpstreamURLFlag                   = &flagDetails{name: "upstream-url"}
+	RepositoryURLFlag                 = &flagDetails{name: "repository-url"}
+	InstallInbandFlag                 = &flagDetails{name: "install-inband"}
+	OEMFlag                           = &flagDetails{name: "oem"}
+	HeadOnlyFlag                      = &flagDetails{name: "head-only"}
+	UpstreamFlag                      = &flagDetails{name: "upstream"}
+	CreateFlag                        = &flagDetails{name: "create"}
+	OfflineFlag                       = &flagDetails{name: "offline"}
+	ReplaceLabelsFlag                 = &flagDetails{name: "replace-labels"}
+	RemoveLabelsFlag                  = &flagDetails{name: "remove-labels"}
+	ReplaceComponentFlag              = &flagDetails{name: "replace-component"}
+	WavesFlag                         = &flagDetails{name: "waves"}
+	MaxFailureRateFlag                = &flagDetails{name: "max-failure-rate"}
+	PollIntervalFlag                  = &flagDetails{name: "poll-interval"}
+	WaveTimeoutFlag                   = &flagDetails{name: "wave-timeout"}
+	ResumeFlag                        = &flagDetails{name: "resume"}
+	PlanFlag                          = &flagDetails{name: "plan"}
+	AtFlag                            = &flagDetails{name: "at"}
+	WindowFlag                        = &flagDetails{name: "window"}
+	JobIDFlag                         = &flagDetails{name: "job-id"}
+	OnceFlag                          = &flagDetails{name: "once"}
+	BMCPasswordEnvFlag                = &flagDetails{name: "bmc-pass-env"}
+	BMCPasswordFileFlag               = &flagDetails{name: "bmc-pass-file"}
+	ResultsFileFlag                   = &flagDetails{name: "results-file"}
+	ConcurrencyFlag                   = &flagDetails{name: "concurrency"}
 
 	OutputTypeJSON outputType = "json"
 	OutputTypeText outputType = "text"
@@ -137,6 +180,23 @@ func RequireOneFlag(cmd *cobra.Command, flags ...*flagDetails) {
 	cmd.MarkFlagsOneRequired(flagNames...)
 }
 
+func RequireFlagsTogether(cmd *cobra.Command, flags ...*flagDetails) {
+	flagNames := make([]string, len(flags))
+
+	for i, f := range flags {
+		flagNames[i] = f.name
+	}
+
+	cmd.MarkFlagsRequiredTogether(flagNames...)
+}
+
+// ExclusiveWithFlag marks each of the others mutually exclusive with the flag.
+func ExclusiveWithFlag(cmd *cobra.Command, flag *flagDetails, others ...*flagDetails) {
+	for _, other := range others {
+		MutuallyExclusiveFlags(cmd, flag, other)
+	}
+}
+
 func AddConfigFileFlag(cmd *cobra.Command, ptr *string) {
 	cmd.PersistentFlags().StringVarP(ptr, ConfigFileFlag.name, ConfigFileFlag.short, "",
 		"config file (default is $XDG_CONFIG_HOME/mctl/config.yml)")
@@ -146,8 +206,16 @@ func AddReAuthFlag(cmd *cobra.Command, ptr *bool) {
 	cmd.PersistentFlags().BoolVar(ptr, ReAuthFlag.name, false, "re-authenticate with oauth services")
 }
 
+// AddServerFlag adds the --server flag, commands resolve its value with ResolveServerID.
 func AddServerFlag(cmd *cobra.Command, ptr *string) {
-	cmd.PersistentFlags().StringVarP(ptr, ServerFlag.name, ServerFlag.short, "", "ID of the server")
+	cmd.PersistentFlags().StringVarP(ptr, ServerFlag.name, ServerFlag.short, "",
+		"ID, hostname, serial, BMC address or BMC/AOC MAC address of the server")
+	registerCompletion(cmd, ServerFlag, fleetDBCompletion(completeServers))
+}
+
+// AddServerIDFlag adds the --server flag for commands that only accept a server ID.
+func AddServerIDFlag(cmd *cobra.Command, ptr *string, usage string) {
+	cmd.PersistentFlags().StringVarP(ptr, ServerFlag.name, ServerFlag.short, "", usage)
 }
 
 func AddSkipFWStatusFlag(cmd *cobra.Command, ptr *bool) {
@@ -189,11 +257,21 @@ func AddBMCUsernameFlag(cmd *cobra.Command, ptr *string) {
 }
 
 func AddBMCPasswordFlag(cmd *cobra.Command, ptr *string) {
-	cmd.PersistentFlags().StringVarP(ptr, BMCPasswordFlag.name, BMCPasswordFlag.short, "", "password of the bmc user")
+	cmd.PersistentFlags().StringVarP(ptr, BMCPasswordFlag.name, BMCPasswordFlag.short, "",
+		"password of the bmc user, the password is kept in the shell history - prefer --bmc-pass-env or --bmc-pass-file")
+}
+
+func AddBMCPasswordEnvFlag(cmd *cobra.Command, ptr *string) {
+	cmd.PersistentFlags().StringVar(ptr, BMCPasswordEnvFlag.name, "", "name of the environment variable with the password of the bmc user")
+}
+
+func AddBMCPasswordFileFlag(cmd *cobra.Command, ptr *string) {
+	cmd.PersistentFlags().StringVar(ptr, BMCPasswordFileFlag.name, "", "file with the password of the bmc user, '-' reads it from stdin")
 }
 
 func AddFirmwareIDFlag(cmd *cobra.Command, ptr *string) {
 	cmd.PersistentFlags().StringVarP(ptr, FirmwareIDFlag.name, FirmwareIDFlag.short, "", "ID of the firmware")
+	registerCompletion(cmd, FirmwareIDFlag, fleetDBCompletion(completeFirmware))
 }
 
 func AddFirmwareVersionFlag(cmd *cobra.Command, ptr *string) {
@@ -202,6 +280,7 @@ func AddFirmwareVersionFlag(cmd *cobra.Command, ptr *string) {
 
 func AddFirmwareSetFlag(cmd *cobra.Command, ptr *string) {
 	cmd.PersistentFlags().StringVar(ptr, FirmwareSetFlag.name, "", "ID of the firmware set")
+	registerCompletion(cmd, FirmwareSetFlag, fleetDBCompletion(completeFirmwareSets))
 }
 
 func AddFirmwareAddIDsFlag(cmd *cobra.Command, ptr *[]string) {
@@ -214,6 +293,20 @@ func AddFirmwareRemoveIDsFlag(cmd *cobra.Command, ptr *[]string) {
 	cmd.PersistentFlags().StringSliceVar(ptr, FirmwareRemoveFlag.name, []string{}, usage)
 }
 
+func AddReplaceLabelsFlag(cmd *cobra.Command, ptr *bool) {
+	cmd.PersistentFlags().BoolVar(ptr, ReplaceLabelsFlag.name, false, "replace all labels with --labels instead of merging them")
+}
+
+func AddRemoveLabelsFlag(cmd *cobra.Command, ptr *[]string) {
+	cmd.PersistentFlags().StringSliceVar(ptr, RemoveLabelsFlag.name, []string{}, "comma separated list of label keys to remove")
+}
+
+//nolint:gocritic // ptrToRefParam we need the pointer map argument
+func AddReplaceComponentFlag(cmd *cobra.Command, ptr *map[string]string) {
+	usage := "replace the firmware of a component with the firmware ID - 'bios=<firmware ID>,bmc=<firmware ID>'"
+	cmd.PersistentFlags().StringToStringVar(ptr, ReplaceComponentFlag.name, nil, usage)
+}
+
 func AddMacAOCFlag(cmd *cobra.Command, ptr *string) {
 	cmd.PersistentFlags().StringVar(ptr, MacAOCFlag.name, "", "aoc mac address")
 }
@@ -236,6 +329,11 @@ func AddDryRunFlag(cmd *cobra.Command, ptr *bool, usage string) {
 	cmd.PersistentFlags().BoolVar(ptr, DryRunFlag.name, false, usage)
 }
 
+// AddClientDryRunFlag adds the --dry-run flag for commands that print the API requests instead of sending them.
+func AddClientDryRunFlag(cmd *cobra.Command, ptr *bool) {
+	AddDryRunFlag(cmd, ptr, "print the API requests instead of sending them")
+}
+
 func AddSkipBmcResetFlag(cmd *cobra.Command, ptr *bool) {
 	cmd.PersistentFlags().BoolVar(ptr, SkipBmcResetFlag.name, false, "skip BMC reset before firmware install")
 }
@@ -246,14 +344,17 @@ func AddPowerOffRequiredFlag(cmd *cobra.Command, ptr *bool, usage string) {
 
 func AddModelFlag(cmd *cobra.Command, ptr *string) {
 	cmd.PersistentFlags().StringVarP(ptr, ModelFlag.name, ModelFlag.short, "", "filter by model")
+	registerCompletion(cmd, ModelFlag, fleetDBCompletion(completeModels))
 }
 
 func AddVendorFlag(cmd *cobra.Command, ptr *string) {
 	cmd.PersistentFlags().StringVarP(ptr, VendorFlag.name, VendorFlag.short, "", "filter by vendor")
+	registerCompletion(cmd, VendorFlag, fleetDBCompletion(completeVendors))
 }
 
 func AddSlugFlag(cmd *cobra.Command, ptr *string, usage string) {
 	cmd.PersistentFlags().StringVar(ptr, SlugFlag.name, "", usage)
+	registerCompletion(cmd, SlugFlag, completeSlugs)
 }
 
 func AddWithRecordsFlag(cmd *cobra.Command, ptr *bool) {
@@ -302,6 +403,7 @@ func AddServerSerialFlag(cmd *cobra.Command, ptr *string) {
 
 func AddBIOSConfigSetIDFlag(cmd *cobra.Command, ptr *string) {
 	cmd.PersistentFlags().StringVarP(ptr, BIOSConfigSetIDFlag.name, BIOSConfigSetIDFlag.short, "", "specify ID of Bios Config Set")
+	registerCompletion(cmd, BIOSConfigSetIDFlag, fleetDBCompletion(completeBIOSConfigSets))
 }
 
 func AddServerPowerActionFlag(cmd *cobra.Command, ptr *string, params []string) {
@@ -330,3 +432,197 @@ func AddBIOSConfigURLFlag(cmd *cobra.Command, ptr *string) {
 		"raw.githubusercontent.com path to bios config",
 	)
 }
+
+func AddBootDeviceFlag(cmd *cobra.Command, ptr *string, devices []string) {
+	cmd.PersistentFlags().StringVar(
+		ptr,
+		BootDeviceFlag.name,
+		"",
+		fmt.Sprintf("set the next boot device [%s]", strings.Join(devices, "|")),
+	)
+}
+
+func AddBootPersistentFlag(cmd *cobra.Command, ptr *bool) {
+	cmd.PersistentFlags().BoolVar(
+		ptr,
+		BootPersistentFlag.name,
+		false,
+		"persist the next boot device across reboots (default is a one-time boot)",
+	)
+}
+
+func AddBootModeFlag(cmd *cobra.Command, ptr *string, modes []string) {
+	cmd.PersistentFlags().StringVar(
+		ptr,
+		BootModeFlag.name,
+		"",
+		fmt.Sprintf("boot mode for the next boot device [%s] (default efi)", strings.Join(modes, "|")),
+	)
+}
+
+func AddYesFlag(cmd *cobra.Command, ptr *bool) {
+	cmd.PersistentFlags().BoolVarP(ptr, YesFlag.name, YesFlag.short, false, "skip the interactive confirmation prompt")
+}
+
+func AddAllowBulkFlag(cmd *cobra.Command, ptr *bool) {
+	cmd.PersistentFlags().BoolVar(ptr, AllowBulkFlag.name, false,
+		"allow acting on more objects than the configured destructive_threshold")
+}
+
+// AddConfirmFlags adds the flags to confirm a destructive operation.
+func AddConfirmFlags(cmd *cobra.Command, ptr *ConfirmParams) {
+	AddYesFlag(cmd, &ptr.Yes)
+	AddAllowBulkFlag(cmd, &ptr.AllowBulk)
+}
+
+func AddSinceFlag(cmd *cobra.Command, ptr *string, usage string) {
+	cmd.PersistentFlags().StringVar(ptr, SinceFlag.name, "", usage)
+}
+
+func AddUserFlag(cmd *cobra.Command, ptr *string, usage string) {
+	cmd.PersistentFlags().StringVar(ptr, UserFlag.name, "", usage)
+}
+
+func AddSubjectFlag(cmd *cobra.Command, ptr *string) {
+	cmd.PersistentFlags().StringVar(ptr, SubjectFlag.name, "", "filter by OAuth token subject")
+}
+
+func AddCommandFlag(cmd *cobra.Command, ptr *string, usage string) {
+	cmd.PersistentFlags().StringVar(ptr, CommandFlag.name, "", usage)
+}
+
+func AddLimitFlag(cmd *cobra.Command, ptr *int, usage string) {
+	cmd.PersistentFlags().IntVar(ptr, LimitFlag.name, 0, usage)
+}
+
+func AddSinceFileFlag(cmd *cobra.Command, ptr *string, usage string) {
+	cmd.PersistentFlags().StringVar(ptr, SinceFileFlag.name, "", usage)
+}
+
+func AddSaveFlag(cmd *cobra.Command, ptr *string, usage string) {
+	cmd.PersistentFlags().StringVar(ptr, SaveFlag.name, "", usage)
+}
+
+func AddAgainstFlag(cmd *cobra.Command, ptr *string) {
+	cmd.PersistentFlags().StringVar(ptr, AgainstFlag.name, "", "ID, hostname, serial, BMC address or BMC/AOC MAC address of the server to compare against")
+	registerCompletion(cmd, AgainstFlag, fleetDBCompletion(completeServers))
+}
+
+//nolint:gocritic // ptrToRefParam we need the pointer map argument
+func AddSettingFlag(cmd *cobra.Command, ptr *map[string]string) {
+	cmd.PersistentFlags().StringToStringVar(ptr, SettingFlag.name, nil, "BIOS settings to set - 'BootMode=Uefi,SriovGlobalEnable=Enabled'")
+}
+
+func AddRemoveSettingFlag(cmd *cobra.Command, ptr *[]string) {
+	cmd.PersistentFlags().StringSliceVar(ptr, RemoveSettingFlag.name, []string{}, "comma separated list of BIOS settings to remove")
+}
+
+func AddHeadOnlyFlag(cmd *cobra.Command, ptr *bool) {
+	cmd.PersistentFlags().BoolVar(ptr, HeadOnlyFlag.name, false, "check artifacts exist without downloading them, checksums are not verified")
+}
+
+func AddUpstreamFlag(cmd *cobra.Command, ptr *bool) {
+	cmd.PersistentFlags().BoolVar(ptr, UpstreamFlag.name, false, "check the upstream vendor URLs exist too")
+}
+
+func AddCreateFlag(cmd *cobra.Command, ptr *bool, usage string) {
+	cmd.PersistentFlags().BoolVar(ptr, CreateFlag.name, false, usage)
+}
+
+func AddOfflineFlag(cmd *cobra.Command, ptr *bool, usage string) {
+	cmd.PersistentFlags().BoolVar(ptr, OfflineFlag.name, false, usage)
+}
+
+func AddWavesFlag(cmd *cobra.Command, ptr *[]string) {
+	cmd.PersistentFlags().StringSliceVar(ptr, WavesFlag.name, []string{"1", "5%", "25%", "rest"},
+		"comma separated wave sizes, a number of servers, a percentage of the servers or 'rest' for the last wave")
+}
+
+func AddMaxFailureRateFlag(cmd *cobra.Command, ptr *float64) {
+	cmd.PersistentFlags().Float64Var(ptr, MaxFailureRateFlag.name, 10, "percentage of failed installs in a wave that halts the rollout")
+}
+
+func AddPollIntervalFlag(cmd *cobra.Command, ptr *time.Duration, usage string) {
+	cmd.PersistentFlags().DurationVar(ptr, PollIntervalFlag.name, 30*time.Second, usage)
+}
+
+func AddWaveTimeoutFlag(cmd *cobra.Command, ptr *time.Duration) {
+	cmd.PersistentFlags().DurationVar(ptr, WaveTimeoutFlag.name, 2*time.Hour, "time to wait for the installs of a wave to complete before halting")
+}
+
+func AddResumeFlag(cmd *cobra.Command, ptr *string) {
+	cmd.PersistentFlags().StringVar(ptr, ResumeFlag.name, "", "ID of an interrupted or halted rollout to resume")
+}
+
+func AddPlanFlag(cmd *cobra.Command, ptr *bool, usage string) {
+	cmd.PersistentFlags().BoolVar(ptr, PlanFlag.name, false, usage)
+}
+
+// AddScheduleFlags adds the flags to submit a condition at a later time.
+func AddScheduleFlags(cmd *cobra.Command, ptr *ScheduleParams) {
+	cmd.PersistentFlags().StringVar(&ptr.At, AtFlag.name, "",
+		"schedule the condition for 'mctl scheduler run' to submit at a time - '2h', '22:30', '2006-01-02 22:30' or RFC3339")
+	cmd.PersistentFlags().DurationVar(&ptr.Window, WindowFlag.name, 0,
+		"time after --at the scheduled condition may still be submitted, the job expires after the window")
+}
+
+func AddJobIDFlag(cmd *cobra.Command, ptr *string) {
+	cmd.PersistentFlags().StringVar(ptr, JobIDFlag.name, "", "ID of the scheduled job")
+}
+
+func AddOnceFlag(cmd *cobra.Command, ptr *bool, usage string) {
+	cmd.PersistentFlags().BoolVar(ptr, OnceFlag.name, false, usage)
+}
+
+func AddResultsFileFlag(cmd *cobra.Command, ptr *string, usage string) {
+	cmd.PersistentFlags().StringVar(ptr, ResultsFileFlag.name, "", usage)
+}
+
+func AddConcurrencyFlag(cmd *cobra.Command, ptr *int, value int, usage string) {
+	cmd.PersistentFlags().IntVar(ptr, ConcurrencyFlag.name, value, usage)
+}
+
+func AddRepositoryURLFlag(cmd *cobra.Command, ptr *string, usage string) {
+	cmd.PersistentFlags().StringVar(ptr, RepositoryURLFlag.name, "", usage)
+}
+
+func AddFilenameFlag(cmd *cobra.Command, ptr *string, usage string) {
+	cmd.PersistentFlags().StringVar(ptr, FilenameFlag.name, "", usage)
+}
+
+func AddChecksumFlag(cmd *cobra.Command, ptr *string, usage string) {
+	cmd.PersistentFlags().StringVar(ptr, ChecksumFlag.name, "", usage)
+}
+
+// AddFirmwareFieldFlags adds a flag for each firmware field, FirmwareFieldFlags lists the flags
+// to identify the fields that were set.
+func AddFirmwareFieldFlags(cmd *cobra.Command, f *FirmwareFields) {
+	flags := cmd.PersistentFlags()
+
+	flags.StringVarP(&f.Vendor, VendorFlag.name, VendorFlag.short, "", "firmware vendor")
+	flags.StringSliceVar(&f.Models, ModelsFlag.name, []string{}, "comma separated list of models the firmware applies to")
+	flags.StringVar(&f.Component, ComponentTypeFlag.name, "", "the component type the firmware applies to (bmc|bios|nic..)")
+	flags.StringVarP(&f.Version, FirmwareVersionFlag.name, FirmwareVersionFlag.short, "", "firmware version")
+	AddFilenameFlag(cmd, &f.Filename, "firmware file name")
+	AddChecksumFlag(cmd, &f.Checksum, "firmware file checksum")
+	flags.StringVar(&f.UpstreamURL, UpstreamURLFlag.name, "", "vendor URL of the firmware file")
+	flags.StringVar(&f.RepositoryURL, RepositoryURLFlag.name, "", "firmware repository URL of the firmware file")
+	flags.BoolVar(&f.InstallInband, InstallInbandFlag.name, false, "firmware is installed inband")
+	flags.BoolVar(&f.OEM, OEMFlag.name, false, "firmware is an OEM build")
+
+	registerCompletion(cmd, VendorFlag, fleetDBCompletion(completeVendors))
+}
+
+// FirmwareFieldFlags are the names of the flags added by AddFirmwareFieldFlags
+var FirmwareFieldFlags = []*flagDetails{
+	VendorFlag,
+	ModelsFlag,
+	ComponentTypeFlag,
+	FirmwareVersionFlag,
+	FilenameFlag,
+	ChecksumFlag,
+	UpstreamURLFlag,
+	RepositoryURLFlag,
+	InstallInbandFlag,
+	OEMFlag,
+}
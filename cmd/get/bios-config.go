@@ -12,6 +12,7 @@ import (
 
 	mctl "github.com/metal-toolbox/mctl/cmd"
 	"github.com/metal-toolbox/mctl/internal/app"
+	"github.com/metal-toolbox/mctl/internal/fleetdb"
 )
 
 type getBiosConfigFlags struct {
@@ -37,7 +38,7 @@ var getBiosConfig = &cobra.Command{
 			log.Fatal(err)
 		}
 
-		serverID, err := uuid.Parse(flagsDefinedGetBiosConfig.serverID)
+		serverID, err := fleetdb.ResolveServer(cmd.Context(), client, flagsDefinedGetBiosConfig.serverID)
 		if err != nil {
 			log.Fatal(err)
 		}
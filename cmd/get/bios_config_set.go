@@ -0,0 +1,78 @@
+package get
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/google/uuid"
+	fleetdbapi "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	mctl "github.com/metal-toolbox/mctl/cmd"
+	"github.com/metal-toolbox/mctl/internal/app"
+)
+
+type getBiosConfigSetFlags struct {
+	id string
+}
+
+var (
+	flagsDefinedGetBiosConfigSet *getBiosConfigSetFlags
+)
+
+// Get BIOS config set
+var getBiosConfigSet = &cobra.Command{
+	Use:   "bios-config-set",
+	Short: "Get a bios config set",
+	Run: func(cmd *cobra.Command, _ []string) {
+		id, err := uuid.Parse(flagsDefinedGetBiosConfigSet.id)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		theApp := mctl.MustCreateApp(cmd.Context())
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), mctl.CmdTimeout)
+		defer cancel()
+
+		client, err := app.NewFleetDBAPIClient(ctx, theApp.Config.FleetDBAPI, theApp.Reauth)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		set, err := mctl.BIOSConfigSetByID(ctx, client, id)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if output == mctl.OutputTypeText.String() {
+			renderBiosConfigSetTable(set)
+			return
+		}
+
+		mctl.PrintResults(output, set)
+	},
+}
+
+func renderBiosConfigSetTable(set *fleetdbapi.BiosConfigSet) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Name", "Version", "Component", "Vendor", "Model", "Setting", "Value"})
+	table.SetAutoMergeCells(true)
+
+	for _, component := range set.Components {
+		for _, setting := range component.Settings {
+			table.Append([]string{set.Name, set.Version, component.Name, component.Vendor, component.Model, setting.Key, setting.Value})
+		}
+	}
+
+	table.Render()
+}
+
+func init() {
+	flagsDefinedGetBiosConfigSet = &getBiosConfigSetFlags{}
+
+	mctl.AddBIOSConfigSetIDFlag(getBiosConfigSet, &flagsDefinedGetBiosConfigSet.id)
+	mctl.RequireFlag(getBiosConfigSet, mctl.BIOSConfigSetIDFlag)
+}
@@ -10,75 +10,11 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/bmc-toolbox/common"
-	"github.com/go-playground/validator"
-	"github.com/metal-toolbox/rivets/v2/types"
+	common "github.com/metal-toolbox/bmc-common"
+	rt "github.com/metal-toolbox/rivets/v2/types"
 	"go.equinixmetal.net/staff"
 )
 
-type commonComponents struct {
-	Chassis chassis `json:"chassis" validate:"required"`
-}
-
-type chassis struct {
-	BMC         BMC         `json:"bmc" validate:"required"`
-	Motherboard Motherboard `json:"motherboard" validate:"required"`
-	CPU         []CPU       `json:"cpu" validate:"required"`
-	Memory      []Memory    `json:"memory" validate:"required"`
-	Disk        []Disk      `json:"disk" validate:"required"`
-	NIC         []NIC       `json:"nic" validate:"required"`
-}
-
-type BMC struct {
-	Manufacturer string   `json:"manufacturer" validate:"required"`
-	Model        string   `json:"model" validate:"required"`
-	Firmware     Firmware `json:"firmware" validate:"required"`
-}
-
-type Motherboard struct {
-	Manufacturer string   `json:"manufacturer" validate:"required"`
-	Model        string   `json:"model" validate:"required"`
-	Firmware     Firmware `json:"firmware" validate:"required"`
-	Serial       string   `json:"serial"`
-}
-
-type CPU struct {
-	Manufacturer string   `json:"manufacturer" validate:"required"`
-	Model        string   `json:"model" validate:"required"`
-	Cores        int      `json:"cores" validate:"required"`
-	ClockSpeed   int64    `json:"clock_speed" validate:"required"`
-	Firmware     Firmware `json:"firmware" validate:"required"`
-}
-
-type Memory struct {
-	Manufacturer string   `json:"manufacturer" validate:"required"`
-	Model        string   `json:"model" validate:"required"`
-	Capacity     int64    `json:"capacity" validate:"required"`
-	Speed        int64    `json:"speed" validate:"required"`
-	Firmware     Firmware `json:"firmware" validate:"required"`
-	Serial       string   `json:"serial"`
-}
-
-type Disk struct {
-	Manufacturer string   `json:"manufacturer" validate:"required"`
-	Model        string   `json:"model" validate:"required"`
-	Capacity     int64    `json:"capacity" validate:"required"`
-	Firmware     Firmware `json:"firmware" validate:"required"`
-	Serial       string   `json:"serial"`
-}
-
-type NIC struct {
-	Manufacturer string   `json:"manufacturer" validate:"required"`
-	Model        string   `json:"model" validate:"required"`
-	Speed        int64    `json:"speed" validate:"required"`
-	Firmware     Firmware `json:"firmware" validate:"required"`
-	MACs         []string `json:"macs" validate:"required"`
-}
-
-type Firmware struct {
-	Version string `json:"version" validate:"required"`
-}
-
 const (
 	BMCType         = "ManagementControllerComponent"
 	MotherboardType = "MotherboardComponent"
@@ -86,174 +22,91 @@ const (
 	MemoryType      = "MemoryComponent"
 	DiskType        = "DiskComponent"
 	NICType         = "NetworkComponent"
-
-	NoFirmware = "N/A"
 )
 
-func convertFleetDBServer(server *types.Server) (*commonComponents, error) {
-	commonData := &commonComponents{}
-
-	for _, component := range server.Components {
-		firmware := Firmware{}
-		if component.Firmware != nil {
-			firmware = Firmware{component.Firmware.Installed}
-		}
-		attribute := component.Attributes
-		if attribute == nil {
-			attribute = &types.ComponentAttributes{}
-		}
-
-		switch component.Name {
-		case common.SlugPhysicalMem:
-			commonData.Chassis.Memory = append(commonData.Chassis.Memory, Memory{
-				Manufacturer: strings.ToLower(component.Vendor),
-				Model:        attribute.PartNumber,
-				Speed:        attribute.ClockSpeedHz,
-				Capacity:     attribute.SizeBytes,
-				Firmware:     firmware,
-				Serial:       component.Serial,
-			})
-
-		case common.SlugCPU:
-			commonData.Chassis.CPU = append(commonData.Chassis.CPU, CPU{
-				Manufacturer: strings.ToLower(component.Vendor),
-				Model:        component.Model,
-				Cores:        attribute.Cores,
-				ClockSpeed:   attribute.ClockSpeedHz,
-				Firmware:     firmware,
-			})
-
-		case common.SlugNIC:
-			commonData.Chassis.NIC = append(commonData.Chassis.NIC, NIC{
-				Manufacturer: strings.ToLower(component.Vendor),
-				Model:        component.Model,
-				// TO BE FILLED. It's is inside the `data`(json) in attribute where GetInventory hasn't parsed
-				// or copied to the response.
-				Speed:    -1,
-				Firmware: firmware,
-				// TO BE FILLED. It's is inside the `data`(json) in attribute where GetInventory hasn't parsed
-				// or copied to the response.
-				MACs: []string{},
-			})
-
-		case common.SlugBMC:
-			commonData.Chassis.BMC = BMC{
-				Manufacturer: strings.ToLower(component.Vendor),
-				Model:        component.Model,
-				Firmware:     firmware,
-			}
-
-		case common.SlugDrive:
-			var bytes int64 = attribute.CapacityBytes
+// convertEMAPIServer converts the EMAPI hardware components to a rivets Server
+// with the component slugs and attributes fleetdb records for them.
+func convertEMAPIServer(comps []staff.Component) (*rt.Server, error) {
+	server := &rt.Server{}
 
-			commonData.Chassis.Disk = append(commonData.Chassis.Disk, Disk{
-				Manufacturer: strings.ToLower(component.Vendor),
-				Model:        component.Model,
-				// Sometimes GetInventory will drop this stat while it is in the database.
-				Capacity: bytes,
-				Firmware: firmware,
-				Serial:   component.Serial,
-			})
-
-		case common.SlugBIOS:
-			commonData.Chassis.Motherboard = Motherboard{
-				Manufacturer: strings.ToLower(component.Vendor),
-				Model:        component.Model,
-				Firmware:     firmware,
-				Serial:       component.Serial,
-			}
+	for _, component := range comps {
+		c := &rt.Component{
+			Vendor:     component.Vendor,
+			Model:      component.Model,
+			Serial:     component.Serial,
+			Firmware:   &common.Firmware{Installed: component.FirmwareVersion},
+			Attributes: &rt.ComponentAttributes{},
 		}
-	}
-
-	validate := validator.New()
-	err := validate.Struct(commonData)
-	if err != nil {
-		fmt.Printf("[ERROR] EMAPI is missing required fields: %v\n", err)
-	}
 
-	return commonData, nil
-}
-
-func convertEMAPIServer(comps []staff.Component) (*commonComponents, error) {
-	commonData := &commonComponents{}
-
-	for _, component := range comps {
-		firmware := Firmware{component.FirmwareVersion}
 		switch component.Type {
 		case BMCType:
-			commonData.Chassis.BMC = BMC{
-				Manufacturer: strings.ToLower(component.Vendor),
-				Model:        component.Model,
-				Firmware:     firmware,
-			}
+			c.Name = common.SlugBMC
+			// fleetdb doesn't record the BMC serial
+			c.Serial = ""
 
 		case MotherboardType:
-			commonData.Chassis.Motherboard = Motherboard{
-				Manufacturer: strings.ToLower(component.Vendor),
-				Model:        component.Model,
-				Firmware:     firmware,
-				Serial:       component.Serial,
-			}
+			// the motherboard firmware is the BIOS in fleetdb
+			c.Name = common.SlugBIOS
 
 		case CPUType:
 			cores, _ := component.Data["cores"].(float64)
 			clock, _ := component.Data["clock"].(float64)
-			commonData.Chassis.CPU = append(commonData.Chassis.CPU, CPU{
-				Manufacturer: strings.ToLower(component.Vendor),
-				Model:        component.Model,
-				Cores:        int(cores),
-				ClockSpeed:   int64(clock),
-				Firmware:     firmware,
-			})
+
+			c.Name = common.SlugCPU
+			c.Serial = ""
+			c.Attributes.Cores = int(cores)
+			c.Attributes.ClockSpeedHz = int64(clock)
 
 		case DiskType:
-			bytesStr := component.Data["size"]
-			bytes, _ := parseSizeWithUnit(bytesStr.(string))
-			commonData.Chassis.Disk = append(commonData.Chassis.Disk, Disk{
-				Manufacturer: strings.ToLower(component.Vendor),
-				Model:        component.Model,
-				Capacity:     bytes,
-				Firmware:     firmware,
-				Serial:       component.Serial,
-			})
+			size, _ := component.Data["size"].(string)
+			bytes, err := parseSizeWithUnit(size)
+			if err != nil {
+				return nil, err
+			}
+
+			c.Name = common.SlugDrive
+			c.Attributes.CapacityBytes = bytes
 
 		case MemoryType:
-			capStr := component.Data["size"].(string)
-			cap, _ := parseSizeWithUnit(capStr)
-			commonData.Chassis.Memory = append(commonData.Chassis.Memory, Memory{
-				Manufacturer: strings.ToLower(component.Vendor),
-				Model:        component.Model,
-				// GetServerComponent and GetInventory don't have this stats currently.
-				Capacity: cap,
-				Speed:    int64(math.Round(component.Data["clock"].(float64) * 1000000)),
-				Firmware: firmware,
-				Serial:   component.Serial,
-			})
+			size, _ := component.Data["size"].(string)
+			bytes, err := parseSizeWithUnit(size)
+			if err != nil {
+				return nil, err
+			}
+
+			clock, _ := component.Data["clock"].(float64)
+
+			c.Name = common.SlugPhysicalMem
+			c.Attributes.SizeBytes = bytes
+			c.Attributes.ClockSpeedHz = int64(math.Round(clock * 1000000))
+			c.Attributes.PartNumber = component.Model
 
 		case NICType:
-			var rate int64
-			if component.Data["rate"] != nil {
-				if rateStr, ok := component.Data["rate"].(string); ok {
-					rate, _ = strconv.ParseInt(rateStr, 10, 64)
-				}
-			}
+			// EMAPI records the NIC MAC address as its serial
+			c.Name = common.SlugNIC
+			c.Serial = ""
+			c.Attributes.MacAddress = component.Serial
 
-			commonData.Chassis.NIC = append(commonData.Chassis.NIC, NIC{
-				Manufacturer: strings.ToLower(component.Vendor),
-				Model:        component.Model,
-				Speed:        rate,
-				Firmware:     firmware,
-				MACs:         []string{component.Serial},
-			})
+		default:
+			continue
 		}
+
+		server.Components = append(server.Components, c)
 	}
 
-	validate := validator.New()
-	err := validate.Struct(commonData)
-	if err != nil {
-		fmt.Printf("[ERROR] EMAPI is missing required fields: %v\n", err)
+	return server, nil
+}
+
+// withoutUntrackedSerials clears the serials of the fleetdb components EMAPI doesn't record a serial for.
+func withoutUntrackedSerials(server *rt.Server) *rt.Server {
+	for _, c := range server.Components {
+		switch strings.ToLower(c.Name) {
+		case strings.ToLower(common.SlugBMC), strings.ToLower(common.SlugCPU), strings.ToLower(common.SlugNIC):
+			c.Serial = ""
+		}
 	}
-	return commonData, nil
+
+	return server
 }
 
 func parseSizeWithUnit(size string) (int64, error) {
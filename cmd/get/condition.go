@@ -3,7 +3,6 @@ package get
 import (
 	"log"
 
-	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 
 	mctl "github.com/metal-toolbox/mctl/cmd"
@@ -30,7 +29,7 @@ var getCondition = &cobra.Command{
 			log.Fatal(err)
 		}
 
-		id, err := uuid.Parse(flagsDefinedGetCondition.id)
+		id, err := mctl.ResolveServerID(cmd.Context(), theApp, flagsDefinedGetCondition.id)
 		if err != nil {
 			log.Fatal(err)
 		}
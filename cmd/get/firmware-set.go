@@ -2,7 +2,6 @@ package get
 
 import (
 	"context"
-	"fmt"
 	"log"
 	"os"
 	"strings"
@@ -14,11 +13,13 @@ import (
 
 	mctl "github.com/metal-toolbox/mctl/cmd"
 	"github.com/metal-toolbox/mctl/internal/app"
+	"github.com/metal-toolbox/mctl/internal/fleetdb"
 )
 
 type getFirmwareSetFlags struct {
 	id       string
 	serverID string
+	labels   map[string]string
 }
 
 var (
@@ -71,11 +72,10 @@ var getFirmwareSet = &cobra.Command{
 
 func firmwareSetForServer(ctx context.Context, client *fleetdbapi.Client, serverID string) (*fleetdbapi.ComponentFirmwareSet, error) {
 	errNoVendorAttrs := errors.New("unable to determine server vendor, model attributes")
-	errNotFound := errors.New("no firmware sets identified for server")
 
-	serverUUID, err := uuid.Parse(serverID)
+	serverUUID, err := fleetdb.ResolveServer(ctx, client, serverID)
 	if err != nil {
-		return nil, errors.Wrap(err, "invalid server ID")
+		return nil, err
 	}
 
 	// validate server exists
@@ -94,26 +94,7 @@ func firmwareSetForServer(ctx context.Context, client *fleetdbapi.Client, server
 		return nil, errNoVendorAttrs
 	}
 
-	params := &fleetdbapi.ComponentFirmwareSetListParams{
-		Vendor: strings.TrimSpace(vendor),
-		Model:  strings.TrimSpace(model),
-		Labels: "default=true,latest=true",
-	}
-
-	// identify firmware set by vendor, model attributes
-	fwSet, _, err := client.ListServerComponentFirmwareSet(ctx, params)
-	if err != nil {
-		return nil, err
-	}
-
-	if len(fwSet) == 0 { // len(nil) is also 0
-		return nil, errors.Wrap(
-			errNotFound,
-			fmt.Sprintf("vendor: %s, model: %s", vendor, model),
-		)
-	}
-
-	return &fwSet[0], nil
+	return mctl.FirmwareSetByVendorModel(ctx, client, vendor, model, flagsDefinedGetFirmwareSet.labels)
 }
 
 func init() {
@@ -122,5 +103,9 @@ func init() {
 	mctl.AddServerFlag(getFirmwareSet, &flagsDefinedGetFirmwareSet.serverID)
 	mctl.AddFirmwareSetFlag(getFirmwareSet, &flagsDefinedGetFirmwareSet.id)
 
+	mctl.AddLabelsFlag(getFirmwareSet, &flagsDefinedGetFirmwareSet.labels,
+		"Labels to select the firmware set for the server instead of default=true,latest=true - 'channel=canary'")
+
 	mctl.MutuallyExclusiveFlags(getFirmwareSet, mctl.ServerFlag, mctl.FirmwareSetFlag)
+	mctl.MutuallyExclusiveFlags(getFirmwareSet, mctl.FirmwareSetFlag, mctl.LabelsFlag)
 }
@@ -4,18 +4,34 @@ import (
 	"context"
 	"log"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/google/uuid"
+	fleetdbapi "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 
 	mctl "github.com/metal-toolbox/mctl/cmd"
 	"github.com/metal-toolbox/mctl/internal/app"
+	"github.com/metal-toolbox/mctl/internal/fleetdb"
 )
 
 type getFirmwareFlags struct {
 	id string
 }
 
+// firmwareWithSets is a firmware and the firmware sets it is a member of.
+type firmwareWithSets struct {
+	*fleetdbapi.ComponentFirmwareVersion
+	MemberOfSets []firmwareSetRef `json:"member_of_sets"`
+}
+
+type firmwareSetRef struct {
+	UUID uuid.UUID `json:"uuid"`
+	Name string    `json:"name"`
+}
+
 var (
 	flagsDefinedGetFirmware *getFirmwareFlags
 )
@@ -24,6 +40,7 @@ var (
 var getFirmware = &cobra.Command{
 	Use:   "firmware",
 	Short: "Get information for given firmware identifier",
+	Long:  "Get information for given firmware identifier, including the firmware sets it is a member of.",
 	Run: func(cmd *cobra.Command, _ []string) {
 		theApp := mctl.MustCreateApp(cmd.Context())
 
@@ -45,11 +62,71 @@ var getFirmware = &cobra.Command{
 			log.Fatal("fleetdb API client returned error: ", err)
 		}
 
-		mctl.PrintResults(output, firmware)
+		allSets, err := mctl.ListFirmwareSets(ctx, client)
+		if err != nil {
+			log.Fatal("fleetdb API client returned error: ", err)
+		}
+
+		sets := fleetdb.FirmwareSetsWithFirmware(allSets, fwID)
+
+		result := &firmwareWithSets{ComponentFirmwareVersion: firmware, MemberOfSets: []firmwareSetRef{}}
+		for idx := range sets {
+			result.MemberOfSets = append(result.MemberOfSets, firmwareSetRef{UUID: sets[idx].UUID, Name: sets[idx].Name})
+		}
+
+		if output == mctl.OutputTypeText.String() {
+			renderFirmwareTable(result)
+			os.Exit(0)
+		}
+
+		mctl.PrintResults(output, result)
 		os.Exit(0)
 	},
 }
 
+func renderFirmwareTable(fw *firmwareWithSets) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+
+	table.AppendBulk([][]string{
+		{"UUID", fw.UUID.String()},
+		{"Vendor", fw.Vendor},
+		{"Model", strings.Join(fw.Model, ",")},
+		{"Component", fw.Component},
+		{"Version", fw.Version},
+		{"Filename", fw.Filename},
+		{"Checksum", fw.Checksum},
+		{"Upstream URL", fw.UpstreamURL},
+		{"Repository URL", fw.RepositoryURL},
+		{"Install Inband", boolPtrString(fw.InstallInband)},
+		{"OEM", boolPtrString(fw.OEM)},
+	})
+
+	table.Render()
+
+	if len(fw.MemberOfSets) == 0 {
+		log.Println("firmware is not a member of any firmware set")
+		return
+	}
+
+	sets := tablewriter.NewWriter(os.Stdout)
+	sets.SetHeader([]string{"Member of set", "Name"})
+
+	for _, set := range fw.MemberOfSets {
+		sets.Append([]string{set.UUID.String(), set.Name})
+	}
+
+	sets.Render()
+}
+
+func boolPtrString(b *bool) string {
+	if b == nil {
+		return "-"
+	}
+
+	return strconv.FormatBool(*b)
+}
+
 func init() {
 	flagsDefinedGetFirmware = &getFirmwareFlags{}
 
@@ -25,6 +25,7 @@ func init() {
 	cmdGet.AddCommand(getFirmware)
 	cmdGet.AddCommand(getFirmwareSet)
 	cmdGet.AddCommand(getBiosConfig)
+	cmdGet.AddCommand(getBiosConfigSet)
 	cmdGet.AddCommand(getBomInfoByMacAddress)
 
 	cmd.AddOutputFlag(cmdGet, &output)
@@ -49,7 +49,7 @@ var getServer = &cobra.Command{
 			log.Fatal(err)
 		}
 
-		id, err := uuid.Parse(cmdArgs.id)
+		id, err := fleetdb.ResolveServer(ctx, client, cmdArgs.id)
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -0,0 +1,158 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	mctl "github.com/metal-toolbox/mctl/cmd"
+	"github.com/metal-toolbox/mctl/internal/audit"
+)
+
+type historyFlags struct {
+	since    string
+	serverID string
+	user     string
+	subject  string
+	command  string
+	limit    int
+	output   string
+}
+
+var (
+	flagsDefinedHistory *historyFlags
+)
+
+var history = &cobra.Command{
+	Use:   "history",
+	Short: "List mutating operations recorded in the local audit log",
+	Example: `  # operations in the last day
+  mctl history --since 24h
+
+  # server deletes on a server
+  mctl history --command "delete server" --server fd5c3a0b-4d2f-4c6c-8e7b-32b0f1d0b4a1`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		filter, err := filterFromFlags(flagsDefinedHistory, time.Now())
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// records hold server IDs, other server references are resolved through fleetdb
+		if filter.Target != "" {
+			if _, err := uuid.Parse(filter.Target); err != nil {
+				theApp := mctl.MustCreateApp(cmd.Context())
+				filter.Target = mctl.MustResolveServerID(cmd.Context(), theApp, filter.Target).String()
+			}
+		}
+
+		path, err := audit.DefaultPath()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		records, err := audit.New(path).Records(filter)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if flagsDefinedHistory.output == mctl.OutputTypeText.String() {
+			printTable(records)
+			return
+		}
+
+		b, err := json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Println(string(b))
+	},
+}
+
+func filterFromFlags(f *historyFlags, now time.Time) (*audit.Filter, error) {
+	filter := &audit.Filter{
+		User:    f.user,
+		Subject: f.subject,
+		Command: f.command,
+		Target:  f.serverID,
+		Limit:   f.limit,
+	}
+
+	if f.since == "" {
+		return filter, nil
+	}
+
+	since, err := mctl.ParseSince(f.since, now)
+	if err != nil {
+		return nil, err
+	}
+
+	filter.Since = since
+
+	return filter, nil
+}
+
+func printTable(records []*audit.Record) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Time", "User", "Subject", "Command", "Targets", "Results"})
+	table.SetAutoWrapText(false)
+
+	for _, r := range records {
+		results := make([]string, 0, len(r.Results))
+		for _, result := range r.Results {
+			results = append(results, formatResult(&result))
+		}
+
+		table.Append([]string{
+			r.Time.Local().Format(time.DateTime),
+			r.User,
+			r.Subject,
+			r.Command,
+			strings.Join(r.Targets, "\n"),
+			strings.Join(results, "\n"),
+		})
+	}
+
+	table.Render()
+}
+
+func formatResult(r *audit.Result) string {
+	parts := []string{}
+
+	if r.ID != "" {
+		parts = append(parts, "id="+r.ID)
+	}
+
+	if r.StatusCode != 0 {
+		parts = append(parts, "status="+strconv.Itoa(r.StatusCode))
+	}
+
+	if r.Message != "" {
+		parts = append(parts, r.Message)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func init() {
+	flagsDefinedHistory = &historyFlags{}
+
+	mctl.RootCmd.AddCommand(history)
+
+	mctl.AddSinceFlag(history, &flagsDefinedHistory.since,
+		"list operations since a duration ago - '24h', a date - '2006-01-02' or an RFC3339 time")
+	mctl.AddServerFlag(history, &flagsDefinedHistory.serverID)
+	mctl.AddUserFlag(history, &flagsDefinedHistory.user, "filter by the local user that ran the command")
+	mctl.AddSubjectFlag(history, &flagsDefinedHistory.subject)
+	mctl.AddCommandFlag(history, &flagsDefinedHistory.command, "filter by command - 'delete server'")
+	mctl.AddLimitFlag(history, &flagsDefinedHistory.limit, "list at most the given number of the latest operations")
+	mctl.AddOutputFlag(history, &flagsDefinedHistory.output)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
@@ -19,14 +20,20 @@ import (
 type installFirmwareSetFlags struct {
 	firmwareSetID         string
 	serverID              string
+	labels                map[string]string
+	plan                  bool
 	forceInstall          bool
 	skipBMCReset          bool
 	requireHostPoweredOff bool
 	dryRun                bool
+	confirm               mctl.ConfirmParams
+	schedule              mctl.ScheduleParams
 }
 
 var (
 	flagsDefinedInstallFwSet *installFirmwareSetFlags
+
+	errNoVendorAttrs = errors.New("unable to determine server vendor, model attributes")
 )
 
 // List
@@ -42,7 +49,7 @@ var installFirmwareSet = &cobra.Command{
 func installFwSet(ctx context.Context) {
 	theApp := mctl.MustCreateApp(ctx)
 
-	serverID, err := uuid.Parse(flagsDefinedInstallFwSet.serverID)
+	serverID, err := mctl.ResolveServerID(ctx, theApp, flagsDefinedInstallFwSet.serverID)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -52,12 +59,12 @@ func installFwSet(ctx context.Context) {
 		log.Fatal(errors.Wrap(err, "fleetdb API client init error"))
 	}
 
-	fwSetID, err := firmwareSetForInstall(ctx, ssclient, serverID)
+	server, err := serverForInstall(ctx, ssclient, serverID)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	client, err := app.NewConditionsClient(ctx, theApp.Config.Conditions, theApp.Reauth)
+	fwSetID, err := firmwareSetForInstall(ctx, ssclient, server)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -71,6 +78,68 @@ func installFwSet(ctx context.Context) {
 		RequireHostPoweredOff: flagsDefinedInstallFwSet.requireHostPoweredOff,
 	}
 
+	action := "firmware-set " + fwSetID.String()
+
+	if flagsDefinedInstallFwSet.plan {
+		set, _, err := ssclient.GetServerComponentFirmwareSet(ctx, fwSetID)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		_, serverModel := mctl.VendorModelFromAttrs(server.Attributes)
+
+		plan, err := installPlan(ctx, ssclient, params, set.ComponentFirmware, serverModel)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		printInstallPlan(output, plan)
+
+		return
+	}
+
+	submitFirmwareInstall(ctx, theApp, action, params, &flagsDefinedInstallFwSet.confirm, &flagsDefinedInstallFwSet.schedule)
+}
+
+// submitFirmwareInstall creates the firmware install condition, or schedules it when the schedule
+// flags were given, a forced install is confirmed first.
+func submitFirmwareInstall(
+	ctx context.Context,
+	theApp *app.App,
+	action string,
+	params *rctypes.FirmwareInstallTaskParameters,
+	confirm *mctl.ConfirmParams,
+	schedule *mctl.ScheduleParams,
+) {
+	// a forced install re-flashes firmware regardless of the installed version
+	if params.ForceInstall && !params.DryRun {
+		op := &mctl.Destructive{
+			Action: "force install " + action,
+			Header: mctl.ServerSummaryHeader,
+			Rows:   mctl.ServerSummaryRows(ctx, theApp, params.AssetID),
+		}
+
+		if err := mctl.ConfirmDestructive(theApp, op, confirm); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if schedule.Scheduled() {
+		job, err := mctl.ScheduleCondition(theApp, schedule, action, params.AssetID, rctypes.FirmwareInstall, params)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		log.Printf("scheduled jobID=%s at=%s", job.ID, job.At.Format(time.RFC3339))
+
+		return
+	}
+
+	client, err := app.NewConditionsClient(ctx, theApp.Config.Conditions, theApp.Reauth)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	response, err := client.ServerFirmwareInstall(ctx, params)
 	if err != nil {
 		log.Fatal(err)
@@ -82,22 +151,27 @@ func installFwSet(ctx context.Context) {
 	}
 
 	log.Printf("status=%d msg=%s conditionID=%s", response.StatusCode, response.Message, condition.ID)
-}
 
-func firmwareSetForInstall(ctx context.Context, client *fleetdbapi.Client, serverID uuid.UUID) (fwSetID uuid.UUID, err error) {
-	errInvalidFwSetID := errors.New("invalid firmware set ID")
-	errNoVendorAttrs := errors.New("unable to determine server vendor, model attributes")
+	mctl.AuditCondition(theApp, params.AssetID, response, condition.ID)
+}
 
-	// validate server exists
+// serverForInstall returns the server to install firmware on.
+func serverForInstall(ctx context.Context, client *fleetdbapi.Client, serverID uuid.UUID) (*fleetdbapi.Server, error) {
 	server, _, err := client.Get(ctx, serverID)
 	if err != nil {
 		if strings.Contains(err.Error(), "resource not found") {
-			return uuid.Nil, errors.Wrap(err, "invalid server ID")
+			return nil, errors.Wrap(err, "invalid server ID")
 		}
 
-		return uuid.Nil, errors.Wrap(err, "failed to retrieve server object")
+		return nil, errors.Wrap(err, "failed to retrieve server object")
 	}
 
+	return server, nil
+}
+
+func firmwareSetForInstall(ctx context.Context, client *fleetdbapi.Client, server *fleetdbapi.Server) (fwSetID uuid.UUID, err error) {
+	errInvalidFwSetID := errors.New("invalid firmware set ID")
+
 	// if a firmware set identifier was given, validate and return
 	if flagsDefinedInstallFwSet.firmwareSetID != "" {
 		fwSetID, err = uuid.Parse(flagsDefinedInstallFwSet.firmwareSetID)
@@ -116,16 +190,16 @@ func firmwareSetForInstall(ctx context.Context, client *fleetdbapi.Client, serve
 	// identify vendor, model attributes
 	vendor, model := mctl.VendorModelFromAttrs(server.Attributes)
 	if vendor == "" || model == "" {
-		return uuid.Nil, errors.Wrap(errNoVendorAttrs, "specify a firmware set ID with --id instead")
+		return uuid.Nil, errors.Wrap(errNoVendorAttrs, "specify a firmware set ID with --set-id instead")
 	}
 
 	// identify firmware set by vendor, model attributes
-	fwSetID, err = mctl.FirmwareSetIDByVendorModel(ctx, vendor, model, client)
+	fwSet, err := mctl.FirmwareSetByVendorModel(ctx, client, vendor, model, flagsDefinedInstallFwSet.labels)
 	if err != nil {
-		return uuid.Nil, errors.Wrap(err, "specify a firmware set ID with --id instead")
+		return uuid.Nil, errors.Wrap(err, "specify a firmware set ID with --set-id instead")
 	}
 
-	return fwSetID, nil
+	return fwSet.UUID, nil
 }
 
 func init() {
@@ -133,13 +207,21 @@ func init() {
 
 	mctl.AddServerFlag(installFirmwareSet, &flagsDefinedInstallFwSet.serverID)
 	mctl.AddFirmwareSetFlag(installFirmwareSet, &flagsDefinedInstallFwSet.firmwareSetID)
+	mctl.AddLabelsFlag(installFirmwareSet, &flagsDefinedInstallFwSet.labels,
+		"Labels to select the firmware set for the server vendor, model instead of default=true,latest=true - 'channel=canary'")
+	mctl.AddPlanFlag(installFirmwareSet, &flagsDefinedInstallFwSet.plan,
+		"print the components the install changes and the effect of the install flags without installing")
 	mctl.AddForceFlag(installFirmwareSet, &flagsDefinedInstallFwSet.forceInstall,
 		"force install (skips firmware version check)")
 	mctl.AddDryRunFlag(installFirmwareSet, &flagsDefinedInstallFwSet.dryRun,
 		"Run install process in dry-run (skips firmware install)")
+	mctl.AddConfirmFlags(installFirmwareSet, &flagsDefinedInstallFwSet.confirm)
+	mctl.AddScheduleFlags(installFirmwareSet, &flagsDefinedInstallFwSet.schedule)
 	mctl.AddSkipBmcResetFlag(installFirmwareSet, &flagsDefinedInstallFwSet.skipBMCReset)
 	mctl.AddPowerOffRequiredFlag(installFirmwareSet, &flagsDefinedInstallFwSet.requireHostPoweredOff,
 		"require host to be powered off before proceeding install")
 
 	mctl.RequireFlag(installFirmwareSet, mctl.ServerFlag)
+	mctl.MutuallyExclusiveFlags(installFirmwareSet, mctl.FirmwareSetFlag, mctl.LabelsFlag)
+	mctl.MutuallyExclusiveFlags(installFirmwareSet, mctl.PlanFlag, mctl.AtFlag)
 }
@@ -6,6 +6,10 @@ import (
 	"github.com/metal-toolbox/mctl/cmd"
 )
 
+var (
+	output string
+)
+
 var install = &cobra.Command{
 	Use:   "install",
 	Short: "Install actions",
@@ -19,4 +23,6 @@ func init() {
 
 	install.AddCommand(installFirmwareSet)
 	install.AddCommand(installStatus)
+
+	cmd.AddOutputFlag(install, &output)
 }
@@ -0,0 +1,140 @@
+package install
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/google/uuid"
+	"github.com/olekukonko/tablewriter"
+
+	fleetdbapi "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	rt "github.com/metal-toolbox/rivets/v2/types"
+
+	mctl "github.com/metal-toolbox/mctl/cmd"
+	"github.com/metal-toolbox/mctl/internal/fleetdb"
+)
+
+// firmwareInstallPlan is the expected outcome of a firmware install on a server.
+type firmwareInstallPlan struct {
+	ServerID      uuid.UUID                 `json:"server_id"`
+	FirmwareSetID uuid.UUID                 `json:"firmware_set_id,omitempty"`
+	Components    []fleetdb.InstallPlanItem `json:"components"`
+	Notes         []string                  `json:"notes"`
+}
+
+// installPlan returns the changes the install of the firmware makes to the server components.
+func installPlan(
+	ctx context.Context,
+	client *fleetdbapi.Client,
+	params *rctypes.FirmwareInstallTaskParameters,
+	firmware []fleetdbapi.ComponentFirmwareVersion,
+	serverModel string,
+) (*firmwareInstallPlan, error) {
+	records, err := mctl.ServerComponents(ctx, client, params.AssetID)
+	if err != nil {
+		return nil, err
+	}
+
+	components := make([]*rt.Component, 0, len(records))
+
+	for idx := range records {
+		component, err := fleetdb.RecordToComponent(&records[idx])
+		if err != nil {
+			return nil, err
+		}
+
+		components = append(components, component)
+	}
+
+	return &firmwareInstallPlan{
+		ServerID:      params.AssetID,
+		FirmwareSetID: params.FirmwareSetID,
+		Components:    fleetdb.PlanFirmwareInstall(components, firmware, serverModel, params.ForceInstall),
+		Notes:         installNotes(params),
+	}, nil
+}
+
+// installNotes describes the power and BMC reset behavior of the install parameters.
+func installNotes(params *rctypes.FirmwareInstallTaskParameters) []string {
+	if params.DryRun {
+		return []string{"dry run: firmware is downloaded and checked but not installed, a powered off host is not powered on"}
+	}
+
+	notes := []string{}
+
+	if params.ResetBMCBeforeInstall {
+		notes = append(notes, "the BMC is reset before the install, --skip-bmc-reset skips the reset")
+	} else {
+		notes = append(notes, "the BMC is not reset before the install")
+	}
+
+	if params.RequireHostPoweredOff {
+		notes = append(notes, "the install fails if the host is powered on")
+	} else {
+		notes = append(notes, "the host may be powered on or power cycled to apply firmware, --power-off-required fails the install on a powered on host instead")
+	}
+
+	if params.ForceInstall {
+		notes = append(notes, "firmware is installed regardless of the installed version")
+	}
+
+	return notes
+}
+
+func printInstallPlan(format string, plan *firmwareInstallPlan) {
+	if format == mctl.OutputTypeJSON.String() {
+		b, err := json.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Println(string(b))
+
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Component", "Vendor", "Model", "Serial", "Installed", "Target", "Action", "Reason"})
+	table.SetAutoWrapText(false)
+
+	changes, unknown := 0, 0
+
+	for idx := range plan.Components {
+		item := &plan.Components[idx]
+
+		switch item.Action {
+		case fleetdb.InstallSkip:
+		case fleetdb.InstallUnknown:
+			unknown++
+		default:
+			changes++
+		}
+
+		table.Append([]string{
+			item.Component,
+			item.Vendor,
+			item.Model,
+			item.Serial,
+			item.Installed,
+			item.Target,
+			string(item.Action),
+			item.Reason,
+		})
+	}
+
+	table.Render()
+
+	fmt.Printf("%d component(s) will change\n", changes)
+
+	if unknown > 0 {
+		fmt.Printf("%d component(s) with firmware in the set have no installed version in the inventory\n", unknown)
+	}
+
+	for _, note := range plan.Notes {
+		fmt.Println("- " + note)
+	}
+}
@@ -0,0 +1,26 @@
+package install
+
+import (
+	"testing"
+
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstallNotes(t *testing.T) {
+	notes := installNotes(&rctypes.FirmwareInstallTaskParameters{ResetBMCBeforeInstall: true})
+	assert.Len(t, notes, 2)
+	assert.Contains(t, notes[0], "the BMC is reset")
+	assert.Contains(t, notes[1], "may be powered on or power cycled")
+
+	notes = installNotes(&rctypes.FirmwareInstallTaskParameters{RequireHostPoweredOff: true, ForceInstall: true})
+	assert.Equal(t, []string{
+		"the BMC is not reset before the install",
+		"the install fails if the host is powered on",
+		"firmware is installed regardless of the installed version",
+	}, notes)
+
+	notes = installNotes(&rctypes.FirmwareInstallTaskParameters{DryRun: true, ResetBMCBeforeInstall: true})
+	assert.Len(t, notes, 1)
+	assert.Contains(t, notes[0], "dry run")
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"log"
 
-	"github.com/google/uuid"
 	rctypes "github.com/metal-toolbox/rivets/v2/condition"
 	"github.com/spf13/cobra"
 
@@ -31,7 +30,7 @@ func statusCheck(ctx context.Context) {
 		log.Fatal(err)
 	}
 
-	serverID, err := uuid.Parse(serverIDStr)
+	serverID, err := mctl.ResolveServerID(ctx, theApp, serverIDStr)
 	if err != nil {
 		log.Fatalf("parsing server id: %s", err.Error())
 	}
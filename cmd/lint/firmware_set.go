@@ -0,0 +1,212 @@
+package lint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/google/uuid"
+	fleetdbapi "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	mctl "github.com/metal-toolbox/mctl/cmd"
+	"github.com/metal-toolbox/mctl/internal/app"
+	"github.com/metal-toolbox/mctl/internal/fleetdb"
+)
+
+type lintFirmwareSetFlags struct {
+	id       string
+	fromFile string
+	offline  bool
+}
+
+var (
+	flagsDefinedLintFirmwareSet *lintFirmwareSetFlags
+)
+
+var lintFirmwareSet = &cobra.Command{
+	Use:   "firmware-set",
+	Short: "Check a firmware set for problems before it is installed",
+	Long: `Check a firmware set in fleetdb, or the sets in a file read by 'mctl create firmware-set --from-file'.
+
+Errors are duplicate firmware for a component, firmware for other models than the set model label,
+firmware missing a checksum or repository URL and sets without vendor and model labels. Warnings are
+firmware from another vendor than the set vendor label and versions older than the firmware in the set
+deployed for the vendor and model - the set labeled latest and default.
+
+The command exits with a non-zero status when errors are found. With --offline the sets in a file are
+not compared to the deployed set.`,
+	Example: `  # lint a set in fleetdb
+  mctl lint firmware-set --set-id 8c0d21a2-6b3d-4b0a-9f0e-6e4b3a1a9a0f -o text
+
+  # lint the sets in a file without fleetdb access
+  mctl lint firmware-set --from-file samples/fw-set.json --offline -o text`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		var (
+			sets   []fleetdbapi.ComponentFirmwareSet
+			client *fleetdbapi.Client
+			err    error
+		)
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), mctl.CmdTimeout)
+		defer cancel()
+
+		if !flagsDefinedLintFirmwareSet.offline {
+			theApp := mctl.MustCreateApp(cmd.Context())
+
+			client, err = app.NewFleetDBAPIClient(ctx, theApp.Config.FleetDBAPI, theApp.Reauth)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		if flagsDefinedLintFirmwareSet.fromFile != "" {
+			sets, err = firmwareSetsFromFile(flagsDefinedLintFirmwareSet.fromFile)
+		} else {
+			sets, err = firmwareSetByID(ctx, client, flagsDefinedLintFirmwareSet.id)
+		}
+
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		findings, err := lintFirmwareSets(ctx, client, sets)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if !printFindings(output, findings) {
+			os.Exit(1)
+		}
+	},
+}
+
+func firmwareSetsFromFile(path string) ([]fleetdbapi.ComponentFirmwareSet, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sets := []fleetdbapi.ComponentFirmwareSet{}
+	if err := json.Unmarshal(b, &sets); err != nil {
+		return nil, err
+	}
+
+	return sets, nil
+}
+
+func firmwareSetByID(ctx context.Context, client *fleetdbapi.Client, setID string) ([]fleetdbapi.ComponentFirmwareSet, error) {
+	id, err := uuid.Parse(setID)
+	if err != nil {
+		return nil, err
+	}
+
+	set, _, err := client.GetServerComponentFirmwareSet(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return []fleetdbapi.ComponentFirmwareSet{*set}, nil
+}
+
+// lintFirmwareSets returns the findings for the sets, sets are compared to the deployed
+// set for their vendor, model when a client is given.
+func lintFirmwareSets(ctx context.Context, client *fleetdbapi.Client, sets []fleetdbapi.ComponentFirmwareSet) ([]fleetdb.LintFinding, error) {
+	var fleetSets []fleetdbapi.ComponentFirmwareSet
+
+	if client != nil {
+		var err error
+
+		fleetSets, err = mctl.ListFirmwareSets(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	findings := []fleetdb.LintFinding{}
+
+	for idx := range sets {
+		set := &sets[idx]
+
+		var deployed *fleetdbapi.ComponentFirmwareSet
+
+		// sets without labels are reported by the lint
+		labels, _ := fleetdb.FirmwareSetLabels(set)
+		if client != nil && labels["vendor"] != "" && labels["model"] != "" {
+			promoted, err := fleetdb.PromotedFirmwareSets(fleetSets, labels["vendor"], labels["model"])
+			if err != nil {
+				return nil, err
+			}
+
+			if len(promoted) == 1 {
+				deployed = &promoted[0]
+			}
+
+			if len(promoted) > 1 {
+				findings = append(findings, fleetdb.LintFinding{
+					Severity: fleetdb.SeverityWarning,
+					SetName:  set.Name,
+					Message: fmt.Sprintf(
+						"%d sets are labeled latest and default for vendor: %s, model: %s, versions are not compared",
+						len(promoted), labels["vendor"], labels["model"],
+					),
+				})
+			}
+		}
+
+		findings = append(findings, fleetdb.LintFirmwareSet(set, deployed)...)
+	}
+
+	return findings, nil
+}
+
+// printFindings prints the findings and returns false when errors were found.
+func printFindings(format string, findings []fleetdb.LintFinding) bool {
+	counts := map[fleetdb.Severity]int{}
+	for idx := range findings {
+		counts[findings[idx].Severity]++
+	}
+
+	if format == mctl.OutputTypeJSON.String() {
+		b, err := json.MarshalIndent(findings, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Println(string(b))
+
+		return counts[fleetdb.SeverityError] == 0
+	}
+
+	if len(findings) > 0 {
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Severity", "Set", "Firmware", "Component", "Message"})
+		table.SetAutoWrapText(false)
+
+		for idx := range findings {
+			f := &findings[idx]
+			table.Append([]string{string(f.Severity), f.SetName, f.FirmwareID, f.Component, f.Message})
+		}
+
+		table.Render()
+	}
+
+	fmt.Printf("%d error(s), %d warning(s)\n", counts[fleetdb.SeverityError], counts[fleetdb.SeverityWarning])
+
+	return counts[fleetdb.SeverityError] == 0
+}
+
+func init() {
+	flagsDefinedLintFirmwareSet = &lintFirmwareSetFlags{}
+
+	mctl.AddFirmwareSetFlag(lintFirmwareSet, &flagsDefinedLintFirmwareSet.id)
+	mctl.AddFromFileFlag(lintFirmwareSet, &flagsDefinedLintFirmwareSet.fromFile, "JSON file with firmware sets in the 'mctl create firmware-set --from-file' format")
+	mctl.AddOfflineFlag(lintFirmwareSet, &flagsDefinedLintFirmwareSet.offline, "lint the sets in the file without comparing them to the deployed sets in fleetdb")
+
+	mctl.RequireOneFlag(lintFirmwareSet, mctl.FirmwareSetFlag, mctl.FromFileFlag)
+	mctl.MutuallyExclusiveFlags(lintFirmwareSet, mctl.FirmwareSetFlag, mctl.FromFileFlag)
+	mctl.MutuallyExclusiveFlags(lintFirmwareSet, mctl.FirmwareSetFlag, mctl.OfflineFlag)
+}
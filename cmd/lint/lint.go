@@ -0,0 +1,26 @@
+package lint
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/metal-toolbox/mctl/cmd"
+)
+
+var (
+	output string
+)
+
+var lint = &cobra.Command{
+	Use:   "lint",
+	Short: "Check resources for problems before they are used",
+	Run: func(cmd *cobra.Command, _ []string) {
+		_ = cmd.Help()
+	},
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(lint)
+	lint.AddCommand(lintFirmwareSet)
+
+	cmd.AddOutputFlag(lint, &output)
+}
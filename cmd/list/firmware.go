@@ -19,6 +19,8 @@ type listFirmwareFlags struct {
 	model     string
 	component string
 	version   string
+	filename  string
+	checksum  string
 	limit     int
 	page      int
 }
@@ -46,6 +48,8 @@ var listFirmware = &cobra.Command{
 			Vendor:    strings.ToLower(flagsDefinedListFirmware.vendor),
 			Version:   flagsDefinedListFirmware.version,
 			Component: flagsDefinedListFirmware.component,
+			Filename:  flagsDefinedListFirmware.filename,
+			Checksum:  strings.ToLower(flagsDefinedListFirmware.checksum),
 			Pagination: &fleetdbapi.PaginationParams{
 				Limit: flagsDefinedListFirmware.limit,
 				Page:  flagsDefinedListFirmware.page,
@@ -90,6 +94,8 @@ func init() {
 	mctl.AddModelFlag(listFirmware, &flagsDefinedListFirmware.model)
 	mctl.AddComponentTypeFlag(listFirmware, &flagsDefinedListFirmware.component)
 	mctl.AddFirmwareVersionFlag(listFirmware, &flagsDefinedListFirmware.version)
+	mctl.AddFilenameFlag(listFirmware, &flagsDefinedListFirmware.filename, "filter by firmware file name")
+	mctl.AddChecksumFlag(listFirmware, &flagsDefinedListFirmware.checksum, "filter by firmware file checksum")
 	mctl.AddPageLimitFlag(listFirmware, &flagsDefinedListFirmware.limit)
 	mctl.AddPageFlag(listFirmware, &flagsDefinedListFirmware.page)
 }
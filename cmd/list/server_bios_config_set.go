@@ -1,11 +1,9 @@
 package list
 
 import (
-	"context"
-	"errors"
-	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	fleetdbapi "github.com/metal-toolbox/fleetdb/pkg/api/v1"
 	mctl "github.com/metal-toolbox/mctl/cmd"
@@ -14,24 +12,43 @@ import (
 	"github.com/spf13/cobra"
 )
 
-//nolint:err113 // brevity is best here
-func sendListServerBiosConfigSetRequest(client *fleetdbapi.Client, _ *cobra.Command) (*[]fleetdbapi.BiosConfigSet, error) {
-	params := &fleetdbapi.BiosConfigSetListParams{
-		Pagination: fleetdbapi.PaginationParams{
-			Preload: true,
-		},
-	}
+type listBiosConfigSetFlags struct {
+	name   string
+	vendor string
+	model  string
+	limit  int
+	page   int
+}
 
-	biosConfig, err := client.ListServerBiosConfigSet(context.Background(), params)
-	if err != nil {
-		return nil, fmt.Errorf("retrieving bios configs: %w", err)
-	}
+var (
+	flagsListBiosConfigSet *listBiosConfigSetFlags
+)
+
+// filterBiosConfigSets returns the sets matching the name and with a component matching the vendor and model,
+// fleetdb doesn't support filtering bios config sets.
+func filterBiosConfigSets(sets []fleetdbapi.BiosConfigSet, fl *listBiosConfigSetFlags) []fleetdbapi.BiosConfigSet {
+	filtered := []fleetdbapi.BiosConfigSet{}
+
+	for idx := range sets {
+		if fl.name != "" && !strings.Contains(strings.ToLower(sets[idx].Name), strings.ToLower(fl.name)) {
+			continue
+		}
+
+		if fl.vendor == "" && fl.model == "" {
+			filtered = append(filtered, sets[idx])
+			continue
+		}
 
-	if biosConfig.TotalRecordCount == 0 {
-		return nil, errors.New("no bios configs identified")
+		for _, component := range sets[idx].Components {
+			if (fl.vendor == "" || strings.EqualFold(component.Vendor, fl.vendor)) &&
+				(fl.model == "" || strings.EqualFold(component.Model, fl.model)) {
+				filtered = append(filtered, sets[idx])
+				break
+			}
+		}
 	}
 
-	return biosConfig.Records.(*[]fleetdbapi.BiosConfigSet), nil
+	return filtered
 }
 
 // List
@@ -46,11 +63,16 @@ var listServerBiosConfigSet = &cobra.Command{
 			log.Fatal(err)
 		}
 
-		biosConfig, err := sendListServerBiosConfigSetRequest(client, cmd)
+		sets, err := mctl.ListBIOSConfigSets(cmd.Context(), client, flagsListBiosConfigSet.page, flagsListBiosConfigSet.limit)
 		if err != nil {
 			log.Fatal(err)
 		}
 
+		biosConfig := filterBiosConfigSets(sets, flagsListBiosConfigSet)
+		if len(biosConfig) == 0 {
+			log.Fatal("no bios configs identified")
+		}
+
 		if output == mctl.OutputTypeJSON.String() {
 			printJSON(biosConfig)
 			os.Exit(0)
@@ -58,7 +80,7 @@ var listServerBiosConfigSet = &cobra.Command{
 
 		table := tablewriter.NewWriter(os.Stdout)
 		table.SetHeader([]string{"ID", "Name", "Version"})
-		for _, s := range *biosConfig {
+		for _, s := range biosConfig {
 			table.Append([]string{s.ID, s.Name, s.Version})
 		}
 
@@ -68,5 +90,11 @@ var listServerBiosConfigSet = &cobra.Command{
 }
 
 func init() {
+	flagsListBiosConfigSet = &listBiosConfigSetFlags{}
 
+	mctl.AddNameFlag(listServerBiosConfigSet, &flagsListBiosConfigSet.name, "filter by bios config set name")
+	mctl.AddVendorFlag(listServerBiosConfigSet, &flagsListBiosConfigSet.vendor)
+	mctl.AddModelFlag(listServerBiosConfigSet, &flagsListBiosConfigSet.model)
+	mctl.AddPageFlag(listServerBiosConfigSet, &flagsListBiosConfigSet.page)
+	mctl.AddPageLimitFlag(listServerBiosConfigSet, &flagsListBiosConfigSet.limit)
 }
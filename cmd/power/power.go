@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
@@ -18,6 +19,7 @@ import (
 
 	mctl "github.com/metal-toolbox/mctl/cmd"
 	"github.com/metal-toolbox/mctl/internal/app"
+	"github.com/metal-toolbox/mctl/pkg/model"
 )
 
 var powerCmd = &cobra.Command{
@@ -26,6 +28,17 @@ var powerCmd = &cobra.Command{
 		"Execute server/bmc power, set next-boot commands: [%s]",
 		strings.Join(serverPowerActions, "|"),
 	),
+	Example: `  # power cycle a server
+  mctl power --server <uuid> --action cycle
+
+  # boot into the BIOS setup once
+  mctl power --server <uuid> --boot-device bios
+
+  # always PXE boot in legacy mode, print the condition instead of submitting it
+  mctl power --server <uuid> --boot-device pxe --boot-persistent --boot-mode legacy --dry-run
+
+  # power cycle a server at 02:00 for 'mctl scheduler run' to submit, unless it is past 03:00
+  mctl power --server <uuid> --action cycle --at 02:00 --window 1h`,
 	Run: func(cmd *cobra.Command, _ []string) {
 		powerAction(cmd.Context())
 	},
@@ -50,33 +63,57 @@ var (
 		"boot-pxe-persistent",
 	}
 
-	errInvalidAction = errors.New("invalid power action requested")
+	// next boot devices accepted by the SetNextBootDevice action
+	serverBootDevices = []string{
+		"pxe",
+		"disk",
+		"cdrom",
+		"bios",
+	}
+
+	bootModeEFI    = "efi"
+	bootModeLegacy = "legacy"
+	bootModes      = []string{bootModeEFI, bootModeLegacy}
+
+	// power actions that require confirmation
+	destructivePowerActions = []string{"off", "soft"}
+
+	errInvalidAction     = errors.New("invalid power action requested")
+	errInvalidBootDevice = errors.New("invalid boot device requested")
+	errInvalidBootMode   = errors.New("invalid boot mode requested")
+	errUnsupportedBoot   = errors.New("unsupported boot parameter combination")
 )
 
 type powerActionFlags struct {
-	serverID  string
-	parameter string
+	serverID       string
+	parameter      string
+	bootDevice     string
+	bootMode       string
+	bootPersistent bool
+	dryRun         bool
+	confirm        mctl.ConfirmParams
+	schedule       mctl.ScheduleParams
 }
 
 func powerAction(ctx context.Context) {
 	theApp := mctl.MustCreateApp(ctx)
 
-	c, err := app.NewConditionsClient(ctx, theApp.Config.Conditions, theApp.Reauth)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	serverID, err := uuid.Parse(flagsDefinedPowerAction.serverID)
+	serverID, err := mctl.ResolveServerID(ctx, theApp, flagsDefinedPowerAction.serverID)
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	if queryActionStatus {
+		c, err := app.NewConditionsClient(ctx, theApp.Config.Conditions, theApp.Reauth)
+		if err != nil {
+			log.Fatal(err)
+		}
+
 		actionStatus(ctx, serverID, c)
 		return
 	}
 
-	controlParams, err := paramsFromFlags(flagsDefinedPowerAction)
+	controlParams, err := paramsFromFlags(serverID, flagsDefinedPowerAction)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -90,6 +127,53 @@ func powerAction(ctx context.Context) {
 		Parameters: params,
 	}
 
+	if flagsDefinedPowerAction.dryRun {
+		mctl.PrintDryRun(&mctl.APIRequest{
+			API:      model.ConditionsAPI,
+			Method:   "ServerConditionCreate",
+			ServerID: serverID.String(),
+			Kind:     rctypes.ServerControl,
+			Payload:  conditionCreate,
+		})
+
+		return
+	}
+
+	if controlParams.Action == rctypes.SetPowerState &&
+		slices.Contains(destructivePowerActions, controlParams.ActionParameter) {
+		op := &mctl.Destructive{
+			Action: "power " + controlParams.ActionParameter,
+			Header: mctl.ServerSummaryHeader,
+			Rows:   mctl.ServerSummaryRows(ctx, theApp, serverID),
+		}
+
+		if err = mctl.ConfirmDestructive(theApp, op, &flagsDefinedPowerAction.confirm); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if flagsDefinedPowerAction.schedule.Scheduled() {
+		description := "power " + controlParams.ActionParameter
+		if controlParams.Action == rctypes.SetNextBootDevice {
+			description = "boot " + controlParams.ActionParameter
+		}
+
+		job, err := mctl.ScheduleCondition(
+			theApp, &flagsDefinedPowerAction.schedule, description, serverID, rctypes.ServerControl, controlParams)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		log.Printf("scheduled jobID=%s at=%s", job.ID, job.At.Format(time.RFC3339))
+
+		return
+	}
+
+	c, err := app.NewConditionsClient(ctx, theApp.Config.Conditions, theApp.Reauth)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	response, err := c.ServerConditionCreate(ctx, serverID, rctypes.ServerControl, conditionCreate)
 	if err != nil {
 		log.Fatal(err)
@@ -101,6 +185,8 @@ func powerAction(ctx context.Context) {
 	}
 
 	log.Printf("status=%d msg=%s conditionID=%s", response.StatusCode, response.Message, condition.ID)
+
+	mctl.AuditCondition(theApp, serverID, response, condition.ID)
 }
 
 func actionStatus(ctx context.Context, serverID uuid.UUID, c *coclient.Client) {
@@ -117,15 +203,24 @@ func actionStatus(ctx context.Context, serverID uuid.UUID, c *coclient.Client) {
 	fmt.Println(s)
 }
 
-func paramsFromFlags(f *powerActionFlags) (*rctypes.ServerControlTaskParameters, error) {
+func paramsFromFlags(serverID uuid.UUID, f *powerActionFlags) (*rctypes.ServerControlTaskParameters, error) {
+	if f.bootDevice != "" {
+		return bootParamsFromFlags(serverID, f)
+	}
+
 	actionParam := strings.ToLower(f.parameter)
 	if !slices.Contains(serverPowerActions, actionParam) {
 		return nil, errors.Wrap(errInvalidAction, actionParam)
 	}
 
+	efiBoot, err := efiBootFromFlags(f)
+	if err != nil {
+		return nil, err
+	}
+
 	var action rctypes.ServerControlAction
 
-	var bootDevicePersistent, efiBoot bool
+	var bootDevicePersistent bool
 	switch actionParam {
 	case "on", "off", "cycle", "reset", "soft":
 		action = rctypes.SetPowerState
@@ -136,11 +231,23 @@ func paramsFromFlags(f *powerActionFlags) (*rctypes.ServerControlTaskParameters,
 	case "boot-pxe-persistent":
 		action = rctypes.PxeBootPersistent
 		bootDevicePersistent = true
-		efiBoot = true
+	}
+
+	// boot parameters are only applicable to the boot-pxe-persistent action
+	if action != rctypes.PxeBootPersistent {
+		if f.bootMode != "" || f.bootPersistent {
+			return nil, errors.Wrap(
+				errUnsupportedBoot,
+				fmt.Sprintf("--%s, --%s require --%s or the boot-pxe-persistent action",
+					mctl.BootModeFlag.Name(), mctl.BootPersistentFlag.Name(), mctl.BootDeviceFlag.Name()),
+			)
+		}
+
+		efiBoot = false
 	}
 
 	return rctypes.NewServerControlTaskParameters(
-		uuid.MustParse(f.serverID),
+		serverID,
 		action,
 		actionParam,
 		bootDevicePersistent,
@@ -148,13 +255,71 @@ func paramsFromFlags(f *powerActionFlags) (*rctypes.ServerControlTaskParameters,
 	), nil
 }
 
+// bootParamsFromFlags returns the SetNextBootDevice parameters for the boot flags.
+func bootParamsFromFlags(serverID uuid.UUID, f *powerActionFlags) (*rctypes.ServerControlTaskParameters, error) {
+	device := strings.ToLower(f.bootDevice)
+	if !slices.Contains(serverBootDevices, device) {
+		return nil, errors.Wrap(errInvalidBootDevice, device)
+	}
+
+	efiBoot, err := efiBootFromFlags(f)
+	if err != nil {
+		return nil, err
+	}
+
+	// a persistent boot into the BIOS setup leaves the server unable to boot an OS
+	if device == "bios" && f.bootPersistent {
+		return nil, errors.Wrap(errUnsupportedBoot, "the bios boot device can only be set for a one-time boot")
+	}
+
+	return rctypes.NewServerControlTaskParameters(
+		serverID,
+		rctypes.SetNextBootDevice,
+		device,
+		f.bootPersistent,
+		efiBoot,
+	), nil
+}
+
+// efiBootFromFlags returns true when the next boot is to be in EFI mode, which is the default.
+func efiBootFromFlags(f *powerActionFlags) (bool, error) {
+	switch strings.ToLower(f.bootMode) {
+	case "", bootModeEFI:
+		return true, nil
+	case bootModeLegacy:
+		return false, nil
+	default:
+		return false, errors.Wrap(errInvalidBootMode, f.bootMode)
+	}
+}
+
 func init() {
 	flagsDefinedPowerAction = &powerActionFlags{}
 
 	mctl.AddServerFlag(powerCmd, &flagsDefinedPowerAction.serverID)
 	mctl.AddServerPowerActionFlag(powerCmd, &flagsDefinedPowerAction.parameter, serverPowerActions)
 	mctl.AddServerPowerActionStatusFlag(powerCmd, &queryActionStatus)
-	mctl.MutuallyExclusiveFlags(powerCmd, mctl.ServerActionPowerActionFlag, mctl.ServerActionPowerActionStatusFlag)
-	mctl.RequireOneFlag(powerCmd, mctl.ServerActionPowerActionFlag, mctl.ServerActionPowerActionStatusFlag)
+	mctl.AddBootDeviceFlag(powerCmd, &flagsDefinedPowerAction.bootDevice, serverBootDevices)
+	mctl.AddBootPersistentFlag(powerCmd, &flagsDefinedPowerAction.bootPersistent)
+	mctl.AddBootModeFlag(powerCmd, &flagsDefinedPowerAction.bootMode, bootModes)
+	mctl.AddClientDryRunFlag(powerCmd, &flagsDefinedPowerAction.dryRun)
+	mctl.AddConfirmFlags(powerCmd, &flagsDefinedPowerAction.confirm)
+	mctl.AddScheduleFlags(powerCmd, &flagsDefinedPowerAction.schedule)
+
+	mctl.MutuallyExclusiveFlags(
+		powerCmd,
+		mctl.ServerActionPowerActionFlag,
+		mctl.ServerActionPowerActionStatusFlag,
+		mctl.BootDeviceFlag,
+	)
+	mctl.MutuallyExclusiveFlags(powerCmd, mctl.ServerActionPowerActionStatusFlag, mctl.DryRunFlag)
+	mctl.MutuallyExclusiveFlags(powerCmd, mctl.ServerActionPowerActionStatusFlag, mctl.AtFlag)
+	mctl.MutuallyExclusiveFlags(powerCmd, mctl.DryRunFlag, mctl.AtFlag)
+	mctl.RequireOneFlag(
+		powerCmd,
+		mctl.ServerActionPowerActionFlag,
+		mctl.ServerActionPowerActionStatusFlag,
+		mctl.BootDeviceFlag,
+	)
 	mctl.RequireFlag(powerCmd, mctl.ServerFlag)
 }
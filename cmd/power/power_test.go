@@ -0,0 +1,107 @@
+package power
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParamsFromFlags(t *testing.T) {
+	serverID := uuid.New()
+
+	testcases := []struct {
+		name           string
+		flags          *powerActionFlags
+		wantAction     rctypes.ServerControlAction
+		wantParam      string
+		wantPersistent bool
+		wantEFI        bool
+		wantErr        error
+	}{
+		{
+			name:       "power action",
+			flags:      &powerActionFlags{parameter: "cycle"},
+			wantAction: rctypes.SetPowerState,
+			wantParam:  "cycle",
+		},
+		{
+			name:    "invalid power action",
+			flags:   &powerActionFlags{parameter: "explode"},
+			wantErr: errInvalidAction,
+		},
+		{
+			name:           "pxe persistent defaults to efi",
+			flags:          &powerActionFlags{parameter: "boot-pxe-persistent"},
+			wantAction:     rctypes.PxeBootPersistent,
+			wantParam:      "boot-pxe-persistent",
+			wantPersistent: true,
+			wantEFI:        true,
+		},
+		{
+			name:           "pxe persistent legacy",
+			flags:          &powerActionFlags{parameter: "boot-pxe-persistent", bootMode: "legacy"},
+			wantAction:     rctypes.PxeBootPersistent,
+			wantParam:      "boot-pxe-persistent",
+			wantPersistent: true,
+		},
+		{
+			name:    "boot mode with power action",
+			flags:   &powerActionFlags{parameter: "on", bootMode: "efi"},
+			wantErr: errUnsupportedBoot,
+		},
+		{
+			name:    "boot persistent with power action",
+			flags:   &powerActionFlags{parameter: "on", bootPersistent: true},
+			wantErr: errUnsupportedBoot,
+		},
+		{
+			name:       "one-time disk boot",
+			flags:      &powerActionFlags{bootDevice: "disk"},
+			wantAction: rctypes.SetNextBootDevice,
+			wantParam:  "disk",
+			wantEFI:    true,
+		},
+		{
+			name:           "persistent legacy cdrom boot",
+			flags:          &powerActionFlags{bootDevice: "CDROM", bootPersistent: true, bootMode: "legacy"},
+			wantAction:     rctypes.SetNextBootDevice,
+			wantParam:      "cdrom",
+			wantPersistent: true,
+		},
+		{
+			name:    "persistent bios setup",
+			flags:   &powerActionFlags{bootDevice: "bios", bootPersistent: true},
+			wantErr: errUnsupportedBoot,
+		},
+		{
+			name:    "invalid boot device",
+			flags:   &powerActionFlags{bootDevice: "floppy"},
+			wantErr: errInvalidBootDevice,
+		},
+		{
+			name:    "invalid boot mode",
+			flags:   &powerActionFlags{bootDevice: "pxe", bootMode: "bios"},
+			wantErr: errInvalidBootMode,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			params, err := paramsFromFlags(serverID, tc.flags)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, serverID, params.AssetID)
+			assert.Equal(t, tc.wantAction, params.Action)
+			assert.Equal(t, tc.wantParam, params.ActionParameter)
+			assert.Equal(t, tc.wantPersistent, params.SetNextBootDevicePersistent)
+			assert.Equal(t, tc.wantEFI, params.SetNextBootDeviceEFI)
+		})
+	}
+}
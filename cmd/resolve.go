@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"context"
+	"log"
+
+	"github.com/google/uuid"
+
+	"github.com/metal-toolbox/mctl/internal/app"
+	"github.com/metal-toolbox/mctl/internal/fleetdb"
+)
+
+// ResolveServerID returns the ID of the server referenced by a server UUID, hostname, chassis serial,
+// BMC IP address or BMC/AOC MAC address. fleetdb is only queried when the reference is not a UUID.
+func ResolveServerID(ctx context.Context, theApp *app.App, ref string) (uuid.UUID, error) {
+	if id, err := uuid.Parse(ref); err == nil {
+		return id, nil
+	}
+
+	client, err := app.NewFleetDBAPIClient(ctx, theApp.Config.FleetDBAPI, theApp.Reauth)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return fleetdb.ResolveServer(ctx, client, ref)
+}
+
+// MustResolveServerID returns the ID of the referenced server, see ResolveServerID.
+func MustResolveServerID(ctx context.Context, theApp *app.App, ref string) uuid.UUID {
+	id, err := ResolveServerID(ctx, theApp, ref)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return id
+}
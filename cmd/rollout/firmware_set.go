@@ -0,0 +1,677 @@
+package rollout
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	coclient "github.com/metal-toolbox/conditionorc/pkg/api/v1/conditions/client"
+	coapiv1 "github.com/metal-toolbox/conditionorc/pkg/api/v1/conditions/types"
+	fleetdbapi "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	rt "github.com/metal-toolbox/rivets/v2/types"
+
+	mctl "github.com/metal-toolbox/mctl/cmd"
+	"github.com/metal-toolbox/mctl/internal/app"
+	"github.com/metal-toolbox/mctl/internal/fleetdb"
+	"github.com/metal-toolbox/mctl/internal/lockfile"
+	"github.com/metal-toolbox/mctl/internal/rollout"
+)
+
+type rolloutFirmwareSetFlags struct {
+	vendor                string
+	model                 string
+	facility              string
+	firmwareSetID         string
+	labels                map[string]string
+	waves                 []string
+	maxFailureRate        float64
+	pollInterval          time.Duration
+	waveTimeout           time.Duration
+	resume                string
+	skipBMCReset          bool
+	requireHostPoweredOff bool
+	dryRun                bool
+	confirm               mctl.ConfirmParams
+}
+
+const (
+	// maxServerPages limits the number of pages of servers selected for a rollout.
+	maxServerPages = 20
+
+	// selectTimeout bounds the fleetdb queries to select the servers and firmware set.
+	selectTimeout = 5 * time.Minute
+)
+
+var (
+	flagsDefinedRolloutFwSet *rolloutFirmwareSetFlags
+
+	errRolloutHalted = errors.New("rollout halted")
+)
+
+var rolloutFirmwareSet = &cobra.Command{
+	Use:   "firmware-set",
+	Short: "Install a firmware set on servers in waves",
+	Long: `Install a firmware set on the servers of a vendor, model in waves. The installs of a wave are
+started together and the next wave starts when the FirmwareInstall conditions of the wave have completed.
+An install whose condition is no longer reported is checked against the server firmware inventory.
+
+Wave sizes are a number of servers, a percentage of the selected servers or 'rest' for the remaining
+servers. The rollout halts when the failed installs of a wave exceed --max-failure-rate percent, or the
+wave does not complete within --wave-timeout.
+
+The rollout state is saved locally after each change, an interrupted or halted rollout continues from
+where it stopped with --resume, the failed installs of a halted wave are retried. A rollout is locked
+while it runs, it can't be resumed by another process at the same time.`,
+	Example: `  # install the latest, default firmware set on one canary, then 5%, 25% and the rest of the servers
+  mctl rollout firmware-set --vendor dell --model r6515 --allow-bulk
+
+  # print the waves without installing
+  mctl rollout firmware-set --vendor dell --model r6515 --waves 2,50%,rest --dry-run -o text
+
+  # resume an interrupted or halted rollout
+  mctl rollout firmware-set --resume 0b4c3f1e-9d2a-4f6b-8e1c-2a7d5b9c4e3f`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		rolloutFwSet(cmd.Context())
+	},
+}
+
+func rolloutFwSet(ctx context.Context) {
+	theApp := mctl.MustCreateApp(ctx)
+	store := rollout.NewStore(rollout.DefaultDir())
+
+	var (
+		r    *rollout.Rollout
+		lock *lockfile.Lock
+		err  error
+	)
+
+	if flagsDefinedRolloutFwSet.resume != "" {
+		r, lock, err = resumeRollout(store, flagsDefinedRolloutFwSet.resume)
+		if err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		r, err = newRollout(ctx, theApp, flagsDefinedRolloutFwSet)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if flagsDefinedRolloutFwSet.dryRun {
+			printRollout(output, r)
+			return
+		}
+
+		op := &mctl.Destructive{
+			Action: "rollout firmware-set " + r.FirmwareSetID.String(),
+			Header: []string{"Wave", "ID", "Name"},
+			Rows:   rolloutRows(r),
+		}
+
+		if err = mctl.ConfirmDestructive(theApp, op, &flagsDefinedRolloutFwSet.confirm); err != nil {
+			log.Fatal(err)
+		}
+
+		if lock, err = store.Lock(r.ID); err != nil {
+			log.Fatal(err)
+		}
+
+		if err = store.Save(r); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	defer lock.Release()
+
+	log.Printf("rollout %s, resume with: mctl rollout firmware-set --resume %s", r.ID, r.ID)
+
+	client, err := app.NewConditionsClient(ctx, theApp.Config.Conditions, theApp.Reauth)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fleetDBClient, err := app.NewFleetDBAPIClient(ctx, theApp.Config.FleetDBAPI, theApp.Reauth)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = runRollout(ctx, theApp, client, fleetDBClient, store, r)
+
+	printRollout(output, r)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// newRollout returns a rollout of the firmware set on the servers selected by the flags.
+func newRollout(ctx context.Context, theApp *app.App, flgs *rolloutFirmwareSetFlags) (*rollout.Rollout, error) {
+	ctx, cancel := context.WithTimeout(ctx, selectTimeout)
+	defer cancel()
+
+	client, err := app.NewFleetDBAPIClient(ctx, theApp.Config.FleetDBAPI, theApp.Reauth)
+	if err != nil {
+		return nil, err
+	}
+
+	setID, err := rolloutFirmwareSetID(ctx, client, flgs)
+	if err != nil {
+		return nil, err
+	}
+
+	servers, err := serversBySelector(ctx, client, flgs.vendor, flgs.model, flgs.facility)
+	if err != nil {
+		return nil, err
+	}
+
+	selector := map[string]string{"vendor": flgs.vendor, "model": flgs.model}
+	if flgs.facility != "" {
+		selector["facility"] = flgs.facility
+	}
+
+	r, err := rollout.New(setID, mctl.FormatLabels(selector), servers, flgs.waves, flgs.maxFailureRate)
+	if err != nil {
+		return nil, err
+	}
+
+	r.SkipBMCReset = flgs.skipBMCReset
+	r.RequireHostPoweredOff = flgs.requireHostPoweredOff
+
+	return r, nil
+}
+
+// rolloutFirmwareSetID returns the ID of the given firmware set, or the set for the vendor, model.
+func rolloutFirmwareSetID(ctx context.Context, client *fleetdbapi.Client, flgs *rolloutFirmwareSetFlags) (uuid.UUID, error) {
+	if flgs.firmwareSetID == "" {
+		set, err := mctl.FirmwareSetByVendorModel(ctx, client, flgs.vendor, flgs.model, flgs.labels)
+		if err != nil {
+			return uuid.Nil, errors.Wrap(err, "specify a firmware set ID with --set-id instead")
+		}
+
+		return set.UUID, nil
+	}
+
+	id, err := uuid.Parse(flgs.firmwareSetID)
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, "invalid firmware set ID")
+	}
+
+	if _, _, err := client.GetServerComponentFirmwareSet(ctx, id); err != nil {
+		return uuid.Nil, errors.Wrap(err, "invalid firmware set ID")
+	}
+
+	return id, nil
+}
+
+// serversBySelector returns the servers of the vendor, model, in the facility when one is given.
+func serversBySelector(ctx context.Context, client *fleetdbapi.Client, vendor, model, facility string) ([]rollout.Target, error) {
+	alp := []fleetdbapi.AttributeListParams{
+		{
+			Namespace: fleetdb.ServerVendorAttributeNS,
+			Keys:      []string{"vendor"},
+			Operator:  "eq",
+			Value:     strings.ToLower(vendor),
+		},
+		{
+			Namespace: fleetdb.ServerVendorAttributeNS,
+			Keys:      []string{"model"},
+			Operator:  "eq",
+			Value:     strings.ToLower(model),
+		},
+	}
+
+	targets := []rollout.Target{}
+
+	for page := 1; page <= maxServerPages; page++ {
+		params := &fleetdbapi.ServerListParams{
+			FacilityCode:        facility,
+			AttributeListParams: alp,
+			PaginationParams: &fleetdbapi.PaginationParams{
+				Limit: fleetdbapi.MaxPaginationSize,
+				Page:  page,
+			},
+		}
+
+		found, resp, err := client.List(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+
+		for idx := range found {
+			targets = append(targets, rollout.Target{ServerID: found[idx].UUID, Name: found[idx].Name})
+		}
+
+		if resp == nil || page >= resp.TotalPages {
+			break
+		}
+	}
+
+	return targets, nil
+}
+
+// resumeRollout locks and loads the state of the rollout, the failed installs of a halted wave are reset to be retried.
+func resumeRollout(store *rollout.Store, rolloutID string) (*rollout.Rollout, *lockfile.Lock, error) {
+	id, err := uuid.Parse(rolloutID)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "invalid rollout ID")
+	}
+
+	lock, err := store.Lock(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r, err := store.Load(id)
+	if err != nil {
+		lock.Release()
+		return nil, nil, err
+	}
+
+	if idx := r.CurrentWave(); r.Status == rollout.StatusHalted && idx != -1 {
+		if retried := r.Waves[idx].RetryFailed(); retried > 0 {
+			log.Printf("wave %d/%d: retrying %d failed install(s)", idx+1, len(r.Waves), retried)
+		}
+	}
+
+	r.Status = rollout.StatusRunning
+
+	return r, lock, nil
+}
+
+// runRollout installs the firmware set on the waves of the rollout in order, waiting for each wave
+// to complete. The rollout is halted when a wave has too many failed installs or times out.
+func runRollout(
+	ctx context.Context,
+	theApp *app.App,
+	client *coclient.Client,
+	fleetDBClient *fleetdbapi.Client,
+	store *rollout.Store,
+	r *rollout.Rollout,
+) error {
+	for idx := r.CurrentWave(); idx != -1; idx = r.CurrentWave() {
+		wave := r.Waves[idx]
+
+		log.Printf("wave %d/%d: installing on %d server(s)", idx+1, len(r.Waves), len(wave.Targets))
+
+		if err := installWave(ctx, theApp, client, store, r, wave); err != nil {
+			return err
+		}
+
+		if err := waitWave(ctx, client, fleetDBClient, store, r, idx); err != nil {
+			if !errors.Is(err, errRolloutHalted) {
+				return err
+			}
+
+			r.Status = rollout.StatusHalted
+			if saveErr := store.Save(r); saveErr != nil {
+				return saveErr
+			}
+
+			return errors.Wrap(err, "resume with: mctl rollout firmware-set --resume "+r.ID.String())
+		}
+
+		log.Printf("wave %d/%d: completed, failure rate: %.1f%%", idx+1, len(r.Waves), wave.FailureRate())
+	}
+
+	r.Status = rollout.StatusCompleted
+
+	return store.Save(r)
+}
+
+// installWave creates the firmware install conditions for the pending servers of the wave,
+// servers where the condition could not be created are marked failed.
+func installWave(
+	ctx context.Context,
+	theApp *app.App,
+	client *coclient.Client,
+	store *rollout.Store,
+	r *rollout.Rollout,
+	wave *rollout.Wave,
+) error {
+	if wave.StartedAt == nil {
+		now := time.Now()
+		wave.StartedAt = &now
+	}
+
+	for _, target := range wave.Targets {
+		if target.State != rollout.TargetPending {
+			continue
+		}
+
+		params := &rctypes.FirmwareInstallTaskParameters{
+			AssetID:               target.ServerID,
+			FirmwareSetID:         r.FirmwareSetID,
+			ResetBMCBeforeInstall: !r.SkipBMCReset,
+			RequireHostPoweredOff: r.RequireHostPoweredOff,
+		}
+
+		conditionID, err := installFirmwareSet(ctx, theApp, client, params)
+		if err != nil {
+			log.Printf("server %s: firmware install error: %s", target.ServerID, err.Error())
+
+			target.State = rollout.TargetFailed
+			target.Message = err.Error()
+		} else {
+			target.State = rollout.TargetActive
+			target.ConditionID = conditionID
+		}
+
+		if err := store.Save(r); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func installFirmwareSet(
+	ctx context.Context,
+	theApp *app.App,
+	client *coclient.Client,
+	params *rctypes.FirmwareInstallTaskParameters,
+) (uuid.UUID, error) {
+	ctx, cancel := context.WithTimeout(ctx, mctl.CmdTimeout)
+	defer cancel()
+
+	response, err := client.ServerFirmwareInstall(ctx, params)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	condition, err := mctl.ConditionFromResponse(response)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	mctl.AuditCondition(theApp, params.AssetID, response, condition.ID)
+
+	return condition.ID, nil
+}
+
+// waitWave polls the conditions of the active installs in the wave until all installs have completed,
+// errRolloutHalted is returned when the failed installs exceed the rollout maximum or the wave timed out.
+func waitWave(
+	ctx context.Context,
+	client *coclient.Client,
+	fleetDBClient *fleetdbapi.Client,
+	store *rollout.Store,
+	r *rollout.Rollout,
+	idx int,
+) error {
+	wave := r.Waves[idx]
+	deadline := time.Now().Add(flagsDefinedRolloutFwSet.waveTimeout)
+
+	for {
+		changed := false
+
+		for _, target := range wave.Targets {
+			if target.State == rollout.TargetActive && updateTarget(ctx, client, fleetDBClient, r.FirmwareSetID, target) {
+				changed = true
+			}
+		}
+
+		if changed {
+			if err := store.Save(r); err != nil {
+				return err
+			}
+		}
+
+		// the failure rate is of all servers in the wave, once exceeded it can't recover
+		if r.Exceeded(wave) {
+			return errors.Wrap(
+				errRolloutHalted,
+				fmt.Sprintf("wave %d/%d: failure rate %.1f%% exceeds %.1f%%", idx+1, len(r.Waves), wave.FailureRate(), r.MaxFailureRate),
+			)
+		}
+
+		if wave.Done() {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return errors.Wrap(
+				errRolloutHalted,
+				fmt.Sprintf("wave %d/%d: installs did not complete within %s", idx+1, len(r.Waves), flagsDefinedRolloutFwSet.waveTimeout),
+			)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(flagsDefinedRolloutFwSet.pollInterval):
+		}
+	}
+}
+
+// updateTarget updates the state of the server install from its condition, returning true when it changed.
+//
+// The conditions API stops reporting the condition of a server once it has finished and was
+// replaced or purged, the install is then confirmed from the server firmware inventory.
+func updateTarget(
+	ctx context.Context,
+	client *coclient.Client,
+	fleetDBClient *fleetdbapi.Client,
+	setID uuid.UUID,
+	target *rollout.Target,
+) bool {
+	ctx, cancel := context.WithTimeout(ctx, mctl.CmdTimeout)
+	defer cancel()
+
+	response, err := client.ServerConditionStatus(ctx, target.ServerID)
+	if err != nil {
+		// the condition is checked again on the next poll
+		log.Printf("server %s: condition status error: %s", target.ServerID, err.Error())
+		return false
+	}
+
+	message := "firmware install failed, see: mctl install status --server " + target.ServerID.String()
+
+	state, ok := conditionState(response, target.ConditionID)
+	if !ok {
+		plan, err := serverInstallPlan(ctx, fleetDBClient, setID, target.ServerID)
+		if err != nil {
+			// the inventory is checked again on the next poll
+			log.Printf("server %s: condition %s not found, firmware inventory error: %s", target.ServerID, target.ConditionID, err.Error())
+			return false
+		}
+
+		state, message = inventoryState(plan)
+	}
+
+	if state == target.State {
+		return false
+	}
+
+	target.State = state
+
+	if state == rollout.TargetFailed {
+		target.Message = message
+		log.Printf("server %s: %s", target.ServerID, target.Message)
+	}
+
+	return true
+}
+
+// serverInstallPlan returns the changes installing the firmware set would make to the server components.
+func serverInstallPlan(
+	ctx context.Context,
+	client *fleetdbapi.Client,
+	setID, serverID uuid.UUID,
+) ([]fleetdb.InstallPlanItem, error) {
+	set, _, err := client.GetServerComponentFirmwareSet(ctx, setID)
+	if err != nil {
+		return nil, err
+	}
+
+	server, _, err := client.Get(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, serverModel := mctl.VendorModelFromAttrs(server.Attributes)
+
+	records, err := mctl.ServerComponents(ctx, client, serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	components := make([]*rt.Component, 0, len(records))
+
+	for idx := range records {
+		component, err := fleetdb.RecordToComponent(&records[idx])
+		if err != nil {
+			return nil, err
+		}
+
+		components = append(components, component)
+	}
+
+	return fleetdb.PlanFirmwareInstall(components, set.ComponentFirmware, serverModel, false), nil
+}
+
+// inventoryState returns the install state of a server whose install condition has finished, the install
+// failed when the plan still changes a component. Only components with set firmware for their vendor and
+// a known installed version count, the install can't be confirmed for the others.
+func inventoryState(plan []fleetdb.InstallPlanItem) (rollout.TargetState, string) {
+	pending := []string{}
+
+	for _, item := range plan {
+		if item.Installed == "" || item.FirmwareID == "" {
+			continue
+		}
+
+		if item.Action == fleetdb.InstallUpgrade || item.Action == fleetdb.InstallDowngrade {
+			pending = append(pending, item.Component)
+		}
+	}
+
+	if len(pending) > 0 {
+		return rollout.TargetFailed, "install condition finished, firmware set not installed on: " + strings.Join(pending, ", ")
+	}
+
+	return rollout.TargetSucceeded, ""
+}
+
+// conditionState returns the install state for the FirmwareInstall condition with the ID in the response,
+// false is returned when the response does not include the condition.
+func conditionState(response *coapiv1.ServerResponse, conditionID uuid.UUID) (rollout.TargetState, bool) {
+	if response == nil || response.Records == nil {
+		return "", false
+	}
+
+	for _, condition := range response.Records.Conditions {
+		if condition == nil || condition.ID != conditionID || condition.Kind != rctypes.FirmwareInstall {
+			continue
+		}
+
+		switch condition.State {
+		case rctypes.Succeeded:
+			return rollout.TargetSucceeded, true
+		case rctypes.Failed:
+			return rollout.TargetFailed, true
+		default:
+			return rollout.TargetActive, true
+		}
+	}
+
+	return "", false
+}
+
+func rolloutRows(r *rollout.Rollout) [][]string {
+	rows := [][]string{}
+
+	for idx, wave := range r.Waves {
+		for _, target := range wave.Targets {
+			rows = append(rows, []string{strconv.Itoa(idx + 1), target.ServerID.String(), target.Name})
+		}
+	}
+
+	return rows
+}
+
+func printRollout(format string, r *rollout.Rollout) {
+	if format == mctl.OutputTypeJSON.String() {
+		b, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Println(string(b))
+
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Wave", "ID", "Name", "State", "Condition", "Message"})
+	table.SetAutoWrapText(false)
+
+	for idx, wave := range r.Waves {
+		for _, target := range wave.Targets {
+			condition := ""
+			if target.ConditionID != uuid.Nil {
+				condition = target.ConditionID.String()
+			}
+
+			table.Append([]string{
+				strconv.Itoa(idx + 1),
+				target.ServerID.String(),
+				target.Name,
+				string(target.State),
+				condition,
+				target.Message,
+			})
+		}
+	}
+
+	table.Render()
+
+	fmt.Printf("rollout %s: %s, firmware set: %s, servers: %s\n", r.ID, r.Status, r.FirmwareSetID, r.Selector)
+}
+
+func init() {
+	flagsDefinedRolloutFwSet = &rolloutFirmwareSetFlags{}
+
+	mctl.AddVendorFlag(rolloutFirmwareSet, &flagsDefinedRolloutFwSet.vendor)
+	mctl.AddModelFlag(rolloutFirmwareSet, &flagsDefinedRolloutFwSet.model)
+	mctl.AddFacilityFlag(rolloutFirmwareSet, &flagsDefinedRolloutFwSet.facility)
+	mctl.AddFirmwareSetFlag(rolloutFirmwareSet, &flagsDefinedRolloutFwSet.firmwareSetID)
+	mctl.AddLabelsFlag(rolloutFirmwareSet, &flagsDefinedRolloutFwSet.labels,
+		"Labels to select the firmware set for the vendor, model instead of default=true,latest=true - 'channel=canary'")
+	mctl.AddWavesFlag(rolloutFirmwareSet, &flagsDefinedRolloutFwSet.waves)
+	mctl.AddMaxFailureRateFlag(rolloutFirmwareSet, &flagsDefinedRolloutFwSet.maxFailureRate)
+	mctl.AddPollIntervalFlag(rolloutFirmwareSet, &flagsDefinedRolloutFwSet.pollInterval, "interval between condition status checks")
+	mctl.AddWaveTimeoutFlag(rolloutFirmwareSet, &flagsDefinedRolloutFwSet.waveTimeout)
+	mctl.AddResumeFlag(rolloutFirmwareSet, &flagsDefinedRolloutFwSet.resume)
+	mctl.AddSkipBmcResetFlag(rolloutFirmwareSet, &flagsDefinedRolloutFwSet.skipBMCReset)
+	mctl.AddPowerOffRequiredFlag(rolloutFirmwareSet, &flagsDefinedRolloutFwSet.requireHostPoweredOff,
+		"require hosts to be powered off before proceeding install")
+	mctl.AddDryRunFlag(rolloutFirmwareSet, &flagsDefinedRolloutFwSet.dryRun, "print the rollout waves without installing")
+	mctl.AddConfirmFlags(rolloutFirmwareSet, &flagsDefinedRolloutFwSet.confirm)
+
+	mctl.RequireFlagsTogether(rolloutFirmwareSet, mctl.VendorFlag, mctl.ModelFlag)
+	mctl.RequireOneFlag(rolloutFirmwareSet, mctl.VendorFlag, mctl.ResumeFlag)
+	mctl.MutuallyExclusiveFlags(rolloutFirmwareSet, mctl.FirmwareSetFlag, mctl.LabelsFlag)
+
+	// a resumed rollout uses the servers, waves and install options it was started with
+	mctl.ExclusiveWithFlag(rolloutFirmwareSet, mctl.ResumeFlag,
+		mctl.VendorFlag,
+		mctl.FacilityFlag,
+		mctl.FirmwareSetFlag,
+		mctl.LabelsFlag,
+		mctl.WavesFlag,
+		mctl.MaxFailureRateFlag,
+		mctl.SkipBmcResetFlag,
+		mctl.PowerOffRequiredFlag,
+		mctl.DryRunFlag,
+	)
+}
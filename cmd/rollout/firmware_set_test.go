@@ -0,0 +1,86 @@
+package rollout
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	coapiv1 "github.com/metal-toolbox/conditionorc/pkg/api/v1/conditions/types"
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metal-toolbox/mctl/internal/fleetdb"
+	"github.com/metal-toolbox/mctl/internal/rollout"
+)
+
+func TestConditionState(t *testing.T) {
+	conditionID := uuid.New()
+
+	response := func(conditions ...*rctypes.Condition) *coapiv1.ServerResponse {
+		return &coapiv1.ServerResponse{Records: &coapiv1.ConditionsResponse{Conditions: conditions}}
+	}
+
+	testcases := []struct {
+		name     string
+		response *coapiv1.ServerResponse
+		want     rollout.TargetState
+		wantOK   bool
+	}{
+		{
+			name:     "succeeded",
+			response: response(&rctypes.Condition{ID: conditionID, Kind: rctypes.FirmwareInstall, State: rctypes.Succeeded}),
+			want:     rollout.TargetSucceeded,
+			wantOK:   true,
+		},
+		{
+			name:     "failed",
+			response: response(&rctypes.Condition{ID: conditionID, Kind: rctypes.FirmwareInstall, State: rctypes.Failed}),
+			want:     rollout.TargetFailed,
+			wantOK:   true,
+		},
+		{
+			name:     "pending",
+			response: response(&rctypes.Condition{ID: conditionID, Kind: rctypes.FirmwareInstall, State: rctypes.Pending}),
+			want:     rollout.TargetActive,
+			wantOK:   true,
+		},
+		{
+			name:     "other condition",
+			response: response(&rctypes.Condition{ID: uuid.New(), Kind: rctypes.FirmwareInstall, State: rctypes.Failed}),
+		},
+		{
+			name:     "no records",
+			response: &coapiv1.ServerResponse{},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := conditionState(tc.response, conditionID)
+			assert.Equal(t, tc.wantOK, ok)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestInventoryState(t *testing.T) {
+	installed := []fleetdb.InstallPlanItem{
+		{Component: "bios", Installed: "2.1.0", Target: "2.1.0", FirmwareID: uuid.NewString(), Action: fleetdb.InstallSkip},
+		{Component: "nic", Target: "22.1", FirmwareID: uuid.NewString(), Action: fleetdb.InstallSkip, Reason: "no matching component on the server"},
+		{Component: "cpld", Target: "1.0.6", FirmwareID: uuid.NewString(), Action: fleetdb.InstallUnknown},
+		{Component: "drive", Installed: "D3MU001", Action: fleetdb.InstallSkip, Reason: "no firmware in set"},
+	}
+
+	state, message := inventoryState(installed)
+	assert.Equal(t, rollout.TargetSucceeded, state)
+	assert.Empty(t, message)
+
+	pending := []fleetdb.InstallPlanItem{
+		installed[0],
+		{Component: "bmc", Installed: "6.10", Target: "7.00", FirmwareID: uuid.NewString(), Action: fleetdb.InstallUpgrade},
+		{Component: "drive", Installed: "B2", Target: "A1", FirmwareID: uuid.NewString(), Action: fleetdb.InstallDowngrade},
+	}
+
+	state, message = inventoryState(pending)
+	assert.Equal(t, rollout.TargetFailed, state)
+	assert.Contains(t, message, "bmc, drive")
+}
@@ -0,0 +1,26 @@
+package rollout
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/metal-toolbox/mctl/cmd"
+)
+
+var (
+	output string
+)
+
+var rolloutCmd = &cobra.Command{
+	Use:   "rollout",
+	Short: "Staged rollouts to groups of servers",
+	Run: func(cmd *cobra.Command, _ []string) {
+		_ = cmd.Help()
+	},
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(rolloutCmd)
+	rolloutCmd.AddCommand(rolloutFirmwareSet)
+
+	cmd.AddOutputFlag(rolloutCmd, &output)
+}
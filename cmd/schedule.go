@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+
+	"github.com/metal-toolbox/mctl/internal/app"
+	"github.com/metal-toolbox/mctl/internal/audit"
+	"github.com/metal-toolbox/mctl/internal/scheduler"
+	"github.com/metal-toolbox/mctl/pkg/model"
+)
+
+var (
+	ErrAt       = errors.New("invalid --at value, expected a duration like 2h, a local time like 22:30 or 2006-01-02 22:30, or an RFC3339 time")
+	ErrSchedule = errors.New("unable to schedule condition")
+)
+
+// ScheduleParams holds the flag values to submit a condition at a later time.
+type ScheduleParams struct {
+	// At is when the condition is submitted.
+	At string
+	// Window is the time after At the condition may still be submitted.
+	Window time.Duration
+}
+
+// Scheduled returns true when scheduling flags were given.
+func (p *ScheduleParams) Scheduled() bool {
+	return p.At != "" || p.Window > 0
+}
+
+// ParseAt returns the time for a duration after now, an RFC3339 time, a local date and time,
+// or the next occurrence of a local time of day.
+func ParseAt(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		if d < 0 {
+			return time.Time{}, errors.Wrap(ErrAt, value)
+		}
+
+		return now.Add(d), nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02 15:04", value, now.Location()); err == nil {
+		return t, nil
+	}
+
+	if t, err := time.ParseInLocation("15:04", value, now.Location()); err == nil {
+		next := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location())
+		if !next.After(now) {
+			next = next.AddDate(0, 0, 1)
+		}
+
+		return next, nil
+	}
+
+	return time.Time{}, errors.Wrap(ErrAt, value)
+}
+
+// ScheduleCondition stores a job to create the condition on the server at the time given by the
+// schedule flags, the job is submitted by 'mctl scheduler run'.
+func ScheduleCondition(
+	theApp *app.App,
+	params *ScheduleParams,
+	description string,
+	serverID uuid.UUID,
+	kind rctypes.Kind,
+	parameters any,
+) (*scheduler.Job, error) {
+	if params.At == "" {
+		return nil, errors.Wrap(ErrSchedule, "--window requires --at")
+	}
+
+	at, err := ParseAt(params.At, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := scheduler.NewJob(description, serverID, kind, parameters, at, params.Window)
+	if err != nil {
+		return nil, err
+	}
+
+	job.User = localUser()
+
+	if err := scheduler.NewStore(scheduler.DefaultDir()).Save(job); err != nil {
+		return nil, err
+	}
+
+	AuditLog(theApp, model.ConditionsAPI, []string{serverID.String()}, audit.Result{
+		Target:  serverID.String(),
+		ID:      job.ID.String(),
+		Message: "scheduled at " + at.Format(time.RFC3339),
+	})
+
+	return job, nil
+}
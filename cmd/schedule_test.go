@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAt(t *testing.T) {
+	now := time.Date(2024, 5, 10, 18, 30, 0, 0, time.UTC)
+
+	testcases := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "2h", want: now.Add(2 * time.Hour)},
+		{value: "2024-05-11T02:00:00Z", want: time.Date(2024, 5, 11, 2, 0, 0, 0, time.UTC)},
+		{value: "2024-05-11 02:00", want: time.Date(2024, 5, 11, 2, 0, 0, 0, time.UTC)},
+		{value: "22:00", want: time.Date(2024, 5, 10, 22, 0, 0, 0, time.UTC)},
+		{value: "02:00", want: time.Date(2024, 5, 11, 2, 0, 0, 0, time.UTC)},
+		{value: "18:30", want: time.Date(2024, 5, 11, 18, 30, 0, 0, time.UTC)},
+		{value: "-1h", wantErr: true},
+		{value: "tonight", wantErr: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.value, func(t *testing.T) {
+			got, err := ParseAt(tc.value, now)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, ErrAt)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
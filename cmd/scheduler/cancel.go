@@ -0,0 +1,43 @@
+package scheduler
+
+import (
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	mctl "github.com/metal-toolbox/mctl/cmd"
+	"github.com/metal-toolbox/mctl/internal/scheduler"
+)
+
+var (
+	jobID string
+)
+
+var schedulerCancel = &cobra.Command{
+	Use:   "cancel",
+	Short: "Cancel a pending scheduled job",
+	Run: func(_ *cobra.Command, _ []string) {
+		cancelJob()
+	},
+}
+
+func cancelJob() {
+	id, err := uuid.Parse(jobID)
+	if err != nil {
+		log.Fatal(errors.Wrap(err, "invalid job ID"))
+	}
+
+	job, err := scheduler.NewStore(scheduler.DefaultDir()).Cancel(id)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("cancelled jobID=%s serverID=%s %s", job.ID, job.ServerID, job.Description)
+}
+
+func init() {
+	mctl.AddJobIDFlag(schedulerCancel, &jobID)
+	mctl.RequireFlag(schedulerCancel, mctl.JobIDFlag)
+}
@@ -0,0 +1,72 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	mctl "github.com/metal-toolbox/mctl/cmd"
+	"github.com/metal-toolbox/mctl/internal/scheduler"
+)
+
+var schedulerList = &cobra.Command{
+	Use:   "list",
+	Short: "List the scheduled jobs on this host",
+	Run: func(cmd *cobra.Command, _ []string) {
+		listJobs(cmd.Context())
+	},
+}
+
+func listJobs(_ context.Context) {
+	jobs, err := scheduler.NewStore(scheduler.DefaultDir()).List()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if output == mctl.OutputTypeJSON.String() {
+		b, err := json.MarshalIndent(jobs, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Println(string(b))
+
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"ID", "Server", "Description", "At", "Until", "Status", "Condition", "Message"})
+	table.SetAutoWrapText(false)
+
+	for _, job := range jobs {
+		until := ""
+		if job.Until != nil {
+			until = job.Until.Local().Format(time.DateTime)
+		}
+
+		condition := ""
+		if job.ConditionID != uuid.Nil {
+			condition = job.ConditionID.String()
+		}
+
+		table.Append([]string{
+			job.ID.String(),
+			job.ServerID.String(),
+			job.Description,
+			job.At.Local().Format(time.DateTime),
+			until,
+			string(job.Status),
+			condition,
+			job.Message,
+		})
+	}
+
+	table.Render()
+}
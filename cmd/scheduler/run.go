@@ -0,0 +1,227 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	coclient "github.com/metal-toolbox/conditionorc/pkg/api/v1/conditions/client"
+	coapiv1 "github.com/metal-toolbox/conditionorc/pkg/api/v1/conditions/types"
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+
+	mctl "github.com/metal-toolbox/mctl/cmd"
+	"github.com/metal-toolbox/mctl/internal/app"
+	"github.com/metal-toolbox/mctl/internal/scheduler"
+)
+
+type schedulerRunFlags struct {
+	pollInterval time.Duration
+	once         bool
+}
+
+var (
+	flagsDefinedSchedulerRun *schedulerRunFlags
+)
+
+var schedulerRun = &cobra.Command{
+	Use:   "run",
+	Short: "Submit scheduled conditions when they are due",
+	Long: `Run the scheduler, which submits the conditions scheduled with --at on this host once they are due.
+Jobs not submitted before the end of their --window expire. The scheduler runs until interrupted,
+keep it running through the maintenance window - in a tmux session or as a systemd user service.
+
+A job is submitted again on the next check when the request fails or conditionorc returns a server error,
+a job conditionorc rejects fails.
+
+Schedulers on the same host take turns submitting due jobs. A job is saved as submitting before its
+condition is created, a job left submitting by an interrupted scheduler is not submitted again, check
+the server conditions before scheduling it again.`,
+	Example: `  # submit due jobs every minute
+  mctl scheduler run --poll-interval 1m
+
+  # submit the jobs due now and exit, to run from cron
+  mctl scheduler run --once`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		runScheduler(cmd.Context())
+	},
+}
+
+func runScheduler(ctx context.Context) {
+	theApp := mctl.MustCreateApp(ctx)
+	store := scheduler.NewStore(scheduler.DefaultDir())
+
+	var client *coclient.Client
+
+	submit := func(ctx context.Context, job *scheduler.Job) error {
+		// the client is created on the first due job so an idle scheduler does not authenticate
+		if client == nil {
+			var err error
+
+			client, err = app.NewConditionsClient(ctx, theApp.Config.Conditions, theApp.Reauth)
+			if err != nil {
+				return err
+			}
+		}
+
+		return submitJob(ctx, theApp, client, job)
+	}
+
+	for {
+		if err := runDueJobs(ctx, store, time.Now(), submit); err != nil {
+			if flagsDefinedSchedulerRun.once {
+				log.Fatal(err)
+			}
+
+			log.Print(err)
+		}
+
+		if flagsDefinedSchedulerRun.once {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(flagsDefinedSchedulerRun.pollInterval):
+		}
+	}
+}
+
+// runDueJobs expires the pending jobs past their window and submits the jobs that are due,
+// the store is locked so concurrent schedulers don't submit a job twice.
+func runDueJobs(
+	ctx context.Context,
+	store *scheduler.Store,
+	now time.Time,
+	submit func(context.Context, *scheduler.Job) error,
+) error {
+	lock, err := store.Lock()
+	if err != nil {
+		return err
+	}
+
+	defer lock.Release()
+
+	jobs, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		if !job.Expired(now) && !job.Due(now) {
+			continue
+		}
+
+		// the job is loaded again so a job cancelled since it was listed is not submitted
+		job, err = store.Load(job.ID)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case job.Expired(now):
+			job.Status = scheduler.StatusExpired
+			job.Message = "not submitted before the end of the window " + job.Until.Format(time.RFC3339)
+
+			log.Printf("jobID=%s serverID=%s %s: expired", job.ID, job.ServerID, job.Description)
+		case job.Due(now):
+			// the job is saved as submitting first so it isn't submitted again when the scheduler is interrupted
+			job.Status = scheduler.StatusSubmitting
+			if err := store.Save(job); err != nil {
+				return err
+			}
+
+			if err := submit(ctx, job); err != nil {
+				// the condition was not created, the job is submitted on the next run
+				job.Status = scheduler.StatusPending
+				if saveErr := store.Save(job); saveErr != nil {
+					return saveErr
+				}
+
+				return err
+			}
+		default:
+			continue
+		}
+
+		if err := store.Save(job); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// submitJob creates the job condition and records the condition or the failure on the job,
+// an error is returned when the condition may be created on a retry.
+func submitJob(ctx context.Context, theApp *app.App, client *coclient.Client, job *scheduler.Job) error {
+	ctx, cancel := context.WithTimeout(ctx, mctl.CmdTimeout)
+	defer cancel()
+
+	var condition rctypes.Condition
+
+	response, err := createCondition(ctx, client, job)
+	if err == nil {
+		condition, err = mctl.ConditionFromResponse(response)
+	}
+
+	if err != nil {
+		log.Printf("jobID=%s serverID=%s %s: %s", job.ID, job.ServerID, job.Description, err)
+
+		if retrySubmit(response, err) {
+			return err
+		}
+
+		job.Status = scheduler.StatusFailed
+		job.Message = err.Error()
+
+		return nil
+	}
+
+	job.Status = scheduler.StatusSubmitted
+	job.ConditionID = condition.ID
+	job.Message = response.Message
+
+	log.Printf("jobID=%s serverID=%s %s: status=%d msg=%s conditionID=%s",
+		job.ID, job.ServerID, job.Description, response.StatusCode, response.Message, condition.ID)
+
+	mctl.AuditCondition(theApp, job.ServerID, response, condition.ID)
+
+	return nil
+}
+
+// retrySubmit returns true when the condition was not created because the request failed or
+// conditionorc returned a server error, conditions conditionorc rejects are not retried.
+func retrySubmit(response *coapiv1.ServerResponse, err error) bool {
+	if response == nil {
+		return !errors.Is(err, scheduler.ErrJob)
+	}
+
+	return response.StatusCode >= http.StatusInternalServerError
+}
+
+func createCondition(ctx context.Context, client *coclient.Client, job *scheduler.Job) (*coapiv1.ServerResponse, error) {
+	// firmware installs have their own endpoint which validates the firmware set
+	if job.Kind == rctypes.FirmwareInstall {
+		params := &rctypes.FirmwareInstallTaskParameters{}
+		if err := json.Unmarshal(job.Parameters, params); err != nil {
+			return nil, errors.Wrap(scheduler.ErrJob, "firmware install parameters: "+err.Error())
+		}
+
+		return client.ServerFirmwareInstall(ctx, params)
+	}
+
+	return client.ServerConditionCreate(ctx, job.ServerID, job.Kind, coapiv1.ConditionCreate{Parameters: job.Parameters})
+}
+
+func init() {
+	flagsDefinedSchedulerRun = &schedulerRunFlags{}
+
+	mctl.AddPollIntervalFlag(schedulerRun, &flagsDefinedSchedulerRun.pollInterval, "interval between checks for due jobs")
+	mctl.AddOnceFlag(schedulerRun, &flagsDefinedSchedulerRun.once, "submit the jobs due now and exit")
+}
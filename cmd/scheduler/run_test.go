@@ -0,0 +1,111 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	coapiv1 "github.com/metal-toolbox/conditionorc/pkg/api/v1/conditions/types"
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/metal-toolbox/mctl/internal/scheduler"
+)
+
+func TestRunDueJobs(t *testing.T) {
+	store := scheduler.NewStore(t.TempDir())
+	now := time.Now()
+
+	newJob := func(at time.Time, window time.Duration) *scheduler.Job {
+		job, err := scheduler.NewJob("power cycle", uuid.New(), rctypes.ServerControl, nil, at, window)
+		require.NoError(t, err)
+
+		return job
+	}
+
+	due := newJob(now.Add(time.Minute), 0)
+	later := newJob(now.Add(2*time.Hour), 0)
+	expired := newJob(now.Add(time.Minute), time.Minute)
+	cancelled := newJob(now.Add(time.Minute), 0)
+	cancelled.Status = scheduler.StatusCancelled
+
+	for _, job := range []*scheduler.Job{due, later, expired, cancelled} {
+		require.NoError(t, store.Save(job))
+	}
+
+	status := func(id uuid.UUID) scheduler.Status {
+		job, err := store.Load(id)
+		require.NoError(t, err)
+
+		return job.Status
+	}
+
+	submitted := []uuid.UUID{}
+	submit := func(_ context.Context, job *scheduler.Job) error {
+		assert.Equal(t, scheduler.StatusSubmitting, status(job.ID), "the job is saved before it's submitted")
+
+		submitted = append(submitted, job.ID)
+		job.Status = scheduler.StatusSubmitted
+
+		return nil
+	}
+
+	require.NoError(t, runDueJobs(context.Background(), store, now.Add(time.Hour), submit))
+	assert.Equal(t, []uuid.UUID{due.ID}, submitted)
+
+	assert.Equal(t, scheduler.StatusSubmitted, status(due.ID))
+	assert.Equal(t, scheduler.StatusPending, status(later.ID))
+	assert.Equal(t, scheduler.StatusExpired, status(expired.ID))
+	assert.Equal(t, scheduler.StatusCancelled, status(cancelled.ID))
+
+	// submitted jobs are not submitted again
+	require.NoError(t, runDueJobs(context.Background(), store, now.Add(time.Hour), submit))
+	assert.Len(t, submitted, 1)
+
+	// a job the scheduler was interrupted submitting is not submitted again
+	interrupted := newJob(now.Add(time.Minute), 0)
+	interrupted.Status = scheduler.StatusSubmitting
+	require.NoError(t, store.Save(interrupted))
+
+	require.NoError(t, runDueJobs(context.Background(), store, now.Add(time.Hour), submit))
+	assert.Len(t, submitted, 1)
+
+	// a job is pending again when it could not be submitted
+	retried := newJob(now.Add(time.Minute), 0)
+	require.NoError(t, store.Save(retried))
+
+	errSubmit := errors.New("no conditions client")
+	err := runDueJobs(context.Background(), store, now.Add(time.Hour), func(context.Context, *scheduler.Job) error {
+		return errSubmit
+	})
+	assert.ErrorIs(t, err, errSubmit)
+	assert.Equal(t, scheduler.StatusPending, status(retried.ID))
+}
+
+func TestRetrySubmit(t *testing.T) {
+	errTimeout := errors.New("context deadline exceeded")
+
+	testcases := []struct {
+		name     string
+		response *coapiv1.ServerResponse
+		err      error
+		want     bool
+	}{
+		{name: "request failed", err: errTimeout, want: true},
+		{name: "invalid parameters", err: fmt.Errorf("firmware install parameters: %w", scheduler.ErrJob), want: false},
+		{name: "server error", response: &coapiv1.ServerResponse{StatusCode: http.StatusServiceUnavailable}, err: errTimeout, want: true},
+		{name: "rejected", response: &coapiv1.ServerResponse{StatusCode: http.StatusConflict}, err: errTimeout, want: false},
+		{name: "no condition", response: &coapiv1.ServerResponse{StatusCode: http.StatusOK}, err: errTimeout, want: false},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, retrySubmit(tc.response, tc.err))
+		})
+	}
+}
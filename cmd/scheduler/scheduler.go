@@ -0,0 +1,28 @@
+package scheduler
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/metal-toolbox/mctl/cmd"
+)
+
+var (
+	output string
+)
+
+var schedulerCmd = &cobra.Command{
+	Use:   "scheduler",
+	Short: "Run and manage conditions scheduled with --at",
+	Run: func(cmd *cobra.Command, _ []string) {
+		_ = cmd.Help()
+	},
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(schedulerCmd)
+	schedulerCmd.AddCommand(schedulerRun)
+	schedulerCmd.AddCommand(schedulerList)
+	schedulerCmd.AddCommand(schedulerCancel)
+
+	cmd.AddOutputFlag(schedulerCmd, &output)
+}
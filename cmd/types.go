@@ -10,10 +10,32 @@ type FirmwareSetFlags struct {
 	AddFirmwareUUIDs []string
 	// list of firmware UUIDs to be removed from the set
 	RemoveFirmwareUUIDs []string
+	// replace all labels instead of merging them
+	ReplaceLabels bool
+	// label keys to be removed from the set
+	RemoveLabels []string
+	// component slugs mapped to the firmware UUID replacing the set firmware for the component
+	ReplaceComponents map[string]string
 	// name for the firmware set to be created/edited
 	FirmwareSetName string
 	// create firmware set from file
 	CreateFromFile string
 	// ignore any create errors
 	IgnoreCreateErrors bool
+	// print the requests instead of sending them
+	DryRun bool
+}
+
+// FirmwareFields are the firmware fields set with AddFirmwareFieldFlags
+type FirmwareFields struct {
+	Vendor        string
+	Models        []string
+	Component     string
+	Version       string
+	Filename      string
+	Checksum      string
+	UpstreamURL   string
+	RepositoryURL string
+	InstallInband bool
+	OEM           bool
 }
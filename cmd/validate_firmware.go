@@ -30,7 +30,7 @@ var validateFirmwareCmd = &cobra.Command{
 			log.Fatalf("creating app structure: %s", err.Error())
 		}
 
-		srvID, err := uuid.Parse(fwvFlags.srvIDStr)
+		srvID, err := ResolveServerID(c.Context(), theApp, fwvFlags.srvIDStr)
 		if err != nil {
 			log.Fatalf("parsing server id: %s", err.Error())
 		}
@@ -6,12 +6,10 @@ toolchain go1.23.1
 
 require (
 	github.com/adrg/xdg v0.5.3
-	github.com/bmc-toolbox/common v0.0.0-20240723142833-87832458b53b
 	github.com/coreos/go-oidc/v3 v3.14.1
 	github.com/davecgh/go-spew v1.1.2-0.20180830191138-d8f796af33cc
 	github.com/dustin/go-humanize v1.0.1
 	github.com/go-jose/go-jose/v4 v4.0.5
-	github.com/go-playground/validator v9.31.0+incompatible
 	github.com/google/uuid v1.6.0
 	github.com/metal-toolbox/bmc-common v1.0.3
 	github.com/metal-toolbox/bomservice v0.2.0
@@ -30,6 +28,7 @@ require (
 	golang.org/x/exp v0.0.0-20250106191152-7588d65b2ba8
 	golang.org/x/net v0.38.0
 	golang.org/x/oauth2 v0.28.0
+	golang.org/x/sys v0.31.0
 )
 
 require (
@@ -137,7 +136,6 @@ require (
 	gocloud.dev v0.40.0 // indirect
 	golang.org/x/arch v0.13.0 // indirect
 	golang.org/x/crypto v0.36.0 // indirect
-	golang.org/x/sys v0.31.0 // indirect
 	golang.org/x/text v0.23.0 // indirect
 	golang.org/x/xerrors v0.0.0-20240903120638-7835f813f4da // indirect
 	google.golang.org/api v0.216.0 // indirect
@@ -44,6 +44,31 @@ func NewFleetDBAPIClient(ctx context.Context, cfg *model.ConfigOIDC, reauth bool
 	)
 }
 
+// NewFleetDBAPIClientFromKeyring returns a fleetdb API client using the access token stored in the keyring,
+// it is intended for shell completions and does not start the interactive auth flow when no valid token is stored.
+func NewFleetDBAPIClientFromKeyring(ctx context.Context, cfg *model.ConfigOIDC) (*fleetdbapi.Client, error) {
+	accessToken := "fake"
+
+	if cfg == nil {
+		return nil, errors.Wrap(ErrNilConfig, "missing fleetdb API API client configuration")
+	}
+
+	if !cfg.Disable {
+		token, err := auth.StoredAccessToken(ctx, model.FleetDBAPI, cfg)
+		if err != nil {
+			return nil, errors.Wrap(ErrAuth, string(model.FleetDBAPI)+err.Error())
+		}
+
+		accessToken = token
+	}
+
+	return fleetdbapi.NewClientWithToken(
+		accessToken,
+		cfg.Endpoint,
+		nil,
+	)
+}
+
 func NewConditionsClient(ctx context.Context, cfg *model.ConfigOIDC, reauth bool) (*co.Client, error) {
 	if cfg == nil {
 		return nil, errors.Wrap(ErrNilConfig, "missing conditions API client configuration")
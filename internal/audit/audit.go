@@ -0,0 +1,285 @@
+// Package audit implements the local append-only journal of mutating mctl operations.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/pkg/errors"
+)
+
+const (
+	// journalFile is the path of the journal relative to the XDG state directory.
+	journalFile = "mctl/audit.jsonl"
+
+	redacted = "REDACTED"
+)
+
+var (
+	ErrJournal = errors.New("audit journal error")
+)
+
+// Record is a single entry in the audit journal.
+type Record struct {
+	// Time is when the operation completed.
+	Time time.Time `json:"time"`
+
+	// User is the local user that ran the command.
+	User string `json:"user"`
+
+	// Subject is the subject of the OAuth token used for the operation, if any.
+	Subject string `json:"subject,omitempty"`
+
+	// Command is the full name of the command - 'mctl delete server'.
+	Command string `json:"command"`
+
+	// Args is the command line, with secrets redacted.
+	Args []string `json:"args"`
+
+	// Targets are the server or object IDs the operation was performed on.
+	Targets []string `json:"targets,omitempty"`
+
+	// Results are the responses for the operation.
+	Results []Result `json:"results,omitempty"`
+}
+
+// Result is the outcome of an operation on a single target.
+type Result struct {
+	// Target is the server or object ID the result applies to.
+	Target string `json:"target,omitempty"`
+
+	// ID is the condition ID or the ID of the fleetdb object created.
+	ID string `json:"id,omitempty"`
+
+	// StatusCode is the API response status code.
+	StatusCode int `json:"status_code,omitempty"`
+
+	// Message is the API response message.
+	Message string `json:"message,omitempty"`
+}
+
+// Filter selects records from the journal, zero value fields match all records.
+type Filter struct {
+	// Since excludes records older than the given time.
+	Since time.Time
+	// User is the local user that ran the command.
+	User string
+	// Subject is the OAuth token subject.
+	Subject string
+	// Command matches records whose command contains the given value.
+	Command string
+	// Target matches records with the given target.
+	Target string
+	// Limit returns at most the last Limit records.
+	Limit int
+}
+
+// Match returns true when the record is selected by the filter.
+func (f *Filter) Match(r *Record) bool {
+	if !f.Since.IsZero() && r.Time.Before(f.Since) {
+		return false
+	}
+
+	if f.User != "" && r.User != f.User {
+		return false
+	}
+
+	if f.Subject != "" && r.Subject != f.Subject {
+		return false
+	}
+
+	if f.Command != "" && !strings.Contains(r.Command, f.Command) {
+		return false
+	}
+
+	if f.Target != "" && !hasTarget(r, f.Target) {
+		return false
+	}
+
+	return true
+}
+
+func hasTarget(r *Record, target string) bool {
+	for _, t := range r.Targets {
+		if strings.EqualFold(t, target) {
+			return true
+		}
+	}
+
+	for _, result := range r.Results {
+		if strings.EqualFold(result.Target, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Journal is an append-only file of JSON encoded records, one per line.
+type Journal struct {
+	path string
+}
+
+// New returns a Journal for the file at the given path.
+func New(path string) *Journal {
+	return &Journal{path: path}
+}
+
+// DefaultPath returns the path of the journal under the XDG state directory,
+// creating the parent directories if required.
+func DefaultPath() (string, error) {
+	return xdg.StateFile(journalFile)
+}
+
+// Path returns the journal file path.
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// Append writes the record to the end of the journal.
+func (j *Journal) Append(r *Record) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return errors.Wrap(ErrJournal, err.Error())
+	}
+
+	if err := os.MkdirAll(filepath.Dir(j.path), 0o700); err != nil {
+		return errors.Wrap(ErrJournal, err.Error())
+	}
+
+	f, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return errors.Wrap(ErrJournal, err.Error())
+	}
+
+	defer f.Close()
+
+	// the record and its newline are written in one call so concurrent writers don't interleave
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		return errors.Wrap(ErrJournal, err.Error())
+	}
+
+	return nil
+}
+
+// Records returns the records in the journal selected by the filter, oldest first.
+//
+// A journal that does not exist yet has no records.
+func (j *Journal) Records(filter *Filter) ([]*Record, error) {
+	f, err := os.Open(j.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []*Record{}, nil
+		}
+
+		return nil, errors.Wrap(ErrJournal, err.Error())
+	}
+
+	defer f.Close()
+
+	records := []*Record{}
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		r := &Record{}
+		if err := json.Unmarshal(scanner.Bytes(), r); err != nil {
+			return nil, errors.Wrapf(ErrJournal, "%s:%d: %s", j.path, line, err.Error())
+		}
+
+		if filter != nil && !filter.Match(r) {
+			continue
+		}
+
+		records = append(records, r)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(ErrJournal, err.Error())
+	}
+
+	if filter != nil && filter.Limit > 0 && len(records) > filter.Limit {
+		records = records[len(records)-filter.Limit:]
+	}
+
+	return records, nil
+}
+
+// RedactArgs returns a copy of the command line with the values of the given flags redacted.
+//
+// Flags are named without the leading dashes, single character names are matched as shorthand flags,
+// both the '--flag value' and '--flag=value' forms are handled.
+func RedactArgs(args []string, flags ...string) []string {
+	long := map[string]bool{}
+	short := map[string]bool{}
+
+	for _, f := range flags {
+		if len(f) == 1 {
+			short[f] = true
+		} else {
+			long[f] = true
+		}
+	}
+
+	out := make([]string, len(args))
+	copy(out, args)
+
+	for idx := 0; idx < len(out); idx++ {
+		arg := out[idx]
+
+		switch {
+		case arg == "--":
+			return out
+		case strings.HasPrefix(arg, "--"):
+			name, _, hasValue := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+			if !long[name] {
+				continue
+			}
+
+			if hasValue {
+				out[idx] = "--" + name + "=" + redacted
+				continue
+			}
+
+			if idx+1 < len(out) {
+				idx++
+				out[idx] = redacted
+			}
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			// the value of a shorthand flag is either the rest of the argument or the next argument,
+			// shorthand booleans may be grouped before it - '-yp secret'.
+			for pos := 1; pos < len(arg); pos++ {
+				if !short[string(arg[pos])] {
+					continue
+				}
+
+				if pos+1 < len(arg) {
+					out[idx] = arg[:pos+1] + redacted
+					break
+				}
+
+				if idx+1 < len(out) {
+					idx++
+					out[idx] = redacted
+				}
+
+				break
+			}
+		}
+	}
+
+	return out
+}
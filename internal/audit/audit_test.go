@@ -0,0 +1,152 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactArgs(t *testing.T) {
+	testcases := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{
+			name: "long flag",
+			args: []string{"mctl", "create", "server", "--bmc-pass", "secret", "--bmc-user", "root"},
+			want: []string{"mctl", "create", "server", "--bmc-pass", redacted, "--bmc-user", "root"},
+		},
+		{
+			name: "long flag with value",
+			args: []string{"mctl", "create", "server", "--bmc-pass=secret"},
+			want: []string{"mctl", "create", "server", "--bmc-pass=" + redacted},
+		},
+		{
+			name: "shorthand flag",
+			args: []string{"mctl", "create", "server", "-p", "secret", "-u", "root"},
+			want: []string{"mctl", "create", "server", "-p", redacted, "-u", "root"},
+		},
+		{
+			name: "shorthand flag with value",
+			args: []string{"mctl", "create", "server", "-psecret"},
+			want: []string{"mctl", "create", "server", "-p" + redacted},
+		},
+		{
+			name: "grouped shorthand flags",
+			args: []string{"mctl", "create", "server", "-yp", "secret"},
+			want: []string{"mctl", "create", "server", "-yp", redacted},
+		},
+		{
+			name: "several flags",
+			args: []string{"mctl", "create", "server", "--bmc-pass-file=/tmp/pass", "--token", "secret", "--bmc-user", "root"},
+			want: []string{"mctl", "create", "server", "--bmc-pass-file=" + redacted, "--token", redacted, "--bmc-user", "root"},
+		},
+		{
+			name: "no secrets",
+			args: []string{"mctl", "delete", "server", "-s", "fd5c3a0b-4d2f-4c6c-8e7b-32b0f1d0b4a1", "--bmc-passthrough"},
+			want: []string{"mctl", "delete", "server", "-s", "fd5c3a0b-4d2f-4c6c-8e7b-32b0f1d0b4a1", "--bmc-passthrough"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := RedactArgs(tc.args, "bmc-pass", "p", "bmc-pass-file", "token")
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestJournal(t *testing.T) {
+	journal := New(filepath.Join(t.TempDir(), "mctl", "audit.jsonl"))
+
+	// a journal that was never written to has no records
+	records, err := journal.Records(nil)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	now := time.Now().UTC().Truncate(time.Second)
+
+	entries := []*Record{
+		{
+			Time:    now.Add(-48 * time.Hour),
+			User:    "alice",
+			Command: "mctl power",
+			Targets: []string{"fd5c3a0b-4d2f-4c6c-8e7b-32b0f1d0b4a1"},
+		},
+		{
+			Time:    now.Add(-time.Hour),
+			User:    "bob",
+			Subject: "bob@example.com",
+			Command: "mctl delete server",
+			Targets: []string{"6a1c8a4c-8a5f-4e56-9e3b-5c2bb0b0d6e2"},
+		},
+		{
+			Time:    now,
+			User:    "alice",
+			Command: "mctl install firmware-set",
+			Results: []Result{{Target: "fd5c3a0b-4d2f-4c6c-8e7b-32b0f1d0b4a1", ID: "c1", StatusCode: 200}},
+		},
+	}
+
+	for _, r := range entries {
+		require.NoError(t, journal.Append(r))
+	}
+
+	info, err := os.Stat(journal.Path())
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	testcases := []struct {
+		name   string
+		filter *Filter
+		want   []*Record
+	}{
+		{
+			name: "all",
+			want: entries,
+		},
+		{
+			name:   "since",
+			filter: &Filter{Since: now.Add(-2 * time.Hour)},
+			want:   entries[1:],
+		},
+		{
+			name:   "user",
+			filter: &Filter{User: "alice"},
+			want:   []*Record{entries[0], entries[2]},
+		},
+		{
+			name:   "subject",
+			filter: &Filter{Subject: "bob@example.com"},
+			want:   entries[1:2],
+		},
+		{
+			name:   "command",
+			filter: &Filter{Command: "install"},
+			want:   entries[2:],
+		},
+		{
+			name:   "target in targets or results",
+			filter: &Filter{Target: "FD5C3A0B-4D2F-4C6C-8E7B-32B0F1D0B4A1"},
+			want:   []*Record{entries[0], entries[2]},
+		},
+		{
+			name:   "limit keeps the latest",
+			filter: &Filter{Limit: 1},
+			want:   entries[2:],
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := journal.Records(tc.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
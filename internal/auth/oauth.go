@@ -79,6 +79,44 @@ func AccessToken(ctx context.Context, apiKind model.APIKind, cfg *model.ConfigOI
 	return token.AccessToken, nil
 }
 
+// StoredAccessToken returns the service access token from the keyring, refreshing it if required,
+// unlike AccessToken it never falls back to the interactive auth flow.
+func StoredAccessToken(ctx context.Context, apiKind model.APIKind, cfg *model.ConfigOIDC) (string, error) {
+	token, err := newOIDCAuthenticator(apiKind, cfg).refreshToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return token.AccessToken, nil
+}
+
+// TokenSubject returns the subject of the access token stored in the keyring for the service,
+// an empty subject is returned when auth is disabled for the service.
+func TokenSubject(apiKind model.APIKind, cfg *model.ConfigOIDC) (string, error) {
+	if cfg == nil || cfg.Disable {
+		return "", nil
+	}
+
+	authenticator := newOIDCAuthenticator(apiKind, cfg)
+
+	rawAccess, err := keyring.Get(keyringService, authenticator.keyringNameToken())
+	if err != nil {
+		return "", err
+	}
+
+	tok, err := jwt.ParseSigned(rawAccess, []jose.SignatureAlgorithm{jose.RS256})
+	if err != nil {
+		return "", err
+	}
+
+	cl := jwt.Claims{}
+	if err := tok.UnsafeClaimsWithoutVerification(&cl); err != nil {
+		return "", err
+	}
+
+	return cl.Subject, nil
+}
+
 // GetOAuth2Token retrieves the OAuth2 token from the issuer and stores it in the local keyring with the given name.
 func (a *authenticator) getOAuth2Token(ctx context.Context) (*oauth2.Token, error) {
 	oauthConfig, err := a.oauth2Config(ctx)
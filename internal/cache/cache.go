@@ -0,0 +1,109 @@
+// Package cache implements a small file backed cache with expiring entries.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/pkg/errors"
+)
+
+// cacheDir is the cache directory relative to the XDG cache directory.
+const cacheDir = "mctl"
+
+var (
+	ErrCache = errors.New("cache error")
+)
+
+type entry struct {
+	Expires time.Time       `json:"expires"`
+	Value   json.RawMessage `json:"value"`
+}
+
+// Cache stores JSON encoded values in files under a directory,
+// entries older than the TTL are ignored.
+type Cache struct {
+	dir string
+	ttl time.Duration
+	now func() time.Time
+}
+
+// New returns a Cache storing entries in dir.
+func New(dir string, ttl time.Duration) *Cache {
+	return &Cache{dir: dir, ttl: ttl, now: time.Now}
+}
+
+// DefaultDir returns the directory for the named cache under the XDG cache directory.
+func DefaultDir(name string) string {
+	return filepath.Join(xdg.CacheHome, cacheDir, name)
+}
+
+// Get decodes the unexpired value for the key into v, returning false if there is none.
+func (c *Cache) Get(key string, v any) bool {
+	b, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return false
+	}
+
+	e := &entry{}
+	if err := json.Unmarshal(b, e); err != nil {
+		return false
+	}
+
+	if c.now().After(e.Expires) {
+		return false
+	}
+
+	return json.Unmarshal(e.Value, v) == nil
+}
+
+// Set stores the value for the key.
+func (c *Cache) Set(key string, v any) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return errors.Wrap(ErrCache, err.Error())
+	}
+
+	b, err := json.Marshal(&entry{Expires: c.now().Add(c.ttl), Value: value})
+	if err != nil {
+		return errors.Wrap(ErrCache, err.Error())
+	}
+
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return errors.Wrap(ErrCache, err.Error())
+	}
+
+	// write to a temporary file and rename so a concurrent reader never sees a partial entry
+	tmp, err := os.CreateTemp(c.dir, ".entry-*")
+	if err != nil {
+		return errors.Wrap(ErrCache, err.Error())
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return errors.Wrap(ErrCache, err.Error())
+	}
+
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(ErrCache, err.Error())
+	}
+
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		return errors.Wrap(ErrCache, err.Error())
+	}
+
+	return nil
+}
+
+// path returns the file for the key, keys are hashed so they may contain any characters.
+func (c *Cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
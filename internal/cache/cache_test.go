@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	c := New(t.TempDir(), time.Minute)
+	c.now = func() time.Time { return now }
+
+	var got []string
+
+	// miss
+	assert.False(t, c.Get("servers", &got))
+
+	want := []string{"fd5c3a0b-4d2f-4c6c-8e7b-32b0f1d0b4a1\tnode1", "6a1c8a4c-8a5f-4e56-9e3b-5c2bb0b0d6e2\tnode2"}
+	require.NoError(t, c.Set("servers", want))
+
+	// hit
+	assert.True(t, c.Get("servers", &got))
+	assert.Equal(t, want, got)
+
+	// keys are independent
+	assert.False(t, c.Get("firmware", &got))
+
+	// overwrite
+	require.NoError(t, c.Set("servers", want[:1]))
+	assert.True(t, c.Get("servers", &got))
+	assert.Equal(t, want[:1], got)
+
+	// expired
+	now = now.Add(2 * time.Minute)
+	assert.False(t, c.Get("servers", &got))
+}
@@ -0,0 +1,243 @@
+// Package enroll reads the manifests of servers to enroll and writes the enrollment results.
+package enroll
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+)
+
+var (
+	ErrManifest = errors.New("enrollment manifest error")
+)
+
+// csvColumns are the manifest CSV columns, the header row names the columns in any order.
+var csvColumns = []string{"facility", "bmc_address", "bmc_username", "bmc_password", "server_id"}
+
+// Row is a server to enroll.
+type Row struct {
+	// Index is the position of the row in the manifest starting at 1, it is not read from the manifest.
+	Index       int    `json:"-"`
+	Facility    string `json:"facility"`
+	BMCAddress  string `json:"bmc_address"`
+	BMCUsername string `json:"bmc_username"`
+	BMCPassword string `json:"bmc_password"`
+	// ServerID is the ID to assign to the server, the enroll condition assigns one when empty.
+	ServerID string `json:"server_id"`
+}
+
+// Result is the outcome of a row enrollment, rows that failed have an Error.
+type Result struct {
+	Row         int    `json:"row"`
+	Facility    string `json:"facility"`
+	BMCAddress  string `json:"bmc_address"`
+	ServerID    string `json:"server_id,omitempty"`
+	ConditionID string `json:"condition_id,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// ReadManifest returns the rows of a CSV or JSON manifest, files with the .csv extension are CSV.
+func ReadManifest(path string) ([]Row, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(ErrManifest, err.Error())
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return parseCSV(bytes.NewReader(b))
+	}
+
+	return parseJSON(b)
+}
+
+func parseJSON(b []byte) ([]Row, error) {
+	rows := []Row{}
+
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&rows); err != nil {
+		return nil, errors.Wrap(ErrManifest, err.Error())
+	}
+
+	for idx := range rows {
+		rows[idx].Index = idx + 1
+	}
+
+	return rows, nil
+}
+
+func parseCSV(r io.Reader) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.Wrap(ErrManifest, "header: "+err.Error())
+	}
+
+	columns := map[string]int{}
+
+	for idx, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(csvColumns, name) {
+			return nil, errors.Wrap(ErrManifest, fmt.Sprintf("unknown column %q, expected %s", name, strings.Join(csvColumns, ",")))
+		}
+
+		columns[name] = idx
+	}
+
+	value := func(record []string, name string) string {
+		idx, ok := columns[name]
+		if !ok {
+			return ""
+		}
+
+		return strings.TrimSpace(record[idx])
+	}
+
+	rows := []Row{}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, errors.Wrap(ErrManifest, err.Error())
+		}
+
+		rows = append(rows, Row{
+			Index:       len(rows) + 1,
+			Facility:    value(record, "facility"),
+			BMCAddress:  value(record, "bmc_address"),
+			BMCUsername: value(record, "bmc_username"),
+			BMCPassword: value(record, "bmc_password"),
+			ServerID:    value(record, "server_id"),
+		})
+	}
+
+	return rows, nil
+}
+
+// ApplyDefaults sets the facility and BMC credentials of rows without them.
+func ApplyDefaults(rows []Row, defaults *Row) {
+	for idx := range rows {
+		if rows[idx].Facility == "" {
+			rows[idx].Facility = defaults.Facility
+		}
+
+		if rows[idx].BMCUsername == "" {
+			rows[idx].BMCUsername = defaults.BMCUsername
+		}
+
+		if rows[idx].BMCPassword == "" {
+			rows[idx].BMCPassword = defaults.BMCPassword
+		}
+	}
+}
+
+// Validate returns an error listing the rows with missing fields, invalid server IDs or
+// a BMC address or server ID of an earlier row.
+func Validate(rows []Row) error {
+	if len(rows) == 0 {
+		return errors.Wrap(ErrManifest, "no rows")
+	}
+
+	problems := []string{}
+	addresses := map[string]int{}
+	serverIDs := map[string]int{}
+
+	for idx := range rows {
+		row := &rows[idx]
+		prefix := "row " + strconv.Itoa(row.Index) + ": "
+
+		for _, field := range []struct{ name, value string }{
+			{"facility", row.Facility},
+			{"bmc_address", row.BMCAddress},
+			{"bmc_username", row.BMCUsername},
+			{"bmc_password", row.BMCPassword},
+		} {
+			if field.value == "" {
+				problems = append(problems, prefix+"no "+field.name)
+			}
+		}
+
+		if first, ok := addresses[row.BMCAddress]; ok && row.BMCAddress != "" {
+			problems = append(problems, prefix+"bmc_address "+row.BMCAddress+" is in row "+strconv.Itoa(first))
+		} else {
+			addresses[row.BMCAddress] = row.Index
+		}
+
+		if row.ServerID == "" {
+			continue
+		}
+
+		if _, err := uuid.Parse(row.ServerID); err != nil {
+			problems = append(problems, prefix+"invalid server_id "+row.ServerID)
+			continue
+		}
+
+		if first, ok := serverIDs[row.ServerID]; ok {
+			problems = append(problems, prefix+"server_id "+row.ServerID+" is in row "+strconv.Itoa(first))
+		} else {
+			serverIDs[row.ServerID] = row.Index
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.Wrap(ErrManifest, strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// WriteResults writes the results as CSV when the path has the .csv extension, as JSON otherwise.
+func WriteResults(path string, results []Result) error {
+	var buf bytes.Buffer
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		writer := csv.NewWriter(&buf)
+		records := [][]string{{"row", "facility", "bmc_address", "server_id", "condition_id", "error"}}
+
+		for _, r := range results {
+			records = append(records, []string{
+				strconv.Itoa(r.Row), r.Facility, r.BMCAddress, r.ServerID, r.ConditionID, r.Error,
+			})
+		}
+
+		if err := writer.WriteAll(records); err != nil {
+			return errors.Wrap(ErrManifest, err.Error())
+		}
+	} else {
+		b, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return errors.Wrap(ErrManifest, err.Error())
+		}
+
+		buf.Write(append(b, '\n'))
+	}
+
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		return errors.Wrap(ErrManifest, err.Error())
+	}
+
+	return nil
+}
+
+// ResultsPath returns the default results file for a manifest - servers.csv -> servers-results.csv.
+func ResultsPath(manifest string) string {
+	ext := filepath.Ext(manifest)
+	return strings.TrimSuffix(manifest, ext) + "-results" + ext
+}
@@ -0,0 +1,115 @@
+package enroll
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadManifest(t *testing.T) {
+	dir := t.TempDir()
+
+	csvPath := filepath.Join(dir, "servers.csv")
+	require.NoError(t, os.WriteFile(csvPath, []byte(`bmc_address, facility, server_id
+10.0.0.1, sandbox, 6b6f0a8e-7b4a-4c4e-9b1a-0c5e8f2d1a11
+10.0.0.2, sandbox,
+`), 0o600))
+
+	rows, err := ReadManifest(csvPath)
+	require.NoError(t, err)
+	assert.Equal(t, []Row{
+		{Index: 1, Facility: "sandbox", BMCAddress: "10.0.0.1", ServerID: "6b6f0a8e-7b4a-4c4e-9b1a-0c5e8f2d1a11"},
+		{Index: 2, Facility: "sandbox", BMCAddress: "10.0.0.2"},
+	}, rows)
+
+	jsonPath := filepath.Join(dir, "servers.json")
+	require.NoError(t, os.WriteFile(jsonPath,
+		[]byte(`[{"facility":"sandbox","bmc_address":"10.0.0.1","bmc_username":"root"}]`), 0o600))
+
+	rows, err = ReadManifest(jsonPath)
+	require.NoError(t, err)
+	assert.Equal(t, []Row{{Index: 1, Facility: "sandbox", BMCAddress: "10.0.0.1", BMCUsername: "root"}}, rows)
+
+	// misspelled columns are not ignored
+	require.NoError(t, os.WriteFile(csvPath, []byte("bmc_addr,facility\n10.0.0.1,sandbox\n"), 0o600))
+
+	_, err = ReadManifest(csvPath)
+	assert.ErrorIs(t, err, ErrManifest)
+
+	require.NoError(t, os.WriteFile(jsonPath, []byte(`[{"bmc_addr":"10.0.0.1"}]`), 0o600))
+
+	_, err = ReadManifest(jsonPath)
+	assert.ErrorIs(t, err, ErrManifest)
+}
+
+func TestValidate(t *testing.T) {
+	rows := []Row{
+		{Index: 1, Facility: "sandbox", BMCAddress: "10.0.0.1", BMCUsername: "admin", BMCPassword: "secret"},
+		{Index: 2, BMCAddress: "10.0.0.2", ServerID: "6b6f0a8e-7b4a-4c4e-9b1a-0c5e8f2d1a11"},
+	}
+
+	ApplyDefaults(rows, &Row{Facility: "default", BMCUsername: "root", BMCPassword: "shared"})
+	assert.Equal(t, "sandbox", rows[0].Facility)
+	assert.Equal(t, "secret", rows[0].BMCPassword)
+	assert.Equal(t, Row{
+		Index:       2,
+		Facility:    "default",
+		BMCAddress:  "10.0.0.2",
+		BMCUsername: "root",
+		BMCPassword: "shared",
+		ServerID:    "6b6f0a8e-7b4a-4c4e-9b1a-0c5e8f2d1a11",
+	}, rows[1])
+	require.NoError(t, Validate(rows))
+
+	invalid := []Row{
+		{Index: 1, Facility: "sandbox", BMCAddress: "10.0.0.1", BMCUsername: "root"},
+		{Index: 2, Facility: "sandbox", BMCAddress: "10.0.0.1", BMCUsername: "root", BMCPassword: "x", ServerID: "srv-1"},
+	}
+
+	err := Validate(invalid)
+	require.ErrorIs(t, err, ErrManifest)
+
+	for _, problem := range []string{
+		"row 1: no bmc_password",
+		"row 2: bmc_address 10.0.0.1 is in row 1",
+		"row 2: invalid server_id srv-1",
+	} {
+		assert.Contains(t, err.Error(), problem)
+	}
+
+	assert.ErrorIs(t, Validate(nil), ErrManifest)
+}
+
+func TestWriteResults(t *testing.T) {
+	dir := t.TempDir()
+	results := []Result{
+		{Row: 1, Facility: "sandbox", BMCAddress: "10.0.0.1", ServerID: "s1", ConditionID: "c1"},
+		{Row: 2, Facility: "sandbox", BMCAddress: "10.0.0.2", Error: "bmc unreachable"},
+	}
+
+	path := ResultsPath(filepath.Join(dir, "servers.csv"))
+	assert.Equal(t, filepath.Join(dir, "servers-results.csv"), path)
+
+	require.NoError(t, WriteResults(path, results))
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Join([]string{
+		"row,facility,bmc_address,server_id,condition_id,error",
+		"1,sandbox,10.0.0.1,s1,c1,",
+		"2,sandbox,10.0.0.2,,,bmc unreachable",
+		"",
+	}, "\n"), string(b))
+
+	path = ResultsPath(filepath.Join(dir, "servers.json"))
+	require.NoError(t, WriteResults(path, results))
+
+	b, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"condition_id": "c1"`)
+	assert.Contains(t, string(b), `"error": "bmc unreachable"`)
+}
@@ -0,0 +1,243 @@
+package firmware
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	common "github.com/metal-toolbox/bmc-common"
+	ss "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrCatalog = errors.New("firmware catalog error")
+)
+
+// dellCategories maps the Dell catalog categories to component slugs.
+var dellCategories = map[string]string{
+	"BI": common.SlugBIOS,
+	"LC": common.SlugBMC,
+	"ES": common.SlugBMC,
+	"NI": common.SlugNIC,
+	"SA": common.SlugStorageController,
+	"SF": common.SlugDrive,
+	"AS": common.SlugDrive,
+	"CP": common.SlugCPLD,
+	"PS": common.SlugPSU,
+	"BP": common.SlugBackplaneExpander,
+}
+
+// dellCatalog is a Dell update catalog - Catalog.xml.
+type dellCatalog struct {
+	BaseLocation string `xml:"baseLocation,attr"`
+	Protocols    string `xml:"baseLocationAccessProtocols,attr"`
+	Components   []struct {
+		Path          string `xml:"path,attr"`
+		VendorVersion string `xml:"vendorVersion,attr"`
+		HashMD5       string `xml:"hashMD5,attr"`
+		ComponentType struct {
+			Value string `xml:"value,attr"`
+		} `xml:"ComponentType"`
+		Category struct {
+			Value string `xml:"value,attr"`
+		} `xml:"Category"`
+		Models []string   `xml:"SupportedSystems>Brand>Model>Display"`
+		Hashes []dellHash `xml:"Cryptography>Hash"`
+	} `xml:"SoftwareComponent"`
+}
+
+type dellHash struct {
+	Algorithm string `xml:"algorithm,attr"`
+	Value     string `xml:",chardata"`
+}
+
+// SupermicroManifest lists Supermicro firmware downloads, Supermicro doesn't publish a catalog.
+type SupermicroManifest struct {
+	// BaseURL is prefixed to the filename of firmware without a URL.
+	BaseURL  string `json:"base_url"`
+	Firmware []struct {
+		Models    []string `json:"models"`
+		Component string   `json:"component"`
+		Version   string   `json:"version"`
+		Filename  string   `json:"filename"`
+		Checksum  string   `json:"checksum"`
+		URL       string   `json:"url,omitempty"`
+	} `json:"firmware"`
+}
+
+// ParseDellCatalog returns the firmware for the model in a Dell update catalog.
+//
+// Only Windows update packages are returned, the iDRAC installs these regardless of the host OS.
+// Packages in categories without a component slug are skipped.
+func ParseDellCatalog(data []byte, model string) ([]ss.ComponentFirmwareVersion, error) {
+	catalog := &dellCatalog{}
+
+	decoder := xml.NewDecoder(bytes.NewReader(utf8Catalog(data)))
+	// the catalog is converted to UTF-8 above, the declared encoding is ignored
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) { return input, nil }
+
+	if err := decoder.Decode(catalog); err != nil {
+		return nil, errors.Wrap(ErrCatalog, err.Error())
+	}
+
+	scheme := "https"
+	if catalog.Protocols != "" && !strings.Contains(strings.ToUpper(catalog.Protocols), "HTTPS") {
+		scheme = "http"
+	}
+
+	firmware := []ss.ComponentFirmwareVersion{}
+	seen := map[string]bool{}
+
+	for idx := range catalog.Components {
+		c := &catalog.Components[idx]
+
+		if c.ComponentType.Value != "FRMW" && c.ComponentType.Value != "BIOS" {
+			continue
+		}
+
+		slug, ok := dellCategories[c.Category.Value]
+		if !ok {
+			continue
+		}
+
+		if !strings.EqualFold(path.Ext(c.Path), ".exe") || !containsFold(c.Models, model) {
+			continue
+		}
+
+		filename := path.Base(c.Path)
+		if seen[filename] {
+			continue
+		}
+
+		seen[filename] = true
+
+		firmware = append(firmware, ss.ComponentFirmwareVersion{
+			Vendor:      common.VendorDell,
+			Model:       []string{strings.ToLower(model)},
+			Filename:    filename,
+			Version:     c.VendorVersion,
+			Component:   strings.ToLower(slug),
+			Checksum:    dellChecksum(c.HashMD5, c.Hashes),
+			UpstreamURL: (&url.URL{Scheme: scheme, Host: catalog.BaseLocation, Path: "/" + c.Path}).String(),
+		})
+	}
+
+	return sortFirmware(firmware), nil
+}
+
+// dellChecksum returns the SHA256 checksum of a package, or the MD5 checksum in older catalogs.
+func dellChecksum(md5sum string, hashes []dellHash) string {
+	for _, h := range hashes {
+		if strings.EqualFold(h.Algorithm, "SHA256") && strings.TrimSpace(h.Value) != "" {
+			return "sha256sum:" + strings.ToLower(strings.TrimSpace(h.Value))
+		}
+	}
+
+	if md5sum == "" {
+		return ""
+	}
+
+	return "md5sum:" + strings.ToLower(md5sum)
+}
+
+// utf8Catalog returns the catalog in UTF-8, Dell publishes catalogs in UTF-16 with a byte order mark.
+func utf8Catalog(data []byte) []byte {
+	var order binary.ByteOrder
+
+	switch {
+	case bytes.HasPrefix(data, []byte{0xff, 0xfe}):
+		order = binary.LittleEndian
+	case bytes.HasPrefix(data, []byte{0xfe, 0xff}):
+		order = binary.BigEndian
+	default:
+		return bytes.TrimPrefix(data, []byte{0xef, 0xbb, 0xbf})
+	}
+
+	data = data[2:]
+
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		units = append(units, order.Uint16(data[i:]))
+	}
+
+	return []byte(string(utf16.Decode(units)))
+}
+
+// ParseSupermicroManifest returns the firmware for the model in a Supermicro manifest.
+func ParseSupermicroManifest(data []byte, model string) ([]ss.ComponentFirmwareVersion, error) {
+	manifest := &SupermicroManifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, errors.Wrap(ErrCatalog, err.Error())
+	}
+
+	firmware := []ss.ComponentFirmwareVersion{}
+
+	for idx := range manifest.Firmware {
+		f := &manifest.Firmware[idx]
+		if !containsFold(f.Models, model) {
+			continue
+		}
+
+		upstream := f.URL
+		if upstream == "" && manifest.BaseURL != "" {
+			upstream = strings.TrimSuffix(manifest.BaseURL, "/") + "/" + f.Filename
+		}
+
+		firmware = append(firmware, ss.ComponentFirmwareVersion{
+			Vendor:      common.VendorSupermicro,
+			Model:       []string{strings.ToLower(model)},
+			Filename:    f.Filename,
+			Version:     f.Version,
+			Component:   strings.ToLower(f.Component),
+			Checksum:    strings.ToLower(f.Checksum),
+			UpstreamURL: upstream,
+		})
+	}
+
+	return sortFirmware(firmware), nil
+}
+
+// SetRepositoryURLs sets the repository URL of the firmware to the filename under the vendor
+// directory of the repository - 'https://firmware.example.com/files/dell/BIOS_2.19.1.EXE'.
+func SetRepositoryURLs(firmware []ss.ComponentFirmwareVersion, repositoryURL string) error {
+	base, err := url.Parse(repositoryURL)
+	if err != nil {
+		return errors.Wrap(ErrCatalog, err.Error())
+	}
+
+	for idx := range firmware {
+		firmware[idx].RepositoryURL = base.JoinPath(firmware[idx].Vendor, firmware[idx].Filename).String()
+	}
+
+	return nil
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortFirmware(firmware []ss.ComponentFirmwareVersion) []ss.ComponentFirmwareVersion {
+	sort.Slice(firmware, func(i, j int) bool {
+		if firmware[i].Component != firmware[j].Component {
+			return firmware[i].Component < firmware[j].Component
+		}
+
+		return firmware[i].Filename < firmware[j].Filename
+	})
+
+	return firmware
+}
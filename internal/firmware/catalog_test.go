@@ -0,0 +1,162 @@
+package firmware
+
+import (
+	"encoding/binary"
+	"testing"
+	"unicode/utf16"
+
+	ss "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const dellCatalogXML = `<?xml version="1.0" encoding="utf-16"?>
+<Manifest baseLocation="downloads.dell.com" baseLocationAccessProtocols="HTTPS" dateTime="2024-05-01T00:00:00">
+  <SoftwareComponent path="FOLDER1/BIOS_2.19.1.EXE" vendorVersion="2.19.1" hashMD5="A1B2C3D4E5F60718293A4B5C6D7E8F90">
+    <ComponentType value="BIOS" />
+    <Category value="BI" />
+    <SupportedSystems>
+      <Brand><Model><Display>R6515</Display></Model><Model><Display>R7515</Display></Model></Brand>
+    </SupportedSystems>
+    <Cryptography>
+      <Hash algorithm="SHA256">E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855</Hash>
+    </Cryptography>
+  </SoftwareComponent>
+  <SoftwareComponent path="FOLDER2/iDRAC_7.00.00.EXE" vendorVersion="7.00.00.00" hashMD5="0123456789ABCDEF0123456789ABCDEF">
+    <ComponentType value="FRMW" />
+    <Category value="LC" />
+    <SupportedSystems>
+      <Brand><Model><Display>R6515</Display></Model></Brand>
+    </SupportedSystems>
+  </SoftwareComponent>
+  <SoftwareComponent path="FOLDER2/iDRAC_7.00.00.BIN" vendorVersion="7.00.00.00" hashMD5="0123456789ABCDEF0123456789ABCDEF">
+    <ComponentType value="FRMW" />
+    <Category value="LC" />
+    <SupportedSystems>
+      <Brand><Model><Display>R6515</Display></Model></Brand>
+    </SupportedSystems>
+  </SoftwareComponent>
+  <SoftwareComponent path="FOLDER3/Driver_1.0.EXE" vendorVersion="1.0" hashMD5="0123456789ABCDEF0123456789ABCDEF">
+    <ComponentType value="DRVR" />
+    <Category value="NI" />
+    <SupportedSystems>
+      <Brand><Model><Display>R6515</Display></Model></Brand>
+    </SupportedSystems>
+  </SoftwareComponent>
+  <SoftwareComponent path="FOLDER4/Network_22.5.EXE" vendorVersion="22.5" hashMD5="0123456789ABCDEF0123456789ABCDEF">
+    <ComponentType value="FRMW" />
+    <Category value="NI" />
+    <SupportedSystems>
+      <Brand><Model><Display>R7515</Display></Model></Brand>
+    </SupportedSystems>
+  </SoftwareComponent>
+</Manifest>`
+
+func utf16LE(s string) []byte {
+	b := []byte{0xff, 0xfe}
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = binary.LittleEndian.AppendUint16(b, u)
+	}
+
+	return b
+}
+
+func TestParseDellCatalog(t *testing.T) {
+	want := []ss.ComponentFirmwareVersion{
+		{
+			Vendor:      "dell",
+			Model:       []string{"r6515"},
+			Filename:    "BIOS_2.19.1.EXE",
+			Version:     "2.19.1",
+			Component:   "bios",
+			Checksum:    "sha256sum:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			UpstreamURL: "https://downloads.dell.com/FOLDER1/BIOS_2.19.1.EXE",
+		},
+		{
+			Vendor:      "dell",
+			Model:       []string{"r6515"},
+			Filename:    "iDRAC_7.00.00.EXE",
+			Version:     "7.00.00.00",
+			Component:   "bmc",
+			Checksum:    "md5sum:0123456789abcdef0123456789abcdef",
+			UpstreamURL: "https://downloads.dell.com/FOLDER2/iDRAC_7.00.00.EXE",
+		},
+	}
+
+	testcases := []struct {
+		name string
+		data []byte
+		want []ss.ComponentFirmwareVersion
+	}{
+		{name: "utf-8", data: []byte(dellCatalogXML), want: want},
+		{name: "utf-16 with byte order mark", data: utf16LE(dellCatalogXML), want: want},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseDellCatalog(tc.data, "r6515")
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	got, err := ParseDellCatalog([]byte(dellCatalogXML), "r640")
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	_, err = ParseDellCatalog([]byte("<Manifest"), "r6515")
+	assert.ErrorIs(t, err, ErrCatalog)
+}
+
+func TestParseSupermicroManifest(t *testing.T) {
+	manifest := `{
+  "base_url": "https://www.supermicro.com/Bios/softfiles/",
+  "firmware": [
+    {"models": ["X11DPH-T"], "component": "BIOS", "version": "3.9", "filename": "X11DPH9.B23.zip", "checksum": "MD5SUM:A1B2C3D4E5F60718293A4B5C6D7E8F90"},
+    {"models": ["X11DPH-T", "X11DPG-QT"], "component": "bmc", "version": "1.74.14", "filename": "BMC_X11AST2500.zip",
+     "checksum": "sha256sum:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+     "url": "https://www.supermicro.com/fw/BMC_X11AST2500.zip"},
+    {"models": ["X12STH-SYS"], "component": "bios", "version": "2.1", "filename": "X12STH.zip", "checksum": "md5sum:0123"}
+  ]
+}`
+
+	got, err := ParseSupermicroManifest([]byte(manifest), "x11dph-t")
+	require.NoError(t, err)
+
+	want := []ss.ComponentFirmwareVersion{
+		{
+			Vendor:      "supermicro",
+			Model:       []string{"x11dph-t"},
+			Filename:    "X11DPH9.B23.zip",
+			Version:     "3.9",
+			Component:   "bios",
+			Checksum:    "md5sum:a1b2c3d4e5f60718293a4b5c6d7e8f90",
+			UpstreamURL: "https://www.supermicro.com/Bios/softfiles/X11DPH9.B23.zip",
+		},
+		{
+			Vendor:      "supermicro",
+			Model:       []string{"x11dph-t"},
+			Filename:    "BMC_X11AST2500.zip",
+			Version:     "1.74.14",
+			Component:   "bmc",
+			Checksum:    "sha256sum:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			UpstreamURL: "https://www.supermicro.com/fw/BMC_X11AST2500.zip",
+		},
+	}
+
+	assert.Equal(t, want, got)
+
+	_, err = ParseSupermicroManifest([]byte("{"), "x11dph-t")
+	assert.ErrorIs(t, err, ErrCatalog)
+}
+
+func TestSetRepositoryURLs(t *testing.T) {
+	firmware := []ss.ComponentFirmwareVersion{
+		{Vendor: "dell", Filename: "BIOS_2.19.1.EXE"},
+		{Vendor: "supermicro", Filename: "BMC_X11AST2500.zip"},
+	}
+
+	require.NoError(t, SetRepositoryURLs(firmware, "https://firmware.example.com/files/"))
+	assert.Equal(t, "https://firmware.example.com/files/dell/BIOS_2.19.1.EXE", firmware[0].RepositoryURL)
+	assert.Equal(t, "https://firmware.example.com/files/supermicro/BMC_X11AST2500.zip", firmware[1].RepositoryURL)
+}
@@ -0,0 +1,277 @@
+// Package firmware verifies firmware artifacts are available and match their checksum,
+// and imports firmware from vendor catalogs.
+package firmware
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec // firmware checksums are published as md5
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	ss "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrChecksum = errors.New("invalid checksum")
+)
+
+// Status is the result of verifying an artifact.
+type Status string
+
+const (
+	// OK is an artifact that is available and matches its checksum when downloaded.
+	OK Status = "ok"
+
+	// Missing is an artifact that isn't found at its URL.
+	Missing Status = "missing"
+
+	// Mismatch is an artifact that doesn't match its checksum.
+	Mismatch Status = "checksum-mismatch"
+
+	// Unreachable is an artifact that couldn't be retrieved.
+	Unreachable Status = "unreachable"
+
+	// Invalid is an artifact without a URL or with a checksum that can't be verified.
+	Invalid Status = "invalid"
+)
+
+const (
+	// Repository is the artifact in the firmware repository installs download from.
+	Repository = "repository"
+
+	// Upstream is the artifact at the vendor URL, upstream artifacts are checked to exist.
+	Upstream = "upstream"
+)
+
+// Artifact is a firmware file to verify.
+type Artifact struct {
+	// FirmwareID identifies the firmware the artifact is for.
+	FirmwareID string `json:"firmware_id"`
+
+	// Source is the URL kind - 'repository' or 'upstream'.
+	Source   string `json:"source"`
+	Filename string `json:"filename"`
+	URL      string `json:"url"`
+
+	// Checksum is the expected checksum with its algorithm prefix - 'md5sum:f9a1...',
+	// repository artifacts require a checksum.
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// Result is the verification result of an artifact.
+type Result struct {
+	Artifact
+	Status Status `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Verifier checks firmware artifacts.
+type Verifier struct {
+	// Client is used to retrieve the artifacts, http.DefaultClient when nil.
+	Client *http.Client
+
+	// HeadOnly checks artifacts exist without downloading them, checksums aren't verified.
+	HeadOnly bool
+
+	// Concurrency is the number of artifacts verified in parallel, 1 when not set.
+	Concurrency int
+}
+
+// ParseChecksum returns the hash algorithm and the hex encoded sum of a checksum.
+//
+// Checksums are prefixed with their algorithm - 'md5sum:', 'md5:', 'sha256sum:' or 'sha256:',
+// checksums without a prefix are identified by their length. The sum length must match the algorithm.
+func ParseChecksum(checksum string) (algorithm, sum string, err error) {
+	algorithm, sum, found := strings.Cut(strings.TrimSpace(checksum), ":")
+	if !found {
+		algorithm, sum = "", algorithm
+	}
+
+	sum = strings.ToLower(sum)
+	if _, err := hex.DecodeString(sum); err != nil || sum == "" {
+		return "", "", errors.Wrap(ErrChecksum, "expected a hex encoded sum: "+checksum)
+	}
+
+	switch strings.TrimSuffix(strings.ToLower(algorithm), "sum") {
+	case "md5":
+		algorithm = "md5"
+		if len(sum) != hex.EncodedLen(md5.Size) {
+			return "", "", errors.Wrap(ErrChecksum, "unexpected md5 sum length: "+checksum)
+		}
+	case "sha256":
+		algorithm = "sha256"
+		if len(sum) != hex.EncodedLen(sha256.Size) {
+			return "", "", errors.Wrap(ErrChecksum, "unexpected sha256 sum length: "+checksum)
+		}
+	case "":
+		switch len(sum) {
+		case hex.EncodedLen(md5.Size):
+			algorithm = "md5"
+		case hex.EncodedLen(sha256.Size):
+			algorithm = "sha256"
+		default:
+			return "", "", errors.Wrap(ErrChecksum, "unable to identify the algorithm: "+checksum)
+		}
+	default:
+		return "", "", errors.Wrap(ErrChecksum, "unsupported algorithm: "+checksum)
+	}
+
+	return algorithm, sum, nil
+}
+
+func newHash(algorithm string) hash.Hash {
+	if algorithm == "md5" {
+		return md5.New() //nolint:gosec // firmware checksums are published as md5
+	}
+
+	return sha256.New()
+}
+
+// Verify returns the result for each artifact in the order given.
+func (v *Verifier) Verify(ctx context.Context, artifacts []Artifact) []Result {
+	results := make([]Result, len(artifacts))
+
+	concurrency := v.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+
+	for idx := range artifacts {
+		wg.Add(1)
+
+		sem <- struct{}{}
+
+		go func(idx int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			results[idx] = v.verify(ctx, &artifacts[idx])
+		}(idx)
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+func (v *Verifier) verify(ctx context.Context, artifact *Artifact) Result {
+	result := Result{Artifact: *artifact}
+
+	if artifact.URL == "" {
+		result.Status, result.Detail = Invalid, "no URL"
+		return result
+	}
+
+	if u, err := url.Parse(artifact.URL); err != nil || u.Host == "" {
+		result.Status, result.Detail = Invalid, "invalid URL"
+		return result
+	}
+
+	var algorithm, sum string
+
+	if artifact.Source == Repository {
+		if artifact.Checksum == "" {
+			result.Status, result.Detail = Invalid, "no checksum"
+			return result
+		}
+
+		var err error
+
+		algorithm, sum, err = ParseChecksum(artifact.Checksum)
+		if err != nil {
+			result.Status, result.Detail = Invalid, err.Error()
+			return result
+		}
+	}
+
+	headOnly := v.HeadOnly || sum == ""
+
+	method := http.MethodGet
+	if headOnly {
+		method = http.MethodHead
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, artifact.URL, http.NoBody)
+	if err != nil {
+		result.Status, result.Detail = Invalid, err.Error()
+		return result
+	}
+
+	client := v.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		result.Status, result.Detail = Unreachable, err.Error()
+		return result
+	}
+
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		result.Status, result.Detail = Missing, resp.Status
+		return result
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		result.Status, result.Detail = Unreachable, resp.Status
+		return result
+	case headOnly:
+		result.Status = OK
+		return result
+	}
+
+	h := newHash(algorithm)
+	if _, err := io.Copy(h, resp.Body); err != nil {
+		result.Status, result.Detail = Unreachable, err.Error()
+		return result
+	}
+
+	if got := hex.EncodeToString(h.Sum(nil)); got != sum {
+		result.Status, result.Detail = Mismatch, fmt.Sprintf("%s: %s", algorithm, got)
+		return result
+	}
+
+	result.Status = OK
+
+	return result
+}
+
+// Artifacts returns the repository artifact of the firmware, and the upstream artifact when requested.
+func Artifacts(fw *ss.ComponentFirmwareVersion, upstream bool) []Artifact {
+	artifacts := []Artifact{
+		{
+			FirmwareID: fw.UUID.String(),
+			Source:     Repository,
+			Filename:   fw.Filename,
+			URL:        fw.RepositoryURL,
+			Checksum:   fw.Checksum,
+		},
+	}
+
+	if upstream {
+		artifacts = append(artifacts, Artifact{
+			FirmwareID: fw.UUID.String(),
+			Source:     Upstream,
+			Filename:   fw.Filename,
+			URL:        fw.UpstreamURL,
+		})
+	}
+
+	return artifacts
+}
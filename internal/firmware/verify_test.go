@@ -0,0 +1,101 @@
+package firmware
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec // firmware checksums are published as md5
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFileServer serves the files as a firmware repository stand-in.
+func newFileServer(t *testing.T, files map[string]string) *httptest.Server {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	server := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestParseChecksum(t *testing.T) {
+	md5sum := "f9a156b4b077c826aa65eb8f1384efc3"
+	sha256sum := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+	testcases := []struct {
+		checksum  string
+		algorithm string
+		sum       string
+		wantErr   bool
+	}{
+		{checksum: "md5sum:" + md5sum, algorithm: "md5", sum: md5sum},
+		{checksum: "MD5:" + md5sum, algorithm: "md5", sum: md5sum},
+		{checksum: "sha256sum:" + sha256sum, algorithm: "sha256", sum: sha256sum},
+		{checksum: sha256sum, algorithm: "sha256", sum: sha256sum},
+		{checksum: md5sum, algorithm: "md5", sum: md5sum},
+		{checksum: "sha1:da39a3ee5e6b4b0d3255bfef95601890afd80709", wantErr: true},
+		{checksum: "md5sum:not-hex", wantErr: true},
+		{checksum: "abcd", wantErr: true},
+		{checksum: "sha256:" + md5sum, wantErr: true},
+		{checksum: "md5:" + sha256sum, wantErr: true},
+		{checksum: "sha256sum:" + sha256sum[:62], wantErr: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.checksum, func(t *testing.T) {
+			algorithm, sum, err := ParseChecksum(tc.checksum)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, ErrChecksum)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.algorithm, algorithm)
+			assert.Equal(t, tc.sum, sum)
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	content := "firmware"
+	md5sum := md5.Sum([]byte(content)) //nolint:gosec // test data
+	sha256sum := sha256.Sum256([]byte(content))
+
+	server := newFileServer(t, map[string]string{"bios.bin": content})
+
+	artifacts := []Artifact{
+		{Source: Repository, URL: server.URL + "/bios.bin", Checksum: "md5sum:" + hex.EncodeToString(md5sum[:])},
+		{Source: Repository, URL: server.URL + "/bios.bin", Checksum: "sha256:" + hex.EncodeToString(sha256sum[:])},
+		{Source: Repository, URL: server.URL + "/bios.bin", Checksum: "md5sum:f9a156b4b077c826aa65eb8f1384efc3"},
+		{Source: Repository, URL: server.URL + "/bmc.bin", Checksum: "md5sum:f9a156b4b077c826aa65eb8f1384efc3"},
+		{Source: Repository, URL: server.URL + "/bios.bin"},
+		{Source: Repository, Checksum: "md5sum:f9a156b4b077c826aa65eb8f1384efc3"},
+		{Source: Upstream, URL: server.URL + "/bios.bin"},
+		{Source: Upstream, URL: server.URL + "/bmc.bin"},
+	}
+
+	verifier := &Verifier{Client: server.Client(), Concurrency: 3}
+
+	got := []Status{}
+	for _, r := range verifier.Verify(context.Background(), artifacts) {
+		got = append(got, r.Status)
+	}
+
+	assert.Equal(t, []Status{OK, OK, Mismatch, Missing, Invalid, Invalid, OK, Missing}, got)
+
+	headOnly := &Verifier{Client: server.Client(), HeadOnly: true}
+	results := headOnly.Verify(context.Background(), artifacts[2:3])
+	assert.Equal(t, OK, results[0].Status, "checksums aren't verified with HEAD requests")
+}
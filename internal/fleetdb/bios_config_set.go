@@ -0,0 +1,187 @@
+package fleetdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	ss "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrInvalidBiosConfigSet = errors.New("invalid bios config set")
+)
+
+// ValidateBiosConfigSet checks the BIOS config set payload has the fields fleetdb requires,
+// all problems found are returned in the error.
+func ValidateBiosConfigSet(set *ss.BiosConfigSet) error {
+	problems := []string{}
+
+	if strings.TrimSpace(set.Name) == "" {
+		problems = append(problems, "name is required")
+	}
+
+	if strings.TrimSpace(set.Version) == "" {
+		problems = append(problems, "version is required")
+	}
+
+	if len(set.Components) == 0 {
+		problems = append(problems, "at least one component is required")
+	}
+
+	for idx := range set.Components {
+		problems = append(problems, componentProblems(idx, &set.Components[idx])...)
+	}
+
+	if len(problems) > 0 {
+		return errors.Wrap(ErrInvalidBiosConfigSet, strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+func componentProblems(idx int, component *ss.BiosConfigComponent) []string {
+	problems := []string{}
+	prefix := fmt.Sprintf("components[%d]", idx)
+
+	// the vendor and model are optional, components without them apply to all servers
+	if strings.TrimSpace(component.Name) == "" {
+		problems = append(problems, prefix+": name is required")
+	}
+
+	if len(component.Settings) == 0 {
+		problems = append(problems, prefix+": at least one setting is required")
+	}
+
+	keys := map[string]bool{}
+
+	for sidx, setting := range component.Settings {
+		settingPrefix := fmt.Sprintf("%s.settings[%d]", prefix, sidx)
+
+		switch {
+		case strings.TrimSpace(setting.Key) == "":
+			problems = append(problems, settingPrefix+": key is required")
+		case keys[setting.Key]:
+			problems = append(problems, fmt.Sprintf("%s: duplicate key %s", settingPrefix, setting.Key))
+		}
+
+		keys[setting.Key] = true
+
+		if len(setting.Raw) > 0 && !json.Valid(setting.Raw) {
+			problems = append(problems, settingPrefix+": raw is not valid JSON")
+		}
+	}
+
+	return problems
+}
+
+// PatchBiosConfigSet updates, adds and removes settings of the set components with the name,
+// all components are patched when the name is empty.
+//
+// Settings not in the components are added when a single component is patched.
+func PatchBiosConfigSet(set *ss.BiosConfigSet, componentName string, settings map[string]string, remove []string) error {
+	components := []*ss.BiosConfigComponent{}
+	for idx := range set.Components {
+		if componentName == "" || strings.EqualFold(set.Components[idx].Name, componentName) {
+			components = append(components, &set.Components[idx])
+		}
+	}
+
+	if len(components) == 0 {
+		return errors.Wrap(ErrInvalidBiosConfigSet, "no component named "+componentName)
+	}
+
+	for _, key := range sortedKeys(settings) {
+		if updateSetting(components, key, settings[key]) {
+			continue
+		}
+
+		if len(components) > 1 {
+			return errors.Wrap(
+				ErrInvalidBiosConfigSet,
+				fmt.Sprintf("setting %s is not in the set, select the component to add it to", key),
+			)
+		}
+
+		components[0].Settings = append(components[0].Settings, ss.BiosConfigSetting{Key: key, Value: settings[key]})
+	}
+
+	for _, key := range remove {
+		if !removeSetting(components, key) {
+			return errors.Wrap(ErrInvalidBiosConfigSet, fmt.Sprintf("setting %s is not in the set", key))
+		}
+	}
+
+	return nil
+}
+
+func updateSetting(components []*ss.BiosConfigComponent, key, value string) bool {
+	found := false
+
+	for _, component := range components {
+		for idx := range component.Settings {
+			if component.Settings[idx].Key == key {
+				component.Settings[idx].Value = value
+				component.Settings[idx].Raw = nil
+				found = true
+			}
+		}
+	}
+
+	return found
+}
+
+func removeSetting(components []*ss.BiosConfigComponent, key string) bool {
+	found := false
+
+	for _, component := range components {
+		kept := make([]ss.BiosConfigSetting, 0, len(component.Settings))
+		for _, setting := range component.Settings {
+			if setting.Key == key {
+				found = true
+				continue
+			}
+
+			kept = append(kept, setting)
+		}
+
+		component.Settings = kept
+	}
+
+	return found
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// BiosConfigSetSettings returns the settings of the set components for the vendor and model,
+// components without a vendor or model apply to all servers.
+func BiosConfigSetSettings(set *ss.BiosConfigSet, vendor, model string) map[string]string {
+	settings := map[string]string{}
+
+	for _, component := range set.Components {
+		if component.Vendor != "" && !strings.EqualFold(component.Vendor, vendor) {
+			continue
+		}
+
+		if component.Model != "" && !strings.EqualFold(component.Model, model) {
+			continue
+		}
+
+		for _, setting := range component.Settings {
+			settings[setting.Key] = setting.Value
+		}
+	}
+
+	return settings
+}
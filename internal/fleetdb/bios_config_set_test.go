@@ -0,0 +1,191 @@
+package fleetdb
+
+import (
+	"encoding/json"
+	"testing"
+
+	ss "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testBiosConfigSet() *ss.BiosConfigSet {
+	return &ss.BiosConfigSet{
+		Name:    "r6515",
+		Version: "1",
+		Components: []ss.BiosConfigComponent{
+			{
+				Name:   "bios",
+				Vendor: "dell",
+				Model:  "r6515",
+				Settings: []ss.BiosConfigSetting{
+					{Key: "BootMode", Value: "Bios"},
+					{Key: "PxeDev1EnDis", Value: "Enabled"},
+				},
+			},
+			{
+				Name:   "nic",
+				Vendor: "dell",
+				Model:  "r6515",
+				Settings: []ss.BiosConfigSetting{
+					{Key: "BootMode", Value: "Bios"},
+				},
+			},
+		},
+	}
+}
+
+func TestValidateBiosConfigSet(t *testing.T) {
+	testcases := []struct {
+		name     string
+		mutate   func(*ss.BiosConfigSet)
+		contains []string
+	}{
+		{
+			name:   "valid",
+			mutate: func(*ss.BiosConfigSet) {},
+		},
+		{
+			name: "missing name and version",
+			mutate: func(s *ss.BiosConfigSet) {
+				s.Name = " "
+				s.Version = ""
+			},
+			contains: []string{"name is required", "version is required"},
+		},
+		{
+			name:     "no components",
+			mutate:   func(s *ss.BiosConfigSet) { s.Components = nil },
+			contains: []string{"at least one component is required"},
+		},
+		{
+			name: "components for all servers",
+			mutate: func(s *ss.BiosConfigSet) {
+				s.Components[1].Vendor = ""
+				s.Components[1].Model = ""
+			},
+		},
+		{
+			name: "component fields",
+			mutate: func(s *ss.BiosConfigSet) {
+				s.Components[1].Name = ""
+				s.Components[1].Settings = nil
+			},
+			contains: []string{"components[1]: name is required", "components[1]: at least one setting is required"},
+		},
+		{
+			name: "settings",
+			mutate: func(s *ss.BiosConfigSet) {
+				s.Components[0].Settings = append(s.Components[0].Settings,
+					ss.BiosConfigSetting{Key: "BootMode"},
+					ss.BiosConfigSetting{Key: ""},
+					ss.BiosConfigSetting{Key: "Raw", Raw: json.RawMessage(`{`)},
+				)
+			},
+			contains: []string{
+				"components[0].settings[2]: duplicate key BootMode",
+				"components[0].settings[3]: key is required",
+				"components[0].settings[4]: raw is not valid JSON",
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			set := testBiosConfigSet()
+			tc.mutate(set)
+
+			err := ValidateBiosConfigSet(set)
+			if len(tc.contains) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, ErrInvalidBiosConfigSet)
+			for _, c := range tc.contains {
+				assert.Contains(t, err.Error(), c)
+			}
+		})
+	}
+}
+
+func TestPatchBiosConfigSet(t *testing.T) {
+	testcases := []struct {
+		name      string
+		component string
+		settings  map[string]string
+		remove    []string
+		wantErr   bool
+		want      map[string][]ss.BiosConfigSetting
+	}{
+		{
+			name:     "update all components",
+			settings: map[string]string{"BootMode": "Uefi"},
+			want: map[string][]ss.BiosConfigSetting{
+				"bios": {{Key: "BootMode", Value: "Uefi"}, {Key: "PxeDev1EnDis", Value: "Enabled"}},
+				"nic":  {{Key: "BootMode", Value: "Uefi"}},
+			},
+		},
+		{
+			name:      "add and remove in a component",
+			component: "BIOS",
+			settings:  map[string]string{"SriovGlobalEnable": "Enabled"},
+			remove:    []string{"PxeDev1EnDis"},
+			want: map[string][]ss.BiosConfigSetting{
+				"bios": {{Key: "BootMode", Value: "Bios"}, {Key: "SriovGlobalEnable", Value: "Enabled"}},
+				"nic":  {{Key: "BootMode", Value: "Bios"}},
+			},
+		},
+		{
+			name:     "add without a component",
+			settings: map[string]string{"SriovGlobalEnable": "Enabled"},
+			wantErr:  true,
+		},
+		{
+			name:      "unknown component",
+			component: "raid",
+			settings:  map[string]string{"BootMode": "Uefi"},
+			wantErr:   true,
+		},
+		{
+			name:      "remove unknown setting",
+			component: "nic",
+			remove:    []string{"PxeDev1EnDis"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			set := testBiosConfigSet()
+
+			err := PatchBiosConfigSet(set, tc.component, tc.settings, tc.remove)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidBiosConfigSet)
+				return
+			}
+
+			require.NoError(t, err)
+
+			got := map[string][]ss.BiosConfigSetting{}
+			for _, c := range set.Components {
+				got[c.Name] = c.Settings
+			}
+
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestBiosConfigSetSettings(t *testing.T) {
+	set := &ss.BiosConfigSet{
+		Components: []ss.BiosConfigComponent{
+			{Vendor: "dell", Model: "r6515", Settings: []ss.BiosConfigSetting{{Key: "BootMode", Value: "Uefi"}}},
+			{Vendor: "supermicro", Settings: []ss.BiosConfigSetting{{Key: "Boot mode select", Value: "UEFI"}}},
+			{Settings: []ss.BiosConfigSetting{{Key: "TPM", Value: "Enabled"}}},
+		},
+	}
+
+	assert.Equal(t, map[string]string{"BootMode": "Uefi", "TPM": "Enabled"}, BiosConfigSetSettings(set, "Dell", "R6515"))
+	assert.Equal(t, map[string]string{"TPM": "Enabled"}, BiosConfigSetSettings(set, "dell", "r640"))
+}
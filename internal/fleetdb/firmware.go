@@ -0,0 +1,90 @@
+package fleetdb
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+	ss "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrInvalidFirmware = errors.New("invalid firmware")
+)
+
+// ValidateFirmware checks the firmware payload has the fields fleetdb requires,
+// all problems found are returned in the error.
+func ValidateFirmware(fw *ss.ComponentFirmwareVersion) error {
+	problems := []string{}
+
+	required := []struct{ field, value string }{
+		{"vendor", fw.Vendor},
+		{"filename", fw.Filename},
+		{"version", fw.Version},
+		{"component", fw.Component},
+		{"checksum", fw.Checksum},
+		{"upstream_url", fw.UpstreamURL},
+		{"repository_url", fw.RepositoryURL},
+	}
+
+	for _, r := range required {
+		if strings.TrimSpace(r.value) == "" {
+			problems = append(problems, r.field+" is required")
+		}
+	}
+
+	if len(fw.Model) == 0 {
+		problems = append(problems, "at least one model is required")
+	}
+
+	// fleetdb requires these in lower case
+	lowercase := []struct{ field, value string }{
+		{"vendor", fw.Vendor},
+		{"component", fw.Component},
+		{"checksum", fw.Checksum},
+	}
+
+	for _, l := range lowercase {
+		if l.value != strings.ToLower(l.value) {
+			problems = append(problems, l.field+" must be lower case")
+		}
+	}
+
+	for _, u := range []struct{ field, value string }{
+		{"upstream_url", fw.UpstreamURL},
+		{"repository_url", fw.RepositoryURL},
+	} {
+		if u.value == "" {
+			continue
+		}
+
+		parsed, err := url.Parse(u.value)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			problems = append(problems, fmt.Sprintf("%s is not an absolute http(s) URL: %s", u.field, u.value))
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.Wrap(ErrInvalidFirmware, strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// FirmwareSetsWithFirmware returns the sets the firmware is a member of.
+func FirmwareSetsWithFirmware(sets []ss.ComponentFirmwareSet, id uuid.UUID) []ss.ComponentFirmwareSet {
+	found := []ss.ComponentFirmwareSet{}
+
+	for idx := range sets {
+		for _, fw := range sets[idx].ComponentFirmware {
+			if fw.UUID == id {
+				found = append(found, sets[idx])
+				break
+			}
+		}
+	}
+
+	return found
+}
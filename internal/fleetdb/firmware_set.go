@@ -0,0 +1,204 @@
+package fleetdb
+
+import (
+	"encoding/json"
+	"maps"
+	"strings"
+
+	"github.com/google/uuid"
+	ss "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Firmware set labels identifying the set installed on servers of a vendor, model.
+	FirmwareSetLabelLatest  = "latest"
+	FirmwareSetLabelDefault = "default"
+
+	// Firmware set label recording the set a promoted set took the latest, default labels from.
+	FirmwareSetLabelPrevious = "previous-set"
+)
+
+var (
+	ErrFirmwareSetLabels    = errors.New("invalid firmware set labels")
+	ErrFirmwareSetPromotion = errors.New("unable to promote firmware set")
+)
+
+// FirmwareSetLabelUpdate is a change to the labels of a firmware set.
+type FirmwareSetLabelUpdate struct {
+	ID        uuid.UUID         `json:"uuid"`
+	Name      string            `json:"name"`
+	OldLabels map[string]string `json:"old_labels"`
+	NewLabels map[string]string `json:"new_labels"`
+}
+
+// FirmwareSetLabels returns the labels of the firmware set, a set without labels returns an empty map.
+func FirmwareSetLabels(set *ss.ComponentFirmwareSet) (map[string]string, error) {
+	labels := map[string]string{}
+
+	for idx := range set.Attributes {
+		if set.Attributes[idx].Namespace != FirmwareSetAttributeNS {
+			continue
+		}
+
+		if err := json.Unmarshal(set.Attributes[idx].Data, &labels); err != nil {
+			return nil, errors.Wrap(ErrFirmwareSetLabels, set.UUID.String()+": "+err.Error())
+		}
+	}
+
+	return labels, nil
+}
+
+// PromotedFirmwareSets returns the sets for the vendor, model labeled latest and default.
+func PromotedFirmwareSets(sets []ss.ComponentFirmwareSet, vendor, model string) ([]ss.ComponentFirmwareSet, error) {
+	promoted := []ss.ComponentFirmwareSet{}
+
+	for idx := range sets {
+		labels, err := FirmwareSetLabels(&sets[idx])
+		if err != nil {
+			return nil, err
+		}
+
+		if forVendorModel(labels, vendor, model) && labelTrue(labels, FirmwareSetLabelLatest) && labelTrue(labels, FirmwareSetLabelDefault) {
+			promoted = append(promoted, sets[idx])
+		}
+	}
+
+	return promoted, nil
+}
+
+// PromoteFirmwareSet returns the label updates that move the latest and default labels to the set
+// with the ID from the other sets of its vendor and model. The updates removing labels come first,
+// the update of the promoted set is last.
+//
+// The promoted set records the set it took the labels from in the previous-set label, the record
+// is removed when no other set or more than one other set held the labels. No updates are returned when the set is
+// already the only set holding the labels.
+func PromoteFirmwareSet(sets []ss.ComponentFirmwareSet, id uuid.UUID) ([]FirmwareSetLabelUpdate, error) {
+	var target *ss.ComponentFirmwareSet
+
+	for idx := range sets {
+		if sets[idx].UUID == id {
+			target = &sets[idx]
+			break
+		}
+	}
+
+	if target == nil {
+		return nil, errors.Wrap(ErrFirmwareSetPromotion, "firmware set not found: "+id.String())
+	}
+
+	targetLabels, err := FirmwareSetLabels(target)
+	if err != nil {
+		return nil, err
+	}
+
+	vendor, model := targetLabels["vendor"], targetLabels["model"]
+	if vendor == "" || model == "" {
+		return nil, errors.Wrap(ErrFirmwareSetPromotion, "firmware set requires vendor and model labels: "+id.String())
+	}
+
+	updates := []FirmwareSetLabelUpdate{}
+	previous := []uuid.UUID{}
+
+	for idx := range sets {
+		set := &sets[idx]
+		if set.UUID == id {
+			continue
+		}
+
+		labels, err := FirmwareSetLabels(set)
+		if err != nil {
+			return nil, err
+		}
+
+		if !forVendorModel(labels, vendor, model) {
+			continue
+		}
+
+		latest, isDefault := labelTrue(labels, FirmwareSetLabelLatest), labelTrue(labels, FirmwareSetLabelDefault)
+		if !latest && !isDefault {
+			continue
+		}
+
+		if latest && isDefault {
+			previous = append(previous, set.UUID)
+		}
+
+		newLabels := maps.Clone(labels)
+		delete(newLabels, FirmwareSetLabelLatest)
+		delete(newLabels, FirmwareSetLabelDefault)
+
+		updates = append(updates, FirmwareSetLabelUpdate{ID: set.UUID, Name: set.Name, OldLabels: labels, NewLabels: newLabels})
+	}
+
+	newLabels := maps.Clone(targetLabels)
+	newLabels[FirmwareSetLabelLatest] = "true"
+	newLabels[FirmwareSetLabelDefault] = "true"
+
+	// a set that already held the labels keeps its previous record
+	targetHeld := labelTrue(targetLabels, FirmwareSetLabelLatest) && labelTrue(targetLabels, FirmwareSetLabelDefault)
+
+	switch {
+	case targetHeld:
+	case len(previous) == 1:
+		newLabels[FirmwareSetLabelPrevious] = previous[0].String()
+	default:
+		delete(newLabels, FirmwareSetLabelPrevious)
+	}
+
+	if !maps.Equal(targetLabels, newLabels) {
+		updates = append(updates, FirmwareSetLabelUpdate{ID: id, Name: target.Name, OldLabels: targetLabels, NewLabels: newLabels})
+	}
+
+	return updates, nil
+}
+
+func forVendorModel(labels map[string]string, vendor, model string) bool {
+	return strings.EqualFold(labels["vendor"], vendor) && strings.EqualFold(labels["model"], model)
+}
+
+func labelTrue(labels map[string]string, key string) bool {
+	return strings.EqualFold(labels[key], "true")
+}
+
+// EditFirmwareSetLabels returns the labels of a firmware set after an edit, the labels are merged
+// into the current labels or replace them, the remove keys are removed from the result.
+// An edit that drops the vendor or model label of the set is refused.
+func EditFirmwareSetLabels(current, labels map[string]string, remove []string, replace bool) (map[string]string, error) {
+	edited := maps.Clone(current)
+	if replace || edited == nil {
+		edited = map[string]string{}
+	}
+
+	maps.Copy(edited, labels)
+
+	for _, key := range remove {
+		if _, exists := labels[key]; exists {
+			return nil, errors.Wrap(ErrFirmwareSetLabels, "label is both set and removed: "+key)
+		}
+
+		delete(edited, key)
+	}
+
+	for _, key := range []string{"vendor", "model"} {
+		if current[key] != "" && edited[key] == "" {
+			return nil, errors.Wrap(ErrFirmwareSetLabels, "the edit drops the label: "+key)
+		}
+	}
+
+	return edited, nil
+}
+
+// FirmwareSetComponentFirmware returns the IDs of the set firmware for the component slug.
+func FirmwareSetComponentFirmware(set *ss.ComponentFirmwareSet, component string) []uuid.UUID {
+	ids := []uuid.UUID{}
+
+	for idx := range set.ComponentFirmware {
+		if strings.EqualFold(set.ComponentFirmware[idx].Component, component) {
+			ids = append(ids, set.ComponentFirmware[idx].UUID)
+		}
+	}
+
+	return ids
+}
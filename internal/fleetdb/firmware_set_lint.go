@@ -0,0 +1,196 @@
+package fleetdb
+
+import (
+	"cmp"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	ss "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+)
+
+// Severity is the severity of a lint finding.
+type Severity string
+
+const (
+	// SeverityError is a problem that prevents the set from being installed correctly.
+	SeverityError Severity = "error"
+
+	// SeverityWarning is a problem that might be intended - a firmware downgrade.
+	SeverityWarning Severity = "warning"
+)
+
+// LintFinding is a problem found in a firmware set.
+type LintFinding struct {
+	Severity Severity `json:"severity"`
+	SetName  string   `json:"set_name"`
+
+	// FirmwareID and Component identify the firmware, empty for problems with the set.
+	FirmwareID string `json:"firmware_id,omitempty"`
+	Component  string `json:"component,omitempty"`
+	Message    string `json:"message"`
+}
+
+// LintFirmwareSet returns the problems found in the firmware set, errors are listed before warnings.
+//
+// The versions of the set firmware are compared to the firmware of the deployed set for the vendor
+// and model when deployed is not nil, firmware older than the deployed version is a warning.
+func LintFirmwareSet(set, deployed *ss.ComponentFirmwareSet) []LintFinding {
+	findings := []LintFinding{}
+
+	add := func(severity Severity, fw *ss.ComponentFirmwareVersion, message string) {
+		f := LintFinding{Severity: severity, SetName: set.Name, Message: message}
+		if fw != nil {
+			f.FirmwareID, f.Component = fw.UUID.String(), fw.Component
+		}
+
+		findings = append(findings, f)
+	}
+
+	labels, err := FirmwareSetLabels(set)
+	if err != nil {
+		add(SeverityError, nil, err.Error())
+	}
+
+	vendor, model := labels["vendor"], labels["model"]
+	if vendor == "" || model == "" {
+		add(SeverityError, nil, "set requires vendor and model labels")
+	}
+
+	if len(set.ComponentFirmware) == 0 {
+		add(SeverityError, nil, "set has no firmware")
+	}
+
+	seenIDs := map[string]bool{}
+	seenComponents := map[string]string{}
+
+	for idx := range set.ComponentFirmware {
+		fw := &set.ComponentFirmware[idx]
+
+		if seenIDs[fw.UUID.String()] {
+			add(SeverityError, fw, "firmware is listed more than once")
+			continue
+		}
+
+		seenIDs[fw.UUID.String()] = true
+
+		key := firmwareKey(fw)
+		if other, exists := seenComponents[key]; exists {
+			add(SeverityError, fw, fmt.Sprintf("duplicate %s firmware for models [%s], also in firmware %s", fw.Component, strings.Join(fw.Model, ", "), other))
+		} else {
+			seenComponents[key] = fw.UUID.String()
+		}
+
+		if err := ValidateFirmware(fw); err != nil {
+			add(SeverityError, fw, err.Error())
+		}
+
+		if model != "" && !containsFold(fw.Model, model) {
+			add(SeverityError, fw, fmt.Sprintf("firmware models [%s] don't include the set model %s", strings.Join(fw.Model, ", "), model))
+		}
+
+		if vendor != "" && !strings.EqualFold(fw.Vendor, vendor) {
+			add(SeverityWarning, fw, fmt.Sprintf("firmware vendor %s doesn't match the set vendor %s", fw.Vendor, vendor))
+		}
+
+		if deployed == nil || deployed.UUID == set.UUID {
+			continue
+		}
+
+		for jdx := range deployed.ComponentFirmware {
+			current := &deployed.ComponentFirmware[jdx]
+			if firmwareKey(current) == key && CompareFirmwareVersions(fw.Version, current.Version) < 0 {
+				add(SeverityWarning, fw, fmt.Sprintf("version %s is older than %s in the deployed set %s", fw.Version, current.Version, deployed.Name))
+			}
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Severity == SeverityError && findings[j].Severity != SeverityError
+	})
+
+	return findings
+}
+
+// firmwareKey identifies the firmware for a component of a server model,
+// a set with more than one firmware for the same key is ambiguous.
+func firmwareKey(fw *ss.ComponentFirmwareVersion) string {
+	models := make([]string, 0, len(fw.Model))
+	for _, m := range fw.Model {
+		models = append(models, strings.ToLower(strings.TrimSpace(m)))
+	}
+
+	sort.Strings(models)
+
+	return strings.ToLower(fw.Component) + "/" + strings.ToLower(fw.Vendor) + "/" + strings.Join(models, ",")
+}
+
+// CompareFirmwareVersions compares two firmware versions, returning -1, 0 or 1 when a is older, equal or newer than b.
+//
+// Versions are compared by their numeric and non-numeric parts - '2.19.1' is newer than '2.9.3',
+// non-numeric parts are compared case insensitively.
+func CompareFirmwareVersions(a, b string) int {
+	pa, pb := versionParts(a), versionParts(b)
+
+	for idx := 0; idx < len(pa) && idx < len(pb); idx++ {
+		na, errA := strconv.ParseUint(pa[idx], 10, 64)
+		nb, errB := strconv.ParseUint(pb[idx], 10, 64)
+
+		c := strings.Compare(strings.ToLower(pa[idx]), strings.ToLower(pb[idx]))
+		if errA == nil && errB == nil {
+			c = cmp.Compare(na, nb)
+		}
+
+		if c != 0 {
+			return c
+		}
+	}
+
+	return cmp.Compare(len(pa), len(pb))
+}
+
+// versionParts splits a version into runs of digits and runs of other characters, separators are dropped.
+func versionParts(version string) []string {
+	parts := []string{}
+
+	var current strings.Builder
+
+	digits := false
+
+	flush := func() {
+		if current.Len() > 0 {
+			parts = append(parts, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, r := range version {
+		switch {
+		case r == '.' || r == '-' || r == '_' || unicode.IsSpace(r):
+			flush()
+		case unicode.IsDigit(r) != digits:
+			flush()
+			digits = unicode.IsDigit(r)
+
+			current.WriteRune(r)
+		default:
+			current.WriteRune(r)
+		}
+	}
+
+	flush()
+
+	return parts
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), value) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,133 @@
+package fleetdb
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	ss "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func testSetFirmware(component, version string, models ...string) ss.ComponentFirmwareVersion {
+	return ss.ComponentFirmwareVersion{
+		UUID:          uuid.New(),
+		Vendor:        "dell",
+		Model:         models,
+		Filename:      component + "_" + version + ".EXE",
+		Version:       version,
+		Component:     component,
+		Checksum:      "md5sum:f9a156b4b077c826aa65eb8f1384efc3",
+		UpstreamURL:   "https://dl.dell.com/" + component + "_" + version + ".EXE",
+		RepositoryURL: "https://firmware.example.com/dell/" + component + "_" + version + ".EXE",
+	}
+}
+
+func TestLintFirmwareSet(t *testing.T) {
+	labels := map[string]string{"vendor": "dell", "model": "r6515"}
+
+	deployed := testFirmwareSet(t, "r6515-2024", labels)
+	deployed.ComponentFirmware = []ss.ComponentFirmwareVersion{
+		testSetFirmware("bios", "2.19.1", "r6515"),
+		testSetFirmware("bmc", "7.00.00.00", "r6515"),
+	}
+
+	testcases := []struct {
+		name     string
+		labels   map[string]string
+		firmware func() []ss.ComponentFirmwareVersion
+		deployed *ss.ComponentFirmwareSet
+		want     []LintFinding
+	}{
+		{
+			name:   "valid",
+			labels: labels,
+			firmware: func() []ss.ComponentFirmwareVersion {
+				return []ss.ComponentFirmwareVersion{
+					testSetFirmware("bios", "2.20.0", "r6515"),
+					testSetFirmware("bmc", "7.10.30.00", "r6515"),
+					testSetFirmware("storagecontroller", "2.5.13", "boss-s1", "r6515"),
+					testSetFirmware("storagecontroller", "52.16.1", "perc", "r6515"),
+				}
+			},
+			deployed: &deployed,
+			want:     []LintFinding{},
+		},
+		{
+			name:   "set problems",
+			labels: map[string]string{"vendor": "dell"},
+			firmware: func() []ss.ComponentFirmwareVersion {
+				return nil
+			},
+			want: []LintFinding{
+				{Severity: SeverityError, Message: "set requires vendor and model labels"},
+				{Severity: SeverityError, Message: "set has no firmware"},
+			},
+		},
+		{
+			name:   "firmware problems",
+			labels: labels,
+			firmware: func() []ss.ComponentFirmwareVersion {
+				bios := testSetFirmware("bios", "2.9.3", "r6515")
+				bmc := testSetFirmware("bmc", "7.10.30.00", "R6515")
+				bmc.Checksum = ""
+				duplicate := testSetFirmware("bmc", "7.10.50.00", "r6515")
+				nic := testSetFirmware("nic", "22.5", "r6615")
+				nic.Vendor = "broadcom"
+
+				return []ss.ComponentFirmwareVersion{bios, bmc, duplicate, nic, bios}
+			},
+			deployed: &deployed,
+			want: []LintFinding{
+				{Severity: SeverityError, Component: "bmc", Message: "checksum is required"},
+				{Severity: SeverityError, Component: "bmc", Message: "duplicate bmc firmware for models [r6515]"},
+				{Severity: SeverityError, Component: "nic", Message: "firmware models [r6615] don't include the set model r6515"},
+				{Severity: SeverityError, Component: "bios", Message: "firmware is listed more than once"},
+				{Severity: SeverityWarning, Component: "bios", Message: "version 2.9.3 is older than 2.19.1 in the deployed set r6515-2024"},
+				{Severity: SeverityWarning, Component: "nic", Message: "firmware vendor broadcom doesn't match the set vendor dell"},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			set := testFirmwareSet(t, "r6515-2025", tc.labels)
+			set.ComponentFirmware = tc.firmware()
+
+			got := LintFirmwareSet(&set, tc.deployed)
+			if !assert.Len(t, got, len(tc.want)) {
+				t.Log(got)
+				return
+			}
+
+			for idx, want := range tc.want {
+				assert.Equal(t, want.Severity, got[idx].Severity)
+				assert.Equal(t, want.Component, got[idx].Component)
+				assert.Contains(t, got[idx].Message, want.Message)
+				assert.Equal(t, set.Name, got[idx].SetName)
+			}
+		})
+	}
+}
+
+func TestCompareFirmwareVersions(t *testing.T) {
+	testcases := []struct {
+		a, b string
+		want int
+	}{
+		{"2.19.1", "2.9.3", 1},
+		{"2.19.1", "2.19.1", 0},
+		{"1.74.14", "1.74.9", 1},
+		{"7.00.00.00", "7.10.30.00", -1},
+		{"3.9", "3.10", -1},
+		{"2.1a", "2.1b", -1},
+		{"2.1", "2.1.1", -1},
+		{"X11DPH9.B23", "x11dph9.b22", 1},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.a+"_"+tc.b, func(t *testing.T) {
+			assert.Equal(t, tc.want, CompareFirmwareVersions(tc.a, tc.b))
+			assert.Equal(t, -tc.want, CompareFirmwareVersions(tc.b, tc.a))
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package fleetdb
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	ss "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFirmwareSet(t *testing.T, name string, labels map[string]string) ss.ComponentFirmwareSet {
+	t.Helper()
+
+	data, err := json.Marshal(labels)
+	require.NoError(t, err)
+
+	return ss.ComponentFirmwareSet{
+		UUID:       uuid.New(),
+		Name:       name,
+		Attributes: []ss.Attributes{{Namespace: FirmwareSetAttributeNS, Data: data}},
+	}
+}
+
+func TestPromoteFirmwareSet(t *testing.T) {
+	current := testFirmwareSet(t, "r6515-2024", map[string]string{"vendor": "dell", "model": "r6515", "latest": "true", "default": "true"})
+	stale := testFirmwareSet(t, "r6515-2023", map[string]string{"vendor": "dell", "model": "r6515", "latest": "true"})
+	next := testFirmwareSet(t, "r6515-2025", map[string]string{"vendor": "dell", "model": "r6515"})
+	other := testFirmwareSet(t, "r6615-2024", map[string]string{"vendor": "dell", "model": "r6615", "latest": "true", "default": "true"})
+	unlabeled := testFirmwareSet(t, "unlabeled", map[string]string{})
+
+	sets := []ss.ComponentFirmwareSet{current, stale, next, other, unlabeled}
+
+	updates, err := PromoteFirmwareSet(sets, next.UUID)
+	require.NoError(t, err)
+
+	want := []FirmwareSetLabelUpdate{
+		{
+			ID:        current.UUID,
+			Name:      current.Name,
+			OldLabels: map[string]string{"vendor": "dell", "model": "r6515", "latest": "true", "default": "true"},
+			NewLabels: map[string]string{"vendor": "dell", "model": "r6515"},
+		},
+		{
+			ID:        stale.UUID,
+			Name:      stale.Name,
+			OldLabels: map[string]string{"vendor": "dell", "model": "r6515", "latest": "true"},
+			NewLabels: map[string]string{"vendor": "dell", "model": "r6515"},
+		},
+		{
+			ID:        next.UUID,
+			Name:      next.Name,
+			OldLabels: map[string]string{"vendor": "dell", "model": "r6515"},
+			NewLabels: map[string]string{
+				"vendor": "dell", "model": "r6515", "latest": "true", "default": "true", "previous-set": current.UUID.String(),
+			},
+		},
+	}
+
+	assert.Equal(t, want, updates)
+
+	// the current set is the only set holding the labels for r6615
+	updates, err = PromoteFirmwareSet(sets, other.UUID)
+	require.NoError(t, err)
+	assert.Empty(t, updates)
+
+	// two sets holding the labels leaves the previous set ambiguous
+	duplicate := testFirmwareSet(t, "r6515-dup", map[string]string{
+		"vendor": "dell", "model": "r6515", "latest": "true", "default": "true", "previous-set": uuid.NewString(),
+	})
+
+	updates, err = PromoteFirmwareSet([]ss.ComponentFirmwareSet{current, duplicate, next}, next.UUID)
+	require.NoError(t, err)
+	require.Len(t, updates, 3)
+	assert.NotContains(t, updates[2].NewLabels, FirmwareSetLabelPrevious)
+
+	// no set held the labels, a stale previous record is removed
+	recorded := testFirmwareSet(t, "r6515-2026", map[string]string{"vendor": "dell", "model": "r6515", "previous-set": current.UUID.String()})
+
+	updates, err = PromoteFirmwareSet([]ss.ComponentFirmwareSet{stale, recorded}, recorded.UUID)
+	require.NoError(t, err)
+	require.Len(t, updates, 2)
+	assert.Equal(t, map[string]string{"vendor": "dell", "model": "r6515", "latest": "true", "default": "true"}, updates[1].NewLabels)
+
+	// re-promoting a set that shares the labels keeps its previous record
+	updates, err = PromoteFirmwareSet([]ss.ComponentFirmwareSet{current, duplicate}, duplicate.UUID)
+	require.NoError(t, err)
+	require.Len(t, updates, 1)
+	assert.Equal(t, current.UUID, updates[0].ID)
+
+	_, err = PromoteFirmwareSet(sets, unlabeled.UUID)
+	assert.ErrorIs(t, err, ErrFirmwareSetPromotion)
+
+	_, err = PromoteFirmwareSet(sets, uuid.New())
+	assert.ErrorIs(t, err, ErrFirmwareSetPromotion)
+}
+
+func TestPromotedFirmwareSets(t *testing.T) {
+	current := testFirmwareSet(t, "r6515-2024", map[string]string{"vendor": "dell", "model": "r6515", "latest": "true", "default": "true"})
+	stale := testFirmwareSet(t, "r6515-2023", map[string]string{"vendor": "dell", "model": "r6515", "latest": "true"})
+	other := testFirmwareSet(t, "r6615-2024", map[string]string{"vendor": "dell", "model": "r6615", "latest": "true", "default": "true"})
+
+	promoted, err := PromotedFirmwareSets([]ss.ComponentFirmwareSet{current, stale, other}, "DELL", "R6515")
+	require.NoError(t, err)
+	require.Len(t, promoted, 1)
+	assert.Equal(t, current.UUID, promoted[0].UUID)
+
+	invalid := ss.ComponentFirmwareSet{Attributes: []ss.Attributes{{Namespace: FirmwareSetAttributeNS, Data: []byte("[")}}}
+	_, err = PromotedFirmwareSets([]ss.ComponentFirmwareSet{invalid}, "dell", "r6515")
+	assert.ErrorIs(t, err, ErrFirmwareSetLabels)
+}
+
+func TestEditFirmwareSetLabels(t *testing.T) {
+	current := map[string]string{"vendor": "dell", "model": "r6515", "latest": "true"}
+
+	testcases := []struct {
+		name    string
+		labels  map[string]string
+		remove  []string
+		replace bool
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:   "merge",
+			labels: map[string]string{"latest": "false", "team": "fleet"},
+			want:   map[string]string{"vendor": "dell", "model": "r6515", "latest": "false", "team": "fleet"},
+		},
+		{
+			name:    "replace",
+			labels:  map[string]string{"vendor": "dell", "model": "r6615"},
+			replace: true,
+			want:    map[string]string{"vendor": "dell", "model": "r6615"},
+		},
+		{
+			name:   "remove",
+			remove: []string{"latest", "absent"},
+			want:   map[string]string{"vendor": "dell", "model": "r6515"},
+		},
+		{
+			name:    "replace drops the model",
+			labels:  map[string]string{"vendor": "dell"},
+			replace: true,
+			wantErr: true,
+		},
+		{
+			name:    "remove the vendor",
+			remove:  []string{"vendor"},
+			wantErr: true,
+		},
+		{
+			name:    "set and removed",
+			labels:  map[string]string{"latest": "true"},
+			remove:  []string{"latest"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := EditFirmwareSetLabels(current, tc.labels, tc.remove, tc.replace)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, ErrFirmwareSetLabels)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, "true", current["latest"], "current labels are not modified")
+		})
+	}
+}
+
+func TestFirmwareSetComponentFirmware(t *testing.T) {
+	bios, perc, boss := uuid.New(), uuid.New(), uuid.New()
+
+	set := &ss.ComponentFirmwareSet{
+		ComponentFirmware: []ss.ComponentFirmwareVersion{
+			{UUID: bios, Component: "bios"},
+			{UUID: perc, Component: "storagecontroller"},
+			{UUID: boss, Component: "StorageController"},
+		},
+	}
+
+	assert.Equal(t, []uuid.UUID{bios}, FirmwareSetComponentFirmware(set, "BIOS"))
+	assert.Equal(t, []uuid.UUID{perc, boss}, FirmwareSetComponentFirmware(set, "storagecontroller"))
+	assert.Empty(t, FirmwareSetComponentFirmware(set, "nic"))
+}
@@ -0,0 +1,92 @@
+package fleetdb
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	ss "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateFirmware(t *testing.T) {
+	valid := func() *ss.ComponentFirmwareVersion {
+		return &ss.ComponentFirmwareVersion{
+			Vendor:        "dell",
+			Model:         []string{"r6515"},
+			Filename:      "BIOS_2.19.1.EXE",
+			Version:       "2.19.1",
+			Component:     "bios",
+			Checksum:      "5a3ec1",
+			UpstreamURL:   "https://dl.dell.com/BIOS_2.19.1.EXE",
+			RepositoryURL: "https://firmware.example.com/dell/BIOS_2.19.1.EXE",
+		}
+	}
+
+	testcases := []struct {
+		name     string
+		mutate   func(*ss.ComponentFirmwareVersion)
+		contains []string
+	}{
+		{
+			name:   "valid",
+			mutate: func(*ss.ComponentFirmwareVersion) {},
+		},
+		{
+			name: "required fields",
+			mutate: func(fw *ss.ComponentFirmwareVersion) {
+				fw.Filename = ""
+				fw.Model = nil
+			},
+			contains: []string{"filename is required", "at least one model is required"},
+		},
+		{
+			name: "lower case",
+			mutate: func(fw *ss.ComponentFirmwareVersion) {
+				fw.Vendor = "Dell"
+				fw.Checksum = "5A3EC1"
+			},
+			contains: []string{"vendor must be lower case", "checksum must be lower case"},
+		},
+		{
+			name:     "urls",
+			mutate:   func(fw *ss.ComponentFirmwareVersion) { fw.RepositoryURL = "firmware.example.com/BIOS_2.19.1.EXE" },
+			contains: []string{"repository_url is not an absolute http(s) URL"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			fw := valid()
+			tc.mutate(fw)
+
+			err := ValidateFirmware(fw)
+			if len(tc.contains) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, ErrInvalidFirmware)
+			for _, c := range tc.contains {
+				assert.Contains(t, err.Error(), c)
+			}
+		})
+	}
+}
+
+func TestFirmwareSetsWithFirmware(t *testing.T) {
+	bios, bmc := uuid.New(), uuid.New()
+
+	sets := []ss.ComponentFirmwareSet{
+		{Name: "r6515", ComponentFirmware: []ss.ComponentFirmwareVersion{{UUID: bios}, {UUID: bmc}}},
+		{Name: "r6515-bmc", ComponentFirmware: []ss.ComponentFirmwareVersion{{UUID: bmc}}},
+		{Name: "empty"},
+	}
+
+	found := FirmwareSetsWithFirmware(sets, bios)
+	require.Len(t, found, 1)
+	assert.Equal(t, "r6515", found[0].Name)
+
+	assert.Len(t, FirmwareSetsWithFirmware(sets, bmc), 2)
+	assert.Empty(t, FirmwareSetsWithFirmware(sets, uuid.New()))
+}
@@ -0,0 +1,137 @@
+package fleetdb
+
+import (
+	"sort"
+	"strings"
+
+	ss "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+	rt "github.com/metal-toolbox/rivets/v2/types"
+)
+
+// noComponentReason is the reason firmware that matched no component on the server is skipped.
+const noComponentReason = "no matching component on the server"
+
+// InstallAction is the change a firmware install makes to a component.
+type InstallAction string
+
+const (
+	InstallUpgrade   InstallAction = "upgrade"
+	InstallDowngrade InstallAction = "downgrade"
+	InstallSkip      InstallAction = "skip"
+	// InstallReinstall is a forced install of the installed version.
+	InstallReinstall InstallAction = "reinstall"
+	// InstallUnknown components have firmware in the set but no installed version in the inventory.
+	InstallUnknown InstallAction = "unknown"
+)
+
+// InstallPlanItem is the planned change to a server component, items without a component
+// are firmware that matched no component on the server.
+type InstallPlanItem struct {
+	Component  string        `json:"component"`
+	Vendor     string        `json:"vendor,omitempty"`
+	Model      string        `json:"model,omitempty"`
+	Serial     string        `json:"serial,omitempty"`
+	Installed  string        `json:"installed,omitempty"`
+	Target     string        `json:"target,omitempty"`
+	FirmwareID string        `json:"firmware_id,omitempty"`
+	Action     InstallAction `json:"action"`
+	Reason     string        `json:"reason,omitempty"`
+}
+
+// PlanFirmwareInstall returns the changes installing the firmware makes to the server components.
+//
+// Firmware applies to the components with its component slug and vendor, firmware with models applies
+// only to servers of those models. Components without firmware are skipped, components with the target
+// version installed are skipped unless the install is forced.
+func PlanFirmwareInstall(components []*rt.Component, firmware []ss.ComponentFirmwareVersion, serverModel string, force bool) []InstallPlanItem {
+	plan := []InstallPlanItem{}
+	matched := map[int]bool{}
+
+	for _, component := range components {
+		item := InstallPlanItem{
+			Component: component.Name,
+			Vendor:    component.Vendor,
+			Model:     component.Model,
+			Serial:    component.Serial,
+		}
+
+		if component.Firmware != nil {
+			item.Installed = component.Firmware.Installed
+		}
+
+		idx := firmwareForComponent(component, firmware, serverModel)
+		if idx == -1 {
+			item.Action = InstallSkip
+			item.Reason = "no firmware in set"
+			plan = append(plan, item)
+
+			continue
+		}
+
+		matched[idx] = true
+		fw := &firmware[idx]
+		item.Target = fw.Version
+		item.FirmwareID = fw.UUID.String()
+		item.Action, item.Reason = installAction(item.Installed, fw.Version, force)
+
+		plan = append(plan, item)
+	}
+
+	for idx := range firmware {
+		if matched[idx] {
+			continue
+		}
+
+		plan = append(plan, InstallPlanItem{
+			Component:  firmware[idx].Component,
+			Vendor:     firmware[idx].Vendor,
+			Target:     firmware[idx].Version,
+			FirmwareID: firmware[idx].UUID.String(),
+			Action:     InstallSkip,
+			Reason:     noComponentReason,
+		})
+	}
+
+	sort.SliceStable(plan, func(i, j int) bool {
+		return strings.ToLower(plan[i].Component) < strings.ToLower(plan[j].Component)
+	})
+
+	return plan
+}
+
+// firmwareForComponent returns the index of the firmware for the component of a server of the model,
+// -1 if there is none.
+func firmwareForComponent(component *rt.Component, firmware []ss.ComponentFirmwareVersion, serverModel string) int {
+	for idx := range firmware {
+		fw := &firmware[idx]
+
+		if !strings.EqualFold(fw.Component, component.Name) || !strings.EqualFold(fw.Vendor, component.Vendor) {
+			continue
+		}
+
+		if len(fw.Model) > 0 && !containsFold(fw.Model, serverModel) {
+			continue
+		}
+
+		return idx
+	}
+
+	return -1
+}
+
+func installAction(installed, target string, force bool) (InstallAction, string) {
+	if installed == "" {
+		return InstallUnknown, "installed version unknown"
+	}
+
+	switch c := CompareFirmwareVersions(installed, target); {
+	case c < 0:
+		return InstallUpgrade, ""
+	case c > 0:
+		return InstallDowngrade, ""
+	case force:
+		return InstallReinstall, "forced install of the installed version"
+	default:
+		return InstallSkip, "target version is installed"
+	}
+}
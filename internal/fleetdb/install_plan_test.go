@@ -0,0 +1,88 @@
+package fleetdb
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	common "github.com/metal-toolbox/bmc-common"
+	ss "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+	rt "github.com/metal-toolbox/rivets/v2/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanFirmwareInstall(t *testing.T) {
+	component := func(name, vendor, installed string) *rt.Component {
+		c := &rt.Component{Name: name, Vendor: vendor}
+		if installed != "" {
+			c.Firmware = &common.Firmware{Installed: installed}
+		}
+
+		return c
+	}
+
+	components := []*rt.Component{
+		component("bmc", "dell", "6.10.30.00"),
+		component("bios", "dell", "2.19.1"),
+		component("nic", "mellanox", "26.36.1010"),
+		component("nic", "broadcom", "22.31.6"),
+		component("drive", "micron", "D3MU001"),
+		component("cpld", "dell", ""),
+	}
+
+	firmware := []ss.ComponentFirmwareVersion{
+		{UUID: uuid.New(), Component: "bmc", Vendor: "dell", Version: "7.00.00.00", Model: []string{"r6515", "r6615"}},
+		{UUID: uuid.New(), Component: "bios", Vendor: "dell", Version: "2.19.1"},
+		{UUID: uuid.New(), Component: "bios", Vendor: "dell", Version: "1.0.0", Model: []string{"r7515"}},
+		{UUID: uuid.New(), Component: "nic", Vendor: "broadcom", Version: "22.00.1"},
+		{UUID: uuid.New(), Component: "nic", Vendor: "mellanox", Version: "26.36.1010"},
+		{UUID: uuid.New(), Component: "drive", Vendor: "samsung", Version: "GDC7502Q"},
+		{UUID: uuid.New(), Component: "cpld", Vendor: "dell", Version: "1.0.6"},
+		{UUID: uuid.New(), Component: "storagecontroller", Vendor: "dell", Version: "52.16.1"},
+	}
+
+	// the actions, reasons of the server components, firmware matching no component is left out
+	actions := func(plan []InstallPlanItem) map[string]InstallAction {
+		got := map[string]InstallAction{}
+		for _, item := range plan {
+			if item.Reason != noComponentReason {
+				got[item.Component+"/"+item.Vendor] = item.Action
+			}
+		}
+
+		return got
+	}
+
+	reasons := func(plan []InstallPlanItem) map[string]string {
+		got := map[string]string{}
+		for _, item := range plan {
+			if item.Reason != noComponentReason {
+				got[item.Component+"/"+item.Vendor] = item.Reason
+			}
+		}
+
+		return got
+	}
+
+	plan := PlanFirmwareInstall(components, firmware, "R6515", false)
+	assert.Len(t, plan, 9)
+	assert.Equal(t, map[string]InstallAction{
+		"bios/dell":    InstallSkip,
+		"bmc/dell":     InstallUpgrade,
+		"cpld/dell":    InstallUnknown,
+		"drive/micron": InstallSkip,
+		"nic/mellanox": InstallSkip,
+		"nic/broadcom": InstallDowngrade,
+	}, actions(plan))
+	assert.Equal(t, "no firmware in set", reasons(plan)["drive/micron"], "firmware of another vendor doesn't apply")
+	assert.Equal(t, "bios", plan[0].Component)
+	assert.Equal(t, firmware[1].UUID.String(), plan[0].FirmwareID, "firmware for other models doesn't apply")
+	assert.Equal(t, firmware[0].UUID.String(), plan[2].FirmwareID)
+
+	// the bmc firmware is for other models
+	plan = PlanFirmwareInstall(components, firmware, "r7515", false)
+	assert.Equal(t, "no firmware in set", reasons(plan)["bmc/dell"])
+
+	plan = PlanFirmwareInstall(components, firmware, "r6515", true)
+	assert.Equal(t, InstallReinstall, actions(plan)["bios/dell"])
+	assert.Equal(t, InstallSkip, actions(plan)["drive/micron"])
+}
@@ -0,0 +1,160 @@
+package fleetdb
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/google/uuid"
+	ss "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrServerNotFound  = errors.New("no server matches the reference")
+	ErrServerAmbiguous = errors.New("more than one server matches the reference")
+)
+
+var _ ServerFinder = (*ss.Client)(nil)
+
+// resolveMaxPages limits the number of server pages scanned when matching a server by name.
+const resolveMaxPages = 50
+
+// ServerFinder is the set of fleetdb client methods used to resolve server references.
+type ServerFinder interface {
+	List(ctx context.Context, params *ss.ServerListParams) ([]ss.Server, *ss.ServerResponse, error)
+	GetBomInfoByBMCMacAddr(ctx context.Context, bmcMacAddr string) (*ss.Bom, *ss.ServerResponse, error)
+	GetBomInfoByAOCMacAddr(ctx context.Context, aocMacAddr string) (*ss.Bom, *ss.ServerResponse, error)
+}
+
+// ResolveServer returns the ID of the server identified by the reference, which is one of
+// a server UUID, a BMC or AOC MAC address, a BMC IP address, a chassis serial or a hostname.
+//
+// MAC addresses are resolved to a serial through the BOM information.
+// Serials are matched before hostnames, ErrServerAmbiguous is returned when more than one server matches.
+func ResolveServer(ctx context.Context, client ServerFinder, ref string) (uuid.UUID, error) {
+	ref = strings.TrimSpace(ref)
+
+	if id, err := uuid.Parse(ref); err == nil {
+		return id, nil
+	}
+
+	if mac, err := net.ParseMAC(ref); err == nil {
+		// fleetdb stores MAC addresses in the lower case colon form
+		ref = mac.String()
+
+		serial, err := serialByMAC(ctx, client, ref)
+		if err != nil {
+			return uuid.Nil, err
+		}
+
+		return matchOne(ref, "bmc/aoc mac", serversByAttribute(ctx, client, ServerVendorAttributeNS, "serial", serial))
+	}
+
+	if ip := net.ParseIP(ref); ip != nil {
+		return matchOne(ref, "bmc address", serversByAttribute(ctx, client, ServerAttributeNSBmcAddress, "address", ip.String()))
+	}
+
+	id, err := matchOne(ref, "serial", serversByAttribute(ctx, client, ServerVendorAttributeNS, "serial", ref))
+	if !errors.Is(err, ErrServerNotFound) {
+		return id, err
+	}
+
+	return matchOne(ref, "name", serversByName(ctx, client, ref))
+}
+
+func serialByMAC(ctx context.Context, client ServerFinder, mac string) (string, error) {
+	bom, _, err := client.GetBomInfoByBMCMacAddr(ctx, mac)
+	if err != nil || bom == nil || bom.SerialNum == "" {
+		bom, _, err = client.GetBomInfoByAOCMacAddr(ctx, mac)
+	}
+
+	if err != nil {
+		return "", errors.Wrap(ErrServerNotFound, fmt.Sprintf("%s: bom lookup: %s", mac, err.Error()))
+	}
+
+	if bom == nil || bom.SerialNum == "" {
+		return "", errors.Wrap(ErrServerNotFound, mac+": no bom with this bmc or aoc mac address")
+	}
+
+	return bom.SerialNum, nil
+}
+
+func serversByAttribute(ctx context.Context, client ServerFinder, ns, key, value string) func() ([]ss.Server, error) {
+	return func() ([]ss.Server, error) {
+		params := &ss.ServerListParams{
+			AttributeListParams: []ss.AttributeListParams{
+				{
+					Namespace: ns,
+					Keys:      []string{key},
+					Operator:  "eq",
+					Value:     strings.ToLower(value),
+				},
+			},
+		}
+
+		servers, _, err := client.List(ctx, params)
+
+		return servers, err
+	}
+}
+
+// serversByName scans the server list for hostnames matching the name,
+// fleetdb does not support filtering servers by name.
+func serversByName(ctx context.Context, client ServerFinder, name string) func() ([]ss.Server, error) {
+	return func() ([]ss.Server, error) {
+		found := []ss.Server{}
+
+		for page := 1; page <= resolveMaxPages; page++ {
+			params := &ss.ServerListParams{
+				PaginationParams: &ss.PaginationParams{
+					Limit: ss.MaxPaginationSize,
+					Page:  page,
+				},
+			}
+
+			servers, resp, err := client.List(ctx, params)
+			if err != nil {
+				return nil, err
+			}
+
+			for idx := range servers {
+				if strings.EqualFold(servers[idx].Name, name) {
+					found = append(found, servers[idx])
+				}
+			}
+
+			if resp == nil || page >= resp.TotalPages {
+				break
+			}
+		}
+
+		return found, nil
+	}
+}
+
+func matchOne(ref, kind string, list func() ([]ss.Server, error)) (uuid.UUID, error) {
+	servers, err := list()
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, fmt.Sprintf("server lookup by %s: %s", kind, ref))
+	}
+
+	switch len(servers) {
+	case 0:
+		return uuid.Nil, errors.Wrap(ErrServerNotFound, ref)
+	case 1:
+		return servers[0].UUID, nil
+	}
+
+	candidates := make([]string, 0, len(servers))
+	for idx := range servers {
+		s := ConvertServer(&servers[idx])
+		candidates = append(candidates, fmt.Sprintf("%s (name: %s, serial: %s)", s.ID, s.Name, s.Serial))
+	}
+
+	return uuid.Nil, errors.Wrap(
+		ErrServerAmbiguous,
+		fmt.Sprintf("%s matches %d servers by %s, use a server ID: %s", ref, len(servers), kind, strings.Join(candidates, ", ")),
+	)
+}
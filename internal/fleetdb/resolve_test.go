@@ -0,0 +1,165 @@
+package fleetdb
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	ss "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeFinder struct {
+	servers  []ss.Server
+	bmcBoms  map[string]*ss.Bom
+	aocBoms  map[string]*ss.Bom
+	pageSize int
+}
+
+func (f *fakeFinder) List(_ context.Context, params *ss.ServerListParams) ([]ss.Server, *ss.ServerResponse, error) {
+	if len(params.AttributeListParams) == 0 {
+		// paginated listing
+		page := params.PaginationParams.Page
+		start := (page - 1) * f.pageSize
+		end := min(start+f.pageSize, len(f.servers))
+		total := (len(f.servers) + f.pageSize - 1) / f.pageSize
+
+		return f.servers[start:end], &ss.ServerResponse{TotalPages: total}, nil
+	}
+
+	alp := params.AttributeListParams[0]
+
+	found := []ss.Server{}
+	for _, s := range f.servers {
+		for _, attr := range s.Attributes {
+			if attr.Namespace != alp.Namespace {
+				continue
+			}
+
+			data := map[string]string{}
+			if err := json.Unmarshal(attr.Data, &data); err != nil {
+				return nil, nil, err
+			}
+
+			if data[alp.Keys[0]] == alp.Value {
+				found = append(found, s)
+			}
+		}
+	}
+
+	return found, &ss.ServerResponse{}, nil
+}
+
+func (f *fakeFinder) GetBomInfoByBMCMacAddr(_ context.Context, mac string) (*ss.Bom, *ss.ServerResponse, error) {
+	return f.bmcBoms[mac], &ss.ServerResponse{}, nil
+}
+
+func (f *fakeFinder) GetBomInfoByAOCMacAddr(_ context.Context, mac string) (*ss.Bom, *ss.ServerResponse, error) {
+	return f.aocBoms[mac], &ss.ServerResponse{}, nil
+}
+
+func testServer(name, serial, bmcAddr string) ss.Server {
+	vendorAttrs, _ := json.Marshal(map[string]string{"vendor": "dell", "model": "r6515", "serial": serial})
+	bmcAttrs, _ := json.Marshal(map[string]string{"address": bmcAddr})
+
+	return ss.Server{
+		UUID: uuid.New(),
+		Name: name,
+		Attributes: []ss.Attributes{
+			{Namespace: ServerVendorAttributeNS, Data: vendorAttrs},
+			{Namespace: ServerAttributeNSBmcAddress, Data: bmcAttrs},
+		},
+	}
+}
+
+func TestResolveServer(t *testing.T) {
+	node1 := testServer("node1", "abc123", "10.0.0.1")
+	node2 := testServer("node2", "def456", "10.0.0.2")
+	dup1 := testServer("dup", "ghi789", "10.0.0.3")
+	dup2 := testServer("dup", "jkl012", "10.0.0.3")
+
+	finder := &fakeFinder{
+		servers:  []ss.Server{node1, node2, dup1, dup2},
+		bmcBoms:  map[string]*ss.Bom{"b0:26:28:00:00:01": {SerialNum: "abc123"}},
+		aocBoms:  map[string]*ss.Bom{"b0:26:28:00:00:02": {SerialNum: "def456"}},
+		pageSize: 1,
+	}
+
+	testcases := []struct {
+		name    string
+		ref     string
+		want    uuid.UUID
+		wantErr error
+	}{
+		{
+			name: "uuid",
+			ref:  node1.UUID.String(),
+			want: node1.UUID,
+		},
+		{
+			name: "bmc mac",
+			ref:  "b0:26:28:00:00:01",
+			want: node1.UUID,
+		},
+		{
+			name: "aoc mac",
+			ref:  "b0:26:28:00:00:02",
+			want: node2.UUID,
+		},
+		{
+			name: "upper case dashed mac",
+			ref:  "B0-26-28-00-00-01",
+			want: node1.UUID,
+		},
+		{
+			name:    "unknown mac",
+			ref:     "b0:26:28:00:00:03",
+			wantErr: ErrServerNotFound,
+		},
+		{
+			name: "bmc address",
+			ref:  "10.0.0.2",
+			want: node2.UUID,
+		},
+		{
+			name:    "ambiguous bmc address",
+			ref:     "10.0.0.3",
+			wantErr: ErrServerAmbiguous,
+		},
+		{
+			name: "serial",
+			ref:  "ABC123",
+			want: node1.UUID,
+		},
+		{
+			name: "name on a later page",
+			ref:  "node2",
+			want: node2.UUID,
+		},
+		{
+			name:    "ambiguous name",
+			ref:     "dup",
+			wantErr: ErrServerAmbiguous,
+		},
+		{
+			name:    "not found",
+			ref:     "node3",
+			wantErr: ErrServerNotFound,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ResolveServer(context.Background(), finder, tc.ref)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package fleetdb
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	ss "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+
+	"github.com/metal-toolbox/mctl/internal/inventory"
+)
+
+// InventorySnapshot returns the server inventory at the given time, the latest inventory is returned for a zero time.
+//
+// The BIOS configuration is taken from the versioned attribute history, the latest entry recorded at or before the time is used.
+// FleetDB returns only the latest component firmware versions, components with a firmware version
+// recorded after the time are marked as having an unknown firmware version.
+// Components created after the time are left out of the snapshot.
+func InventorySnapshot(serverID uuid.UUID, components []ss.ServerComponent, biosHistory []ss.VersionedAttributes, at time.Time) (*inventory.Snapshot, error) {
+	snapshot := &inventory.Snapshot{
+		ServerID:   serverID.String(),
+		Time:       at,
+		Components: []*inventory.Component{},
+	}
+
+	if at.IsZero() {
+		snapshot.Time = time.Now().UTC()
+	}
+
+	for idx := range components {
+		rec := &components[idx]
+		if !at.IsZero() && rec.CreatedAt.After(at) {
+			continue
+		}
+
+		c := &inventory.Component{
+			Slug:   rec.ComponentTypeSlug,
+			Vendor: rec.Vendor,
+			Model:  rec.Model,
+			Serial: rec.Serial,
+		}
+
+		fw, fwAt, err := componentFirmware(rec)
+		if err != nil {
+			return nil, err
+		}
+
+		c.Firmware = fw
+		if !at.IsZero() && fwAt.After(at) {
+			c.Firmware = ""
+			c.FirmwareUnknown = true
+		}
+
+		snapshot.Components = append(snapshot.Components, c)
+	}
+
+	bios := biosConfigAt(biosHistory, at)
+	if bios != nil {
+		snapshot.BIOS = map[string]string{}
+		if err := UnpackVersionedAttribute(bios, &snapshot.BIOS); err != nil {
+			return nil, err
+		}
+	}
+
+	return snapshot, nil
+}
+
+// componentFirmware returns the installed firmware version and the time it was recorded,
+// out of band versions are preferred for the same reasons as in RecordToComponent.
+func componentFirmware(rec *ss.ServerComponent) (version string, recorded time.Time, err error) {
+	for _, ns := range []string{FirmwareVersionOutofbandNS, FirmwareVersionInbandNS} {
+		for idx := range rec.VersionedAttributes {
+			va := &rec.VersionedAttributes[idx]
+			if va.Namespace != ns {
+				continue
+			}
+
+			fwva := &FirmwareVersionedAttribute{}
+			if err := UnpackVersionedAttribute(va, fwva); err != nil {
+				return "", time.Time{}, err
+			}
+
+			if fwva.Firmware != nil && fwva.Firmware.Installed != "" {
+				return fwva.Firmware.Installed, va.CreatedAt, nil
+			}
+		}
+	}
+
+	return "", time.Time{}, nil
+}
+
+// biosConfigAt returns the latest BIOS configuration recorded at or before the time.
+func biosConfigAt(history []ss.VersionedAttributes, at time.Time) *ss.VersionedAttributes {
+	var found *ss.VersionedAttributes
+
+	for idx := range history {
+		va := &history[idx]
+		if !at.IsZero() && va.CreatedAt.After(at) {
+			continue
+		}
+
+		if found == nil || va.CreatedAt.After(found.CreatedAt) {
+			found = va
+		}
+	}
+
+	return found
+}
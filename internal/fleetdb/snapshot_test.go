@@ -0,0 +1,106 @@
+package fleetdb
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	ss "github.com/metal-toolbox/fleetdb/pkg/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/metal-toolbox/mctl/internal/inventory"
+)
+
+func firmwareVA(t *testing.T, ns, version string, at time.Time) ss.VersionedAttributes {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]any{"firmware": map[string]string{"installed": version}})
+	require.NoError(t, err)
+
+	return ss.VersionedAttributes{Namespace: ns, Data: data, CreatedAt: at}
+}
+
+func biosVA(t *testing.T, cfg map[string]string, at time.Time) ss.VersionedAttributes {
+	t.Helper()
+
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+
+	return ss.VersionedAttributes{Namespace: BiosConfigInbandNS, Data: data, CreatedAt: at}
+}
+
+func TestInventorySnapshot(t *testing.T) {
+	serverID := uuid.New()
+	day1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+	day3 := day2.Add(24 * time.Hour)
+
+	components := []ss.ServerComponent{
+		{
+			ComponentTypeSlug: "bmc",
+			Vendor:            "dell",
+			CreatedAt:         day1,
+			VersionedAttributes: []ss.VersionedAttributes{
+				firmwareVA(t, FirmwareVersionInbandNS, "5.00", day1),
+				firmwareVA(t, FirmwareVersionOutofbandNS, "5.10", day1),
+			},
+		},
+		{
+			ComponentTypeSlug: "bios",
+			CreatedAt:         day1,
+			VersionedAttributes: []ss.VersionedAttributes{
+				firmwareVA(t, FirmwareVersionOutofbandNS, "2.4.1", day3),
+			},
+		},
+		{
+			ComponentTypeSlug: "drive",
+			Serial:            "d2",
+			CreatedAt:         day3,
+		},
+	}
+
+	// newest first, as returned by fleetdb
+	biosHistory := []ss.VersionedAttributes{
+		biosVA(t, map[string]string{"boot_mode": "UEFI"}, day3),
+		biosVA(t, map[string]string{"boot_mode": "Legacy"}, day1),
+	}
+
+	testcases := []struct {
+		name     string
+		at       time.Time
+		expected []*inventory.Component
+		bios     map[string]string
+	}{
+		{
+			name: "latest",
+			expected: []*inventory.Component{
+				{Slug: "bmc", Vendor: "dell", Firmware: "5.10"},
+				{Slug: "bios", Firmware: "2.4.1"},
+				{Slug: "drive", Serial: "d2"},
+			},
+			bios: map[string]string{"boot_mode": "UEFI"},
+		},
+		{
+			name: "before the firmware install and drive swap",
+			at:   day2,
+			expected: []*inventory.Component{
+				{Slug: "bmc", Vendor: "dell", Firmware: "5.10"},
+				{Slug: "bios", FirmwareUnknown: true},
+			},
+			bios: map[string]string{"boot_mode": "Legacy"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := InventorySnapshot(serverID, components, biosHistory, tc.at)
+			require.NoError(t, err)
+
+			assert.Equal(t, serverID.String(), got.ServerID)
+			assert.Equal(t, tc.expected, got.Components)
+			assert.Equal(t, tc.bios, got.BIOS)
+		})
+	}
+}
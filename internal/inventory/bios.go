@@ -0,0 +1,128 @@
+package inventory
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	// Mismatched is a BIOS setting with a value other than the expected one.
+	Mismatched ChangeKind = "mismatched"
+
+	// Missing is an expected BIOS setting the server doesn't report.
+	Missing ChangeKind = "missing"
+
+	// Extra is a BIOS setting the server reports that isn't expected.
+	Extra ChangeKind = "extra"
+)
+
+// BIOSSettingDiff is a BIOS setting that differs from the expected configuration.
+type BIOSSettingDiff struct {
+	// Key is the setting name in the expected configuration, or on the server for extra settings.
+	Key      string     `json:"key"`
+	Kind     ChangeKind `json:"kind"`
+	Expected string     `json:"expected,omitempty"`
+	Actual   string     `json:"actual,omitempty"`
+}
+
+// biosKeyAliases maps the normalized names vendors give the same setting to a common name,
+// the Dell setting names are used as the common names.
+var biosKeyAliases = map[string]string{
+	// Supermicro
+	"bootmodeselect":                "bootmode",
+	"hyperthreading":                "logicalproc",
+	"smtcontrol":                    "logicalproc",
+	"intelvirtualizationtechnology": "procvirtualization",
+	"svmmode":                       "procvirtualization",
+	"sriovsupport":                  "sriovglobalenable",
+}
+
+// biosToggleAliases maps the Supermicro values of settings that are enabled or disabled to the Dell values.
+var biosToggleAliases = map[string]string{
+	"enable":  "enabled",
+	"disable": "disabled",
+}
+
+// biosValueAliases maps the common setting name to the lower case values vendors use for the same
+// setting value and the common value, the Dell setting values are used as the common values.
+var biosValueAliases = map[string]map[string]string{
+	"bootmode":           {"legacy": "bios"},
+	"logicalproc":        biosToggleAliases,
+	"procvirtualization": biosToggleAliases,
+	"sriovglobalenable":  biosToggleAliases,
+}
+
+// NormalizeBIOSKey returns the setting name in lower case without separators,
+// vendors and inventory collectors name the same setting 'BootMode', 'boot_mode' or 'Boot Mode'.
+// Names vendors give the same setting - 'Boot mode select', 'BootMode' are mapped to a common name.
+func NormalizeBIOSKey(key string) string {
+	normalized := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+
+		return -1
+	}, key)
+
+	if alias, ok := biosKeyAliases[normalized]; ok {
+		return alias
+	}
+
+	return normalized
+}
+
+// normalizeBIOSValue returns the value of the setting with the normalized name in lower case, with the
+// values vendors use for the same setting value - 'Enable', 'Enabled' mapped to a common value.
+func normalizeBIOSValue(key, value string) string {
+	normalized := strings.ToLower(strings.TrimSpace(value))
+	if alias, ok := biosValueAliases[key][normalized]; ok {
+		return alias
+	}
+
+	return normalized
+}
+
+// CompareBIOS returns the settings that differ between the expected and the actual BIOS configuration.
+//
+// Setting names and values are matched after normalizing them.
+func CompareBIOS(expected, actual map[string]string) []BIOSSettingDiff {
+	diffs := []BIOSSettingDiff{}
+
+	actualKeys := make(map[string]string, len(actual))
+	for key := range actual {
+		actualKeys[NormalizeBIOSKey(key)] = key
+	}
+
+	expectedKeys := make(map[string]bool, len(expected))
+
+	for _, key := range sortedKeys(expected) {
+		normalized := NormalizeBIOSKey(key)
+		expectedKeys[normalized] = true
+
+		actualKey, ok := actualKeys[normalized]
+		switch {
+		case !ok:
+			diffs = append(diffs, BIOSSettingDiff{Key: key, Kind: Missing, Expected: expected[key]})
+		case normalizeBIOSValue(normalized, expected[key]) != normalizeBIOSValue(normalized, actual[actualKey]):
+			diffs = append(diffs, BIOSSettingDiff{Key: key, Kind: Mismatched, Expected: expected[key], Actual: actual[actualKey]})
+		}
+	}
+
+	for _, key := range sortedKeys(actual) {
+		if !expectedKeys[NormalizeBIOSKey(key)] {
+			diffs = append(diffs, BIOSSettingDiff{Key: key, Kind: Extra, Actual: actual[key]})
+		}
+	}
+
+	return diffs
+}
+
+// CountBIOSDiffs returns the number of settings of each kind.
+func CountBIOSDiffs(diffs []BIOSSettingDiff) map[ChangeKind]int {
+	counts := map[ChangeKind]int{Mismatched: 0, Missing: 0, Extra: 0}
+	for _, d := range diffs {
+		counts[d.Kind]++
+	}
+
+	return counts
+}
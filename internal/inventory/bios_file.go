@@ -0,0 +1,226 @@
+package inventory
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	bmclibcomm "github.com/metal-toolbox/bmc-common"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrBIOSConfigFile = errors.New("invalid bios config file")
+)
+
+// dellSystemConfiguration is a Dell server configuration profile export.
+type dellSystemConfiguration struct {
+	Components []struct {
+		FQDD       string `xml:"FQDD,attr"`
+		Attributes []struct {
+			Name  string `xml:"Name,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"Attribute"`
+	} `xml:"Component"`
+}
+
+// supermicroBiosCfg is a Supermicro Update Manager BIOS configuration export.
+type supermicroBiosCfg struct {
+	Menus []supermicroMenu `xml:"Menu"`
+}
+
+type supermicroMenu struct {
+	Settings []struct {
+		Name           string `xml:"name,attr"`
+		SelectedOption string `xml:"selectedOption,attr"`
+	} `xml:"Setting"`
+	Menus []supermicroMenu `xml:"Menu"`
+}
+
+// ParseBIOSConfig returns the settings in a BIOS configuration file for a server of the vendor.
+//
+// XML files are accepted for Dell as a server configuration profile and for Supermicro as a SUM BIOS
+// configuration, JSON files for the other vendors, either as an object of settings or a Redfish Bios
+// resource with the settings under 'Attributes'.
+func ParseBIOSConfig(data []byte, vendor string) (map[string]string, error) {
+	var (
+		settings map[string]string
+		err      error
+	)
+
+	trimmed := bytes.TrimSpace(data)
+	vendor = bmclibcomm.FormatVendorName(vendor)
+
+	switch {
+	case len(trimmed) == 0:
+		return nil, errors.Wrap(ErrBIOSConfigFile, "file is empty")
+	case trimmed[0] == '{' && (vendor == bmclibcomm.VendorDell || vendor == bmclibcomm.VendorSupermicro):
+		return nil, errors.Wrap(
+			ErrBIOSConfigFile,
+			fmt.Sprintf("JSON files are not supported for vendor %q, expected the XML export", vendor),
+		)
+	case trimmed[0] == '{':
+		settings, err = parseBIOSConfigJSON(trimmed)
+	case trimmed[0] == '<':
+		settings, err = parseBIOSConfigXML(trimmed, vendor)
+	default:
+		return nil, errors.Wrap(ErrBIOSConfigFile, "expected a JSON or XML file")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(settings) == 0 {
+		return nil, errors.Wrap(ErrBIOSConfigFile, "no settings found")
+	}
+
+	return settings, nil
+}
+
+func parseBIOSConfigJSON(data []byte) (map[string]string, error) {
+	values := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, errors.Wrap(ErrBIOSConfigFile, err.Error())
+	}
+
+	// a Redfish Bios resource
+	if attributes, ok := values["Attributes"]; ok {
+		values = map[string]json.RawMessage{}
+		if err := json.Unmarshal(attributes, &values); err != nil {
+			return nil, errors.Wrap(ErrBIOSConfigFile, "Attributes: "+err.Error())
+		}
+	}
+
+	settings := make(map[string]string, len(values))
+
+	for _, key := range sortedKeys(values) {
+		var value any
+
+		decoder := json.NewDecoder(bytes.NewReader(values[key]))
+		decoder.UseNumber()
+
+		if err := decoder.Decode(&value); err != nil {
+			return nil, errors.Wrap(ErrBIOSConfigFile, key+": "+err.Error())
+		}
+
+		switch v := value.(type) {
+		case string:
+			settings[key] = v
+		case json.Number, bool:
+			settings[key] = fmt.Sprint(v)
+		default:
+			return nil, errors.Wrap(ErrBIOSConfigFile, key+": expected a string, number or boolean value")
+		}
+	}
+
+	return settings, nil
+}
+
+func parseBIOSConfigXML(data []byte, vendor string) (map[string]string, error) {
+	root, err := xmlRoot(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case root == "SystemConfiguration" && vendor == bmclibcomm.VendorDell:
+		return parseDellSystemConfiguration(data)
+	case root == "BiosCfg" && vendor == bmclibcomm.VendorSupermicro:
+		return parseSupermicroBiosCfg(data)
+	default:
+		return nil, errors.Wrap(
+			ErrBIOSConfigFile,
+			fmt.Sprintf("XML file with root element %s is not supported for vendor %q", root, vendor),
+		)
+	}
+}
+
+func xmlRoot(data []byte) (string, error) {
+	decoder := newXMLDecoder(data)
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", errors.Wrap(ErrBIOSConfigFile, err.Error())
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+func parseDellSystemConfiguration(data []byte) (map[string]string, error) {
+	cfg := &dellSystemConfiguration{}
+	if err := newXMLDecoder(data).Decode(cfg); err != nil {
+		return nil, errors.Wrap(ErrBIOSConfigFile, err.Error())
+	}
+
+	settings := map[string]string{}
+
+	for _, component := range cfg.Components {
+		if !strings.HasPrefix(component.FQDD, "BIOS.") {
+			continue
+		}
+
+		for _, attribute := range component.Attributes {
+			settings[attribute.Name] = strings.TrimSpace(attribute.Value)
+		}
+	}
+
+	return settings, nil
+}
+
+func parseSupermicroBiosCfg(data []byte) (map[string]string, error) {
+	cfg := &supermicroBiosCfg{}
+	if err := newXMLDecoder(data).Decode(cfg); err != nil {
+		return nil, errors.Wrap(ErrBIOSConfigFile, err.Error())
+	}
+
+	settings := map[string]string{}
+	supermicroSettings(cfg.Menus, settings)
+
+	return settings, nil
+}
+
+func supermicroSettings(menus []supermicroMenu, settings map[string]string) {
+	for _, menu := range menus {
+		for _, setting := range menu.Settings {
+			if setting.SelectedOption != "" {
+				settings[setting.Name] = setting.SelectedOption
+			}
+		}
+
+		supermicroSettings(menu.Menus, settings)
+	}
+}
+
+// newXMLDecoder returns a decoder for UTF-8 and ISO-8859-1 files, SUM exports are ISO-8859-1 encoded.
+func newXMLDecoder(data []byte) *xml.Decoder {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+		switch strings.ToLower(label) {
+		case "iso-8859-1", "latin1":
+			b, err := io.ReadAll(input)
+			if err != nil {
+				return nil, err
+			}
+
+			// ISO-8859-1 bytes are the first 256 unicode code points
+			runes := make([]rune, len(b))
+			for idx, c := range b {
+				runes[idx] = rune(c)
+			}
+
+			return strings.NewReader(string(runes)), nil
+		default:
+			return nil, errors.Wrap(ErrBIOSConfigFile, "unsupported encoding "+label)
+		}
+	}
+
+	return decoder
+}
@@ -0,0 +1,117 @@
+package inventory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBIOSConfig(t *testing.T) {
+	dellSCP := `<SystemConfiguration Model="PowerEdge R6515">
+  <Component FQDD="BIOS.Setup.1-1">
+    <Attribute Name="BootMode">Uefi</Attribute>
+    <!-- <Attribute Name="ProcVirtualization">Enabled</Attribute> -->
+    <Attribute Name="SriovGlobalEnable">Enabled</Attribute>
+  </Component>
+  <Component FQDD="iDRAC.Embedded.1">
+    <Attribute Name="IPMILan.1#Enable">Enabled</Attribute>
+  </Component>
+</SystemConfiguration>`
+
+	supermicroBiosCfg := `<?xml version="1.0" encoding="ISO-8859-1"?>
+<BiosCfg>
+  <Menu name="Advanced">
+    <Menu name="Boot Feature">
+      <Setting name="Quiet Boot" selectedOption="Enabled" type="Option"/>
+    </Menu>
+    <Setting name="SMT Control" selectedOption="Auto" type="Option"/>
+    <Setting name="Information" type="Information"/>
+  </Menu>
+</BiosCfg>`
+
+	testcases := []struct {
+		name    string
+		data    string
+		vendor  string
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name:   "json settings",
+			data:   `{"BootMode": "Uefi", "NumLock": true, "ProcCores": 8}`,
+			vendor: "asrockrack",
+			want:   map[string]string{"BootMode": "Uefi", "NumLock": "true", "ProcCores": "8"},
+		},
+		{
+			name:   "redfish bios resource",
+			data:   `{"@odata.id": "/redfish/v1/Systems/1/Bios", "Attributes": {"BootMode": "Uefi"}}`,
+			vendor: "asrockrack",
+			want:   map[string]string{"BootMode": "Uefi"},
+		},
+		{
+			name:   "dell server configuration profile",
+			data:   dellSCP,
+			vendor: "Dell Inc.",
+			want:   map[string]string{"BootMode": "Uefi", "SriovGlobalEnable": "Enabled"},
+		},
+		{
+			name:   "supermicro bios config",
+			data:   supermicroBiosCfg,
+			vendor: "Supermicro",
+			want:   map[string]string{"Quiet Boot": "Enabled", "SMT Control": "Auto"},
+		},
+		{
+			name:    "server configuration profile for another vendor",
+			data:    dellSCP,
+			vendor:  "supermicro",
+			wantErr: "root element SystemConfiguration is not supported",
+		},
+		{
+			name:    "json for dell",
+			data:    `{"BootMode": "Uefi"}`,
+			vendor:  "Dell Inc.",
+			wantErr: "JSON files are not supported for vendor \"dell\"",
+		},
+		{
+			name:    "json for supermicro",
+			data:    `{"Attributes": {"BootMode": "Uefi"}}`,
+			vendor:  "Supermicro",
+			wantErr: "JSON files are not supported for vendor \"supermicro\"",
+		},
+		{
+			name:    "nested json value",
+			data:    `{"BootOrder": ["Disk", "NIC"]}`,
+			wantErr: "BootOrder: expected a string",
+		},
+		{
+			name:    "invalid json",
+			data:    `{"BootMode": `,
+			wantErr: "invalid bios config file",
+		},
+		{
+			name:    "no settings",
+			data:    `{"Attributes": {}}`,
+			wantErr: "no settings found",
+		},
+		{
+			name:    "unknown format",
+			data:    "BootMode=Uefi",
+			wantErr: "expected a JSON or XML file",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseBIOSConfig([]byte(tc.data), tc.vendor)
+			if tc.wantErr != "" {
+				require.ErrorIs(t, err, ErrBIOSConfigFile)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package inventory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeBIOSKey(t *testing.T) {
+	for _, key := range []string{"BootMode", "boot_mode", "Boot Mode", "boot-mode", "Boot mode select"} {
+		assert.Equal(t, "bootmode", NormalizeBIOSKey(key), key)
+	}
+
+	for _, key := range []string{"LogicalProc", "Hyper-Threading", "SMT Control"} {
+		assert.Equal(t, "logicalproc", NormalizeBIOSKey(key), key)
+	}
+}
+
+func TestCompareBIOS(t *testing.T) {
+	expected := map[string]string{
+		"boot_mode": "UEFI",
+		"SMT":       "Enabled",
+		"TPM":       "Enabled",
+		"sriov":     "enabled",
+	}
+
+	actual := map[string]string{
+		"BootMode":  "Legacy",
+		"smt":       "enabled",
+		"SR-IOV":    "Enabled",
+		"QuietBoot": "Disabled",
+	}
+
+	// Supermicro names and values for Dell settings
+	expected["LogicalProc"] = "Enabled"
+	expected["ProcVirtualization"] = "Disabled"
+	actual["Hyper-Threading"] = "Enable"
+	actual["SVM Mode"] = "Enabled"
+
+	// values are aliased for the setting they belong to
+	expected["SerialComm"] = "Legacy"
+	actual["serial_comm"] = "BIOS"
+
+	got := CompareBIOS(expected, actual)
+
+	assert.Equal(t, []BIOSSettingDiff{
+		{Key: "ProcVirtualization", Kind: Mismatched, Expected: "Disabled", Actual: "Enabled"},
+		{Key: "SerialComm", Kind: Mismatched, Expected: "Legacy", Actual: "BIOS"},
+		{Key: "TPM", Kind: Missing, Expected: "Enabled"},
+		{Key: "boot_mode", Kind: Mismatched, Expected: "UEFI", Actual: "Legacy"},
+		{Key: "QuietBoot", Kind: Extra, Actual: "Disabled"},
+	}, got)
+
+	assert.Equal(t, map[ChangeKind]int{Mismatched: 3, Missing: 1, Extra: 1}, CountBIOSDiffs(got))
+}
+
+func TestNormalizeBIOSValue(t *testing.T) {
+	assert.Equal(t, "bios", normalizeBIOSValue(NormalizeBIOSKey("Boot Mode Select"), " Legacy"))
+	assert.Equal(t, "enabled", normalizeBIOSValue(NormalizeBIOSKey("Hyper-Threading"), "Enable"))
+	assert.Equal(t, "legacy", normalizeBIOSValue(NormalizeBIOSKey("SerialComm"), "Legacy"))
+	assert.Equal(t, "enable", normalizeBIOSValue(NormalizeBIOSKey("QuietBoot"), "Enable"))
+}
@@ -0,0 +1,116 @@
+package inventory
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	common "github.com/metal-toolbox/bmc-common"
+	rt "github.com/metal-toolbox/rivets/v2/types"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrExpectedInventory = errors.New("expected inventory error")
+)
+
+// hardware is the server inventory compared for component gaps,
+// it holds the fields inventory sources are expected to agree on.
+type hardware struct {
+	Vendor     string                          `json:"vendor"`
+	Model      string                          `json:"model"`
+	Serial     string                          `json:"serial"`
+	Components map[string][]*hardwareComponent `json:"components"`
+}
+
+type hardwareComponent struct {
+	Vendor     string `json:"vendor"`
+	Model      string `json:"model"`
+	Serial     string `json:"serial"`
+	Firmware   string `json:"firmware"`
+	Capacity   int64  `json:"capacity"`
+	Cores      int    `json:"cores"`
+	ClockSpeed int64  `json:"clock_speed"`
+}
+
+// ComponentGaps returns the differences between the inventory of a server and its expected inventory.
+//
+// Components are grouped by slug and matched by serial, values only in the expected inventory are
+// reported as added and values missing from it as removed. Vendor names are compared case insensitively.
+func ComponentGaps(server, expected *rt.Server) []PathChange {
+	return ComparePaths(hardwareFromServer(server), hardwareFromServer(expected))
+}
+
+// ReadServer reads an expected server inventory from a JSON file, in the format of the rivets Server type.
+//
+// A list holding a single server is accepted as well, this is the output of 'mctl get server'.
+func ReadServer(path string) (*rt.Server, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(ErrExpectedInventory, err.Error())
+	}
+
+	if !bytes.HasPrefix(bytes.TrimSpace(b), []byte("[")) {
+		b = append(append([]byte("["), b...), ']')
+	}
+
+	servers := []*rt.Server{}
+	if err := json.Unmarshal(b, &servers); err != nil {
+		return nil, errors.Wrap(ErrExpectedInventory, path+": "+err.Error())
+	}
+
+	if len(servers) != 1 || servers[0] == nil {
+		return nil, errors.Wrap(ErrExpectedInventory, fmt.Sprintf("%s: expected a single server, got %d", path, len(servers)))
+	}
+
+	return servers[0], nil
+}
+
+func hardwareFromServer(server *rt.Server) *hardware {
+	h := &hardware{
+		Vendor:     strings.ToLower(server.Vendor),
+		Model:      server.Model,
+		Serial:     server.Serial,
+		Components: map[string][]*hardwareComponent{},
+	}
+
+	for _, c := range server.Components {
+		slug := strings.ToLower(c.Name)
+		h.Components[slug] = append(h.Components[slug], hardwareFromComponent(c))
+	}
+
+	return h
+}
+
+func hardwareFromComponent(c *rt.Component) *hardwareComponent {
+	hc := &hardwareComponent{
+		Vendor: strings.ToLower(c.Vendor),
+		Model:  c.Model,
+		Serial: c.Serial,
+	}
+
+	if c.Firmware != nil {
+		hc.Firmware = c.Firmware.Installed
+	}
+
+	if c.Attributes == nil {
+		return hc
+	}
+
+	hc.Capacity = c.Attributes.CapacityBytes
+	if hc.Capacity == 0 {
+		hc.Capacity = c.Attributes.SizeBytes
+	}
+
+	hc.Cores = c.Attributes.Cores
+	hc.ClockSpeed = c.Attributes.ClockSpeedHz
+
+	// memory modules report their part number as the model
+	if strings.EqualFold(c.Name, common.SlugPhysicalMem) && c.Attributes.PartNumber != "" {
+		hc.Model = c.Attributes.PartNumber
+	}
+
+	return hc
+}
@@ -0,0 +1,217 @@
+// Package inventory compares server inventories.
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrSnapshot = errors.New("inventory snapshot error")
+)
+
+// Snapshot is the inventory of a server at a point in time.
+type Snapshot struct {
+	// ServerID is the server the inventory was collected from.
+	ServerID string `json:"server_id"`
+
+	// Time is the point in time the inventory is for.
+	Time time.Time `json:"time"`
+
+	// Components are the server hardware components.
+	Components []*Component `json:"components"`
+
+	// BIOS is the BIOS configuration.
+	BIOS map[string]string `json:"bios,omitempty"`
+}
+
+// Component is a server hardware component in a snapshot.
+type Component struct {
+	Slug   string `json:"slug"`
+	Vendor string `json:"vendor,omitempty"`
+	Model  string `json:"model,omitempty"`
+	Serial string `json:"serial,omitempty"`
+
+	// Firmware is the installed firmware version.
+	Firmware string `json:"firmware,omitempty"`
+
+	// FirmwareUnknown is set when the firmware version installed at the snapshot time was not recorded.
+	FirmwareUnknown bool `json:"firmware_unknown,omitempty"`
+}
+
+// ReadSnapshot reads a snapshot from a JSON file.
+func ReadSnapshot(path string) (*Snapshot, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(ErrSnapshot, err.Error())
+	}
+
+	s := &Snapshot{}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, errors.Wrap(ErrSnapshot, path+": "+err.Error())
+	}
+
+	return s, nil
+}
+
+// WriteSnapshot writes the snapshot to a JSON file.
+func WriteSnapshot(path string, s *Snapshot) error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return errors.Wrap(ErrSnapshot, err.Error())
+	}
+
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		return errors.Wrap(ErrSnapshot, err.Error())
+	}
+
+	return nil
+}
+
+// ChangeKind is the kind of difference between two snapshots.
+type ChangeKind string
+
+const (
+	Added   ChangeKind = "added"
+	Removed ChangeKind = "removed"
+	Changed ChangeKind = "changed"
+)
+
+// ComponentChange is a component present in only one of the snapshots.
+type ComponentChange struct {
+	Change ChangeKind `json:"change"`
+	Slug   string     `json:"slug"`
+	Vendor string     `json:"vendor,omitempty"`
+	Model  string     `json:"model,omitempty"`
+	Serial string     `json:"serial,omitempty"`
+}
+
+// FirmwareChange is a component with a different firmware version in the snapshots.
+type FirmwareChange struct {
+	Slug   string `json:"slug"`
+	Serial string `json:"serial,omitempty"`
+	Old    string `json:"old"`
+	New    string `json:"new"`
+}
+
+// SettingChange is a BIOS setting that differs between the snapshots.
+type SettingChange struct {
+	Change ChangeKind `json:"change"`
+	Key    string     `json:"key"`
+	Old    string     `json:"old,omitempty"`
+	New    string     `json:"new,omitempty"`
+}
+
+// Diff lists the differences between two snapshots.
+type Diff struct {
+	From       time.Time         `json:"from"`
+	To         time.Time         `json:"to"`
+	Components []ComponentChange `json:"components"`
+	Firmware   []FirmwareChange  `json:"firmware"`
+	BIOS       []SettingChange   `json:"bios"`
+}
+
+// Empty returns true when the snapshots had no differences.
+func (d *Diff) Empty() bool {
+	return len(d.Components) == 0 && len(d.Firmware) == 0 && len(d.BIOS) == 0
+}
+
+// unknownFirmware is the old version of a firmware change when the version at the snapshot time was not recorded.
+const unknownFirmware = "unknown"
+
+// Compare returns the differences from the older to the newer snapshot.
+//
+// Components are matched by serial, components without a serial are matched by slug in the order listed.
+func Compare(from, to *Snapshot) *Diff {
+	d := &Diff{
+		From:       from.Time,
+		To:         to.Time,
+		Components: []ComponentChange{},
+		Firmware:   []FirmwareChange{},
+		BIOS:       []SettingChange{},
+	}
+
+	fromComponents := componentsByKey(from.Components)
+	toComponents := componentsByKey(to.Components)
+
+	for _, key := range sortedKeys(fromComponents, toComponents) {
+		old, inFrom := fromComponents[key]
+		cur, inTo := toComponents[key]
+
+		switch {
+		case !inTo:
+			d.Components = append(d.Components, componentChange(Removed, old))
+		case !inFrom:
+			d.Components = append(d.Components, componentChange(Added, cur))
+		case old.FirmwareUnknown && !cur.FirmwareUnknown:
+			d.Firmware = append(d.Firmware, FirmwareChange{Slug: cur.Slug, Serial: cur.Serial, Old: unknownFirmware, New: cur.Firmware})
+		case old.Firmware != cur.Firmware && !cur.FirmwareUnknown:
+			d.Firmware = append(d.Firmware, FirmwareChange{Slug: cur.Slug, Serial: cur.Serial, Old: old.Firmware, New: cur.Firmware})
+		}
+	}
+
+	for _, key := range sortedKeys(from.BIOS, to.BIOS) {
+		old, inFrom := from.BIOS[key]
+		cur, inTo := to.BIOS[key]
+
+		switch {
+		case !inTo:
+			d.BIOS = append(d.BIOS, SettingChange{Change: Removed, Key: key, Old: old})
+		case !inFrom:
+			d.BIOS = append(d.BIOS, SettingChange{Change: Added, Key: key, New: cur})
+		case old != cur:
+			d.BIOS = append(d.BIOS, SettingChange{Change: Changed, Key: key, Old: old, New: cur})
+		}
+	}
+
+	return d
+}
+
+func componentChange(kind ChangeKind, c *Component) ComponentChange {
+	return ComponentChange{Change: kind, Slug: c.Slug, Vendor: c.Vendor, Model: c.Model, Serial: c.Serial}
+}
+
+// componentsByKey returns the components keyed by their serial, or slug and position for components without a serial.
+func componentsByKey(components []*Component) map[string]*Component {
+	keyed := make(map[string]*Component, len(components))
+	seen := map[string]int{}
+
+	for _, c := range components {
+		key := "serial:" + strings.ToLower(strings.TrimSpace(c.Serial))
+		if strings.TrimSpace(c.Serial) == "" {
+			key = "slug:" + strings.ToLower(c.Slug)
+		}
+
+		// duplicate keys are told apart by their position
+		n := seen[key]
+		seen[key]++
+
+		keyed[fmt.Sprintf("%s#%d", key, n)] = c
+	}
+
+	return keyed
+}
+
+func sortedKeys[V any](maps ...map[string]V) []string {
+	keys := []string{}
+	seen := map[string]bool{}
+
+	for _, m := range maps {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
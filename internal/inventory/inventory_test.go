@@ -0,0 +1,116 @@
+package inventory
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare(t *testing.T) {
+	testcases := []struct {
+		name     string
+		from     *Snapshot
+		to       *Snapshot
+		expected *Diff
+	}{
+		{
+			name: "no changes",
+			from: &Snapshot{
+				Components: []*Component{{Slug: "drive", Serial: "d1", Firmware: "1.0"}},
+				BIOS:       map[string]string{"boot_mode": "UEFI"},
+			},
+			to: &Snapshot{
+				Components: []*Component{{Slug: "drive", Serial: "D1", Firmware: "1.0"}},
+				BIOS:       map[string]string{"boot_mode": "UEFI"},
+			},
+			expected: &Diff{Components: []ComponentChange{}, Firmware: []FirmwareChange{}, BIOS: []SettingChange{}},
+		},
+		{
+			name: "drive swapped",
+			from: &Snapshot{
+				Components: []*Component{{Slug: "drive", Vendor: "intel", Serial: "d1"}, {Slug: "cpu", Serial: "c1"}},
+			},
+			to: &Snapshot{
+				Components: []*Component{{Slug: "drive", Vendor: "micron", Serial: "d2"}, {Slug: "cpu", Serial: "c1"}},
+			},
+			expected: &Diff{
+				Components: []ComponentChange{
+					{Change: Removed, Slug: "drive", Vendor: "intel", Serial: "d1"},
+					{Change: Added, Slug: "drive", Vendor: "micron", Serial: "d2"},
+				},
+				Firmware: []FirmwareChange{},
+				BIOS:     []SettingChange{},
+			},
+		},
+		{
+			name: "firmware updated",
+			from: &Snapshot{
+				Components: []*Component{
+					{Slug: "bmc", Firmware: "5.10"},
+					{Slug: "bios", FirmwareUnknown: true},
+					{Slug: "nic", Serial: "n1", Firmware: "20.1"},
+				},
+			},
+			to: &Snapshot{
+				Components: []*Component{
+					{Slug: "bmc", Firmware: "6.00"},
+					{Slug: "bios", Firmware: "2.4.1"},
+					{Slug: "nic", Serial: "n1", Firmware: "20.1"},
+				},
+			},
+			expected: &Diff{
+				Components: []ComponentChange{},
+				Firmware: []FirmwareChange{
+					{Slug: "bios", Old: "unknown", New: "2.4.1"},
+					{Slug: "bmc", Old: "5.10", New: "6.00"},
+				},
+				BIOS: []SettingChange{},
+			},
+		},
+		{
+			name: "bios settings",
+			from: &Snapshot{BIOS: map[string]string{"boot_mode": "Legacy", "smt": "Enabled"}},
+			to:   &Snapshot{BIOS: map[string]string{"boot_mode": "UEFI", "tpm": "Enabled"}},
+			expected: &Diff{
+				Components: []ComponentChange{},
+				Firmware:   []FirmwareChange{},
+				BIOS: []SettingChange{
+					{Change: Changed, Key: "boot_mode", Old: "Legacy", New: "UEFI"},
+					{Change: Removed, Key: "smt", Old: "Enabled"},
+					{Change: Added, Key: "tpm", New: "Enabled"},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Compare(tc.from, tc.to)
+			assert.Equal(t, tc.expected, got)
+			assert.Equal(t, len(tc.expected.Components)+len(tc.expected.Firmware)+len(tc.expected.BIOS) == 0, got.Empty())
+		})
+	}
+}
+
+func TestSnapshotFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+
+	s := &Snapshot{
+		ServerID:   "fd5c3a0b-4d2f-4c6c-8e7b-32b0f1d0b4a1",
+		Time:       time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Components: []*Component{{Slug: "bmc", Firmware: "5.10"}},
+		BIOS:       map[string]string{"boot_mode": "UEFI"},
+	}
+
+	require.NoError(t, WriteSnapshot(path, s))
+
+	got, err := ReadSnapshot(path)
+	require.NoError(t, err)
+	assert.Equal(t, s, got)
+
+	_, err = ReadSnapshot(filepath.Join(t.TempDir(), "missing.json"))
+	assert.ErrorIs(t, err, ErrSnapshot)
+}
@@ -0,0 +1,192 @@
+package inventory
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// PathChange is a value that differs between two objects.
+type PathChange struct {
+	// Path locates the value - 'components.drive[S3Z1NB0K].firmware',
+	// slice elements are identified by their serial when set and their index otherwise.
+	Path string     `json:"path"`
+	Kind ChangeKind `json:"kind"`
+	Old  any        `json:"old,omitempty"`
+	New  any        `json:"new,omitempty"`
+}
+
+// ComparePaths returns the values that differ between two objects of the same type.
+//
+// Struct fields are named by their json tag, map entries by their key. Values present only
+// in the newer object are reported as added and values present only in the older one as removed.
+func ComparePaths(from, to any) []PathChange {
+	changes := []PathChange{}
+	comparePaths(&changes, "", reflect.ValueOf(from), reflect.ValueOf(to))
+
+	return changes
+}
+
+//nolint:gocyclo // the value kinds are handled in one place
+func comparePaths(changes *[]PathChange, path string, from, to reflect.Value) {
+	switch {
+	case !isSet(from) && !isSet(to):
+		return
+	case !isSet(from):
+		*changes = append(*changes, PathChange{Path: path, Kind: Added, New: indirect(to).Interface()})
+		return
+	case !isSet(to):
+		*changes = append(*changes, PathChange{Path: path, Kind: Removed, Old: indirect(from).Interface()})
+		return
+	}
+
+	for from.Kind() == reflect.Ptr || from.Kind() == reflect.Interface {
+		from, to = from.Elem(), to.Elem()
+		if !isSet(from) || !isSet(to) {
+			comparePaths(changes, path, from, to)
+			return
+		}
+	}
+
+	if from.Type() != to.Type() {
+		*changes = append(*changes, PathChange{Path: path, Kind: Changed, Old: from.Interface(), New: to.Interface()})
+		return
+	}
+
+	switch from.Kind() {
+	case reflect.Struct:
+		for i := 0; i < from.NumField(); i++ {
+			field := from.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			comparePaths(changes, joinPath(path, fieldName(&field)), from.Field(i), to.Field(i))
+		}
+
+	case reflect.Map:
+		fromKeys := mapKeys(from)
+		toKeys := mapKeys(to)
+
+		for _, key := range sortedKeys(fromKeys, toKeys) {
+			fromValue, toValue := reflect.Value{}, reflect.Value{}
+			if k, ok := fromKeys[key]; ok {
+				fromValue = from.MapIndex(k)
+			}
+
+			if k, ok := toKeys[key]; ok {
+				toValue = to.MapIndex(k)
+			}
+
+			comparePaths(changes, joinPath(path, key), fromValue, toValue)
+		}
+
+	case reflect.Slice, reflect.Array:
+		fromElems := sliceElems(from)
+		toElems := sliceElems(to)
+
+		for _, key := range sortedKeys(fromElems, toElems) {
+			comparePaths(changes, path+key, fromElems[key], toElems[key])
+		}
+
+	default:
+		if !reflect.DeepEqual(from.Interface(), to.Interface()) {
+			*changes = append(*changes, PathChange{Path: path, Kind: Changed, Old: from.Interface(), New: to.Interface()})
+		}
+	}
+}
+
+// isSet returns false for invalid values, nil pointers and interfaces, and empty maps and slices.
+func isSet(v reflect.Value) bool {
+	if !v.IsValid() {
+		return false
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return !v.IsNil()
+	case reflect.Map, reflect.Slice:
+		return v.Len() > 0
+	default:
+		return true
+	}
+}
+
+// indirect returns the value pointed to, so pointer fields are reported by their value.
+func indirect(v reflect.Value) reflect.Value {
+	for (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	return v
+}
+
+func fieldName(field *reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return strings.ToLower(field.Name)
+	}
+
+	return name
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+func mapKeys(m reflect.Value) map[string]reflect.Value {
+	keys := make(map[string]reflect.Value, m.Len())
+	for _, k := range m.MapKeys() {
+		keys[fmt.Sprint(k.Interface())] = k
+	}
+
+	return keys
+}
+
+// sliceElems returns the slice elements keyed by their serial, or by index for elements without a serial.
+func sliceElems(s reflect.Value) map[string]reflect.Value {
+	elems := make(map[string]reflect.Value, s.Len())
+
+	for i := 0; i < s.Len(); i++ {
+		elem := s.Index(i)
+
+		key := fmt.Sprintf("[%d]", i)
+		if serial := elemSerial(elem); serial != "" {
+			key = fmt.Sprintf("[%s]", serial)
+		}
+
+		// serials are not guaranteed to be unique
+		if _, exists := elems[key]; exists {
+			key = fmt.Sprintf("%s[%d]", key, i)
+		}
+
+		elems[key] = elem
+	}
+
+	return elems
+}
+
+func elemSerial(v reflect.Value) string {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+
+	serial := v.FieldByName("Serial")
+	if !serial.IsValid() || serial.Kind() != reflect.String {
+		return ""
+	}
+
+	return strings.ToLower(strings.TrimSpace(serial.String()))
+}
@@ -0,0 +1,135 @@
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	common "github.com/metal-toolbox/bmc-common"
+	rt "github.com/metal-toolbox/rivets/v2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComparePaths(t *testing.T) {
+	type disk struct {
+		Serial   string `json:"serial"`
+		Firmware string `json:"firmware"`
+	}
+
+	type host struct {
+		Name   string            `json:"name"`
+		Disks  []*disk           `json:"disks"`
+		Labels map[string]string `json:"labels,omitempty"`
+		Cores  int
+		OEM    *bool `json:"oem"`
+	}
+
+	oem := true
+
+	testcases := []struct {
+		name     string
+		from     *host
+		to       *host
+		expected []PathChange
+	}{
+		{
+			name:     "equal",
+			from:     &host{Name: "a", Disks: []*disk{{Serial: "d1"}, {Serial: "d2"}}},
+			to:       &host{Name: "a", Disks: []*disk{{Serial: "d2"}, {Serial: "d1"}}},
+			expected: []PathChange{},
+		},
+		{
+			name: "changes",
+			from: &host{
+				Name:   "a",
+				Disks:  []*disk{{Serial: "d1", Firmware: "1.0"}, {Serial: "d2"}},
+				Labels: map[string]string{"rack": "r1"},
+				Cores:  8,
+			},
+			to: &host{
+				Name:   "b",
+				Disks:  []*disk{{Serial: "d1", Firmware: "1.1"}, {Serial: "d3"}},
+				Labels: map[string]string{"rack": "r1", "row": "4"},
+				Cores:  8,
+				OEM:    &oem,
+			},
+			expected: []PathChange{
+				{Path: "name", Kind: Changed, Old: "a", New: "b"},
+				{Path: "disks[d1].firmware", Kind: Changed, Old: "1.0", New: "1.1"},
+				{Path: "disks[d2]", Kind: Removed, Old: disk{Serial: "d2"}},
+				{Path: "disks[d3]", Kind: Added, New: disk{Serial: "d3"}},
+				{Path: "labels.row", Kind: Added, New: "4"},
+				{Path: "oem", Kind: Added, New: true},
+			},
+		},
+		{
+			name:     "empty and nil are equal",
+			from:     &host{Disks: []*disk{}, Labels: map[string]string{}},
+			to:       &host{},
+			expected: []PathChange{},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ComparePaths(tc.from, tc.to))
+		})
+	}
+}
+
+func TestComponentGaps(t *testing.T) {
+	server := &rt.Server{
+		Vendor: "Dell",
+		Components: []*rt.Component{
+			{Name: "Drive", Vendor: "Micron", Serial: "d1", Firmware: &common.Firmware{Installed: "D3MU001"}},
+			{Name: "PhysicalMemory", Model: "m1", Serial: "m1", Attributes: &rt.ComponentAttributes{PartNumber: "M393", SizeBytes: 32}},
+		},
+	}
+
+	expected := &rt.Server{
+		Vendor: "dell",
+		Components: []*rt.Component{
+			{Name: "drive", Vendor: "micron", Serial: "d1", Firmware: &common.Firmware{Installed: "D3MU002"}},
+			{Name: "physicalmemory", Serial: "m1", Attributes: &rt.ComponentAttributes{PartNumber: "M393", SizeBytes: 32}},
+			{Name: "cpu", Model: "EPYC", Attributes: &rt.ComponentAttributes{Cores: 16}},
+		},
+	}
+
+	assert.Equal(t, []PathChange{
+		{Path: "components.cpu", Kind: Added, New: []*hardwareComponent{{Model: "EPYC", Cores: 16}}},
+		{Path: "components.drive[d1].firmware", Kind: Changed, Old: "D3MU001", New: "D3MU002"},
+	}, ComponentGaps(server, expected))
+}
+
+func TestReadServer(t *testing.T) {
+	dir := t.TempDir()
+
+	testcases := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{name: "server", content: `{"name": "node1", "components": [{"name": "drive", "serial": "d1"}]}`},
+		{name: "get server output", content: `[{"name": "node1", "components": [{"name": "drive", "serial": "d1"}]}]`},
+		{name: "more than one server", content: `[{"name": "node1"}, {"name": "node2"}]`, wantErr: true},
+		{name: "invalid", content: `{`, wantErr: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(dir, tc.name+".json")
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0o600))
+
+			got, err := ReadServer(path)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, ErrExpectedInventory)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "node1", got.Name)
+			assert.Equal(t, "d1", got.Components[0].Serial)
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package inventory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	rt "github.com/metal-toolbox/rivets/v2/types"
+)
+
+// missing is the value reported for a component or setting not present on a server.
+const missing = "-"
+
+// Mismatch is a field that differs between two servers.
+type Mismatch struct {
+	// Component identifies the aligned component - 'drive slot 2', 'server' for the server attributes
+	// and 'bios' for the BIOS configuration.
+	Component string `json:"component"`
+
+	// Field is the component field or BIOS setting that differs.
+	Field string `json:"field"`
+
+	Server  string `json:"server"`
+	Against string `json:"against"`
+}
+
+// ServerDiff lists the differences between a server and the server it is compared against.
+type ServerDiff struct {
+	Server     string     `json:"server"`
+	Against    string     `json:"against"`
+	Mismatches []Mismatch `json:"mismatches"`
+}
+
+// Empty returns true when the servers had no differences.
+func (d *ServerDiff) Empty() bool {
+	return len(d.Mismatches) == 0
+}
+
+// CompareServers returns the differences in model, firmware version, capacity and BIOS configuration between two servers.
+//
+// Components are aligned by slug and slot, components without a slot are aligned by slug in the model, serial order.
+func CompareServers(server, against *rt.Server) *ServerDiff {
+	d := &ServerDiff{
+		Server:     serverLabel(server),
+		Against:    serverLabel(against),
+		Mismatches: []Mismatch{},
+	}
+
+	d.compare("server", "vendor", server.Vendor, against.Vendor)
+	d.compare("server", "model", server.Model, against.Model)
+
+	serverComponents := componentsBySlot(server.Components)
+	againstComponents := componentsBySlot(against.Components)
+
+	for _, key := range sortedKeys(serverComponents, againstComponents) {
+		c, onServer := serverComponents[key]
+		a, onAgainst := againstComponents[key]
+
+		switch {
+		case !onAgainst:
+			d.compare(key, "component", componentLabel(c), missing)
+		case !onServer:
+			d.compare(key, "component", missing, componentLabel(a))
+		default:
+			d.compare(key, "vendor", c.Vendor, a.Vendor)
+			d.compare(key, "model", c.Model, a.Model)
+			d.compare(key, "firmware", installedFirmware(c), installedFirmware(a))
+			d.compare(key, "capacity", capacity(c), capacity(a))
+		}
+	}
+
+	for _, key := range sortedKeys(server.BIOSCfg, against.BIOSCfg) {
+		d.compare("bios", key, settingValue(server.BIOSCfg, key), settingValue(against.BIOSCfg, key))
+	}
+
+	return d
+}
+
+func (d *ServerDiff) compare(component, field, server, against string) {
+	if strings.EqualFold(server, against) {
+		return
+	}
+
+	d.Mismatches = append(d.Mismatches, Mismatch{Component: component, Field: field, Server: server, Against: against})
+}
+
+// componentsBySlot returns the components keyed by their slug and slot, or slug and position for components without a slot.
+func componentsBySlot(components []*rt.Component) map[string]*rt.Component {
+	sorted := make([]*rt.Component, len(components))
+	copy(sorted, components)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !strings.EqualFold(sorted[i].Model, sorted[j].Model) {
+			return strings.ToLower(sorted[i].Model) < strings.ToLower(sorted[j].Model)
+		}
+
+		return sorted[i].Serial < sorted[j].Serial
+	})
+
+	keyed := make(map[string]*rt.Component, len(sorted))
+	seen := map[string]int{}
+
+	for _, c := range sorted {
+		slug := strings.ToLower(c.Name)
+
+		if c.Attributes != nil && c.Attributes.Slot != "" {
+			key := fmt.Sprintf("%s slot %s", slug, c.Attributes.Slot)
+			if _, exists := keyed[key]; !exists {
+				keyed[key] = c
+				continue
+			}
+		}
+
+		n := seen[slug]
+		seen[slug]++
+
+		keyed[fmt.Sprintf("%s #%d", slug, n)] = c
+	}
+
+	return keyed
+}
+
+func serverLabel(s *rt.Server) string {
+	if s.Name != "" {
+		return s.Name
+	}
+
+	return s.ID
+}
+
+func componentLabel(c *rt.Component) string {
+	label := strings.TrimSpace(c.Vendor + " " + c.Model)
+	if label == "" {
+		return c.Name
+	}
+
+	return label
+}
+
+func installedFirmware(c *rt.Component) string {
+	if c.Firmware == nil {
+		return ""
+	}
+
+	return c.Firmware.Installed
+}
+
+// capacity returns the drive capacity or memory size of the component.
+func capacity(c *rt.Component) string {
+	if c.Attributes == nil {
+		return ""
+	}
+
+	size := c.Attributes.CapacityBytes
+	if size == 0 {
+		size = c.Attributes.SizeBytes
+	}
+
+	if size <= 0 {
+		return ""
+	}
+
+	return humanize.IBytes(uint64(size))
+}
+
+func settingValue(cfg map[string]string, key string) string {
+	value, ok := cfg[key]
+	if !ok {
+		return missing
+	}
+
+	return value
+}
@@ -0,0 +1,92 @@
+package inventory
+
+import (
+	"testing"
+
+	common "github.com/metal-toolbox/bmc-common"
+	rt "github.com/metal-toolbox/rivets/v2/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareServers(t *testing.T) {
+	drive := func(slot, model, fw string, capacity int64) *rt.Component {
+		return &rt.Component{
+			Name:       "Drive",
+			Vendor:     "micron",
+			Model:      model,
+			Serial:     model + slot,
+			Firmware:   &common.Firmware{Installed: fw},
+			Attributes: &rt.ComponentAttributes{Slot: slot, CapacityBytes: capacity},
+		}
+	}
+
+	server := &rt.Server{
+		Name:   "node1",
+		Vendor: "dell",
+		Model:  "r6515",
+		Components: []*rt.Component{
+			drive("0", "5300", "D3MU001", 960197124096),
+			drive("1", "5300", "D3MU001", 960197124096),
+			{Name: "BMC", Vendor: "dell", Firmware: &common.Firmware{Installed: "5.10"}},
+			{Name: "Physical_Mem", Model: "m1", Serial: "a", Attributes: &rt.ComponentAttributes{SizeBytes: 34359738368}},
+		},
+		BIOSCfg: map[string]string{"boot_mode": "UEFI", "smt": "Enabled"},
+	}
+
+	testcases := []struct {
+		name     string
+		against  *rt.Server
+		expected []Mismatch
+	}{
+		{
+			name: "same inventory",
+			against: &rt.Server{
+				Name:   "node2",
+				Vendor: "Dell",
+				Model:  "r6515",
+				Components: []*rt.Component{
+					{Name: "physical_mem", Model: "m1", Serial: "b", Attributes: &rt.ComponentAttributes{SizeBytes: 34359738368}},
+					{Name: "BMC", Vendor: "dell", Firmware: &common.Firmware{Installed: "5.10"}},
+					drive("1", "5300", "D3MU001", 960197124096),
+					drive("0", "5300", "D3MU001", 960197124096),
+				},
+				BIOSCfg: map[string]string{"boot_mode": "UEFI", "smt": "Enabled"},
+			},
+			expected: []Mismatch{},
+		},
+		{
+			name: "differences",
+			against: &rt.Server{
+				Name:   "node2",
+				Vendor: "dell",
+				Model:  "r6515",
+				Components: []*rt.Component{
+					drive("0", "5300", "D3MU002", 960197124096),
+					drive("2", "5400", "D3MU001", 1920383410176),
+					{Name: "BMC", Vendor: "dell", Firmware: &common.Firmware{Installed: "6.00"}},
+					{Name: "Physical_Mem", Model: "m1", Serial: "b", Attributes: &rt.ComponentAttributes{SizeBytes: 68719476736}},
+				},
+				BIOSCfg: map[string]string{"boot_mode": "Legacy", "tpm": "Enabled"},
+			},
+			expected: []Mismatch{
+				{Component: "bmc #0", Field: "firmware", Server: "5.10", Against: "6.00"},
+				{Component: "drive slot 0", Field: "firmware", Server: "D3MU001", Against: "D3MU002"},
+				{Component: "drive slot 1", Field: "component", Server: "micron 5300", Against: "-"},
+				{Component: "drive slot 2", Field: "component", Server: "-", Against: "micron 5400"},
+				{Component: "physical_mem #0", Field: "capacity", Server: "32 GiB", Against: "64 GiB"},
+				{Component: "bios", Field: "boot_mode", Server: "UEFI", Against: "Legacy"},
+				{Component: "bios", Field: "smt", Server: "Enabled", Against: "-"},
+				{Component: "bios", Field: "tpm", Server: "-", Against: "Enabled"},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := CompareServers(server, tc.against)
+			assert.Equal(t, "node1", got.Server)
+			assert.Equal(t, "node2", got.Against)
+			assert.Equal(t, tc.expected, got.Mismatches)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+// Package lockfile provides exclusive advisory locks on files, serializing mctl processes
+// that update the same local state.
+package lockfile
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+var ErrLocked = errors.New("locked by another process")
+
+// Lock is an exclusive lock on a file, held until it is released or the process exits.
+type Lock struct {
+	file *os.File
+}
+
+// Acquire takes the lock on the file at path, waiting for another process holding it to release it.
+func Acquire(path string) (*Lock, error) {
+	return acquire(path, true)
+}
+
+// TryAcquire takes the lock on the file at path, ErrLocked is returned when another process holds it.
+func TryAcquire(path string) (*Lock, error) {
+	return acquire(path, false)
+}
+
+func acquire(path string, wait bool) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+
+	// the lock file is left in place, removing it would let another process lock a new file
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := lockFile(file, wait); err != nil {
+		file.Close()
+
+		if errors.Is(err, errWouldBlock) {
+			return nil, errors.Wrap(ErrLocked, path)
+		}
+
+		return nil, errors.Wrap(err, path)
+	}
+
+	return &Lock{file: file}, nil
+}
+
+// Release releases the lock.
+func (l *Lock) Release() error {
+	return l.file.Close()
+}
@@ -0,0 +1,26 @@
+package lockfile
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTryAcquire(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", ".lock")
+
+	lock, err := TryAcquire(path)
+	require.NoError(t, err)
+
+	// each open of the file is a separate lock holder
+	_, err = TryAcquire(path)
+	assert.ErrorIs(t, err, ErrLocked)
+
+	require.NoError(t, lock.Release())
+
+	lock, err = Acquire(path)
+	require.NoError(t, err)
+	require.NoError(t, lock.Release())
+}
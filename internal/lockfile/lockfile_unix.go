@@ -0,0 +1,19 @@
+//go:build !windows
+
+package lockfile
+
+import (
+	"os"
+	"syscall"
+)
+
+var errWouldBlock = syscall.EWOULDBLOCK
+
+func lockFile(file *os.File, wait bool) error {
+	how := syscall.LOCK_EX
+	if !wait {
+		how |= syscall.LOCK_NB
+	}
+
+	return syscall.Flock(int(file.Fd()), how)
+}
@@ -0,0 +1,327 @@
+// Package rollout plans staged firmware installs on servers in waves and persists the rollout state
+// so an interrupted rollout can be resumed.
+package rollout
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/metal-toolbox/mctl/internal/lockfile"
+)
+
+// stateDir is the rollout state directory relative to the XDG state directory.
+const stateDir = "mctl/rollouts"
+
+// waveRest is the wave size that includes all remaining servers.
+const waveRest = "rest"
+
+var (
+	ErrWaves = errors.New("invalid rollout waves")
+	ErrState = errors.New("rollout state error")
+)
+
+// Status is the status of a rollout.
+type Status string
+
+const (
+	StatusRunning   Status = "running"
+	StatusHalted    Status = "halted"
+	StatusCompleted Status = "completed"
+)
+
+// TargetState is the state of the install on a server.
+type TargetState string
+
+const (
+	// TargetPending servers have no install condition yet.
+	TargetPending   TargetState = "pending"
+	TargetActive    TargetState = "active"
+	TargetSucceeded TargetState = "succeeded"
+	TargetFailed    TargetState = "failed"
+)
+
+// Target is a server in a rollout wave.
+type Target struct {
+	ServerID    uuid.UUID   `json:"server_id"`
+	Name        string      `json:"name,omitempty"`
+	ConditionID uuid.UUID   `json:"condition_id,omitempty"`
+	State       TargetState `json:"state"`
+	Message     string      `json:"message,omitempty"`
+}
+
+// Wave is a group of servers installed together.
+type Wave struct {
+	Size      string     `json:"size"`
+	Targets   []*Target  `json:"targets"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+}
+
+// Rollout is the persisted state of a firmware set rollout.
+type Rollout struct {
+	ID            uuid.UUID `json:"id"`
+	FirmwareSetID uuid.UUID `json:"firmware_set_id"`
+	// Selector describes the servers selected for the rollout.
+	Selector string `json:"selector"`
+	// MaxFailureRate is the percentage of failed installs in a wave that halts the rollout.
+	MaxFailureRate float64 `json:"max_failure_rate"`
+	// SkipBMCReset, RequireHostPoweredOff are the install options, a resumed rollout uses the same options.
+	SkipBMCReset          bool      `json:"skip_bmc_reset"`
+	RequireHostPoweredOff bool      `json:"require_host_powered_off"`
+	Status                Status    `json:"status"`
+	Waves                 []*Wave   `json:"waves"`
+	CreatedAt             time.Time `json:"created_at"`
+	UpdatedAt             time.Time `json:"updated_at"`
+}
+
+// ParseWaves validates the wave sizes, a size is a number of servers, a percentage of all servers
+// or 'rest' for the remaining servers which is only valid for the last wave.
+func ParseWaves(sizes []string) error {
+	if len(sizes) == 0 {
+		return errors.Wrap(ErrWaves, "at least one wave is required")
+	}
+
+	for idx, size := range sizes {
+		if _, err := waveSize(size, 1, 1); err != nil {
+			return err
+		}
+
+		if strings.EqualFold(size, waveRest) && idx != len(sizes)-1 {
+			return errors.Wrap(ErrWaves, "rest is only valid for the last wave")
+		}
+	}
+
+	return nil
+}
+
+// waveSize returns the number of servers in a wave of the given size.
+func waveSize(size string, total, remaining int) (int, error) {
+	size = strings.TrimSpace(size)
+
+	if strings.EqualFold(size, waveRest) {
+		return remaining, nil
+	}
+
+	if percent, ok := strings.CutSuffix(size, "%"); ok {
+		p, err := strconv.ParseFloat(percent, 64)
+		if err != nil || p <= 0 || p > 100 {
+			return 0, errors.Wrap(ErrWaves, "invalid percentage: "+size)
+		}
+
+		// a percentage of a small number of servers includes at least one server
+		return max(1, int(math.Ceil(float64(total)*p/100))), nil
+	}
+
+	n, err := strconv.Atoi(size)
+	if err != nil || n <= 0 {
+		return 0, errors.Wrap(ErrWaves, "invalid size: "+size)
+	}
+
+	return n, nil
+}
+
+// New returns a rollout of the firmware set on the servers in waves of the given sizes,
+// servers are assigned to waves in the given order. Waves with no servers left are dropped,
+// servers beyond the last wave are not part of the rollout.
+func New(setID uuid.UUID, selector string, servers []Target, sizes []string, maxFailureRate float64) (*Rollout, error) {
+	if err := ParseWaves(sizes); err != nil {
+		return nil, err
+	}
+
+	if len(servers) == 0 {
+		return nil, errors.Wrap(ErrWaves, "no servers selected")
+	}
+
+	if maxFailureRate < 0 || maxFailureRate > 100 {
+		return nil, errors.Wrap(ErrWaves, fmt.Sprintf("invalid max failure rate: %.1f", maxFailureRate))
+	}
+
+	now := time.Now()
+	r := &Rollout{
+		ID:             uuid.New(),
+		FirmwareSetID:  setID,
+		Selector:       selector,
+		MaxFailureRate: maxFailureRate,
+		Status:         StatusRunning,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	next := 0
+
+	for _, size := range sizes {
+		remaining := len(servers) - next
+		if remaining == 0 {
+			break
+		}
+
+		n, err := waveSize(size, len(servers), remaining)
+		if err != nil {
+			return nil, err
+		}
+
+		wave := &Wave{Size: size}
+
+		for _, server := range servers[next : next+min(n, remaining)] {
+			target := server
+			target.State = TargetPending
+			wave.Targets = append(wave.Targets, &target)
+		}
+
+		next += len(wave.Targets)
+		r.Waves = append(r.Waves, wave)
+	}
+
+	return r, nil
+}
+
+// CurrentWave returns the index of the first wave with installs that have not completed or with
+// more failed installs than the rollout allows, -1 is returned when all waves have passed.
+func (r *Rollout) CurrentWave() int {
+	for idx, wave := range r.Waves {
+		if !wave.Done() || r.Exceeded(wave) {
+			return idx
+		}
+	}
+
+	return -1
+}
+
+// Done returns true when the installs on all servers in the wave have completed.
+func (w *Wave) Done() bool {
+	for _, t := range w.Targets {
+		if t.State != TargetSucceeded && t.State != TargetFailed {
+			return false
+		}
+	}
+
+	return true
+}
+
+// FailureRate returns the percentage of servers in the wave with failed installs.
+func (w *Wave) FailureRate() float64 {
+	if len(w.Targets) == 0 {
+		return 0
+	}
+
+	failed := 0
+
+	for _, t := range w.Targets {
+		if t.State == TargetFailed {
+			failed++
+		}
+	}
+
+	return float64(failed) * 100 / float64(len(w.Targets))
+}
+
+// Exceeded returns true when the failure rate of the wave is above the rollout maximum.
+func (r *Rollout) Exceeded(w *Wave) bool {
+	return w.FailureRate() > r.MaxFailureRate
+}
+
+// RetryFailed resets the failed servers in the wave to pending so they are installed again.
+func (w *Wave) RetryFailed() int {
+	retried := 0
+
+	for _, t := range w.Targets {
+		if t.State == TargetFailed {
+			t.State = TargetPending
+			t.ConditionID = uuid.Nil
+			t.Message = ""
+			retried++
+		}
+	}
+
+	return retried
+}
+
+// DefaultDir returns the directory of the rollout state files under the XDG state directory.
+func DefaultDir() string {
+	return filepath.Join(xdg.StateHome, stateDir)
+}
+
+// Store persists rollouts as JSON files in a directory.
+type Store struct {
+	dir string
+}
+
+// NewStore returns a Store keeping rollouts in dir.
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Save writes the rollout state.
+func (s *Store) Save(r *Rollout) error {
+	r.UpdatedAt = time.Now()
+
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return errors.Wrap(ErrState, err.Error())
+	}
+
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return errors.Wrap(ErrState, err.Error())
+	}
+
+	// write to a temporary file and rename so an interrupted write leaves the previous state intact
+	tmp, err := os.CreateTemp(s.dir, ".rollout-*")
+	if err != nil {
+		return errors.Wrap(ErrState, err.Error())
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return errors.Wrap(ErrState, err.Error())
+	}
+
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(ErrState, err.Error())
+	}
+
+	if err := os.Rename(tmp.Name(), s.path(r.ID)); err != nil {
+		return errors.Wrap(ErrState, err.Error())
+	}
+
+	return nil
+}
+
+// Load reads the state of the rollout with the ID.
+func (s *Store) Load(id uuid.UUID) (*Rollout, error) {
+	b, err := os.ReadFile(s.path(id))
+	if err != nil {
+		return nil, errors.Wrap(ErrState, err.Error())
+	}
+
+	r := &Rollout{}
+	if err := json.Unmarshal(b, r); err != nil {
+		return nil, errors.Wrap(ErrState, id.String()+": "+err.Error())
+	}
+
+	return r, nil
+}
+
+// Lock takes the lock on the rollout state, an error is returned when another process is running the rollout.
+func (s *Store) Lock(id uuid.UUID) (*lockfile.Lock, error) {
+	lock, err := lockfile.TryAcquire(filepath.Join(s.dir, id.String()+".lock"))
+	if err != nil {
+		return nil, errors.Wrap(ErrState, "rollout "+id.String()+": "+err.Error())
+	}
+
+	return lock, nil
+}
+
+func (s *Store) path(id uuid.UUID) string {
+	return filepath.Join(s.dir, id.String()+".json")
+}
@@ -0,0 +1,163 @@
+package rollout
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTargets(n int) []Target {
+	targets := make([]Target, 0, n)
+	for range n {
+		targets = append(targets, Target{ServerID: uuid.New()})
+	}
+
+	return targets
+}
+
+func waveLens(r *Rollout) []int {
+	lens := []int{}
+	for _, w := range r.Waves {
+		lens = append(lens, len(w.Targets))
+	}
+
+	return lens
+}
+
+func TestNew(t *testing.T) {
+	testcases := []struct {
+		name    string
+		servers int
+		sizes   []string
+		want    []int
+		wantErr bool
+	}{
+		{
+			name:    "canary, percentages and rest",
+			servers: 100,
+			sizes:   []string{"1", "5%", "25%", "rest"},
+			want:    []int{1, 5, 25, 69},
+		},
+		{
+			name:    "percentage includes at least one server",
+			servers: 10,
+			sizes:   []string{"1", "5%", "rest"},
+			want:    []int{1, 1, 8},
+		},
+		{
+			name:    "waves without servers are dropped",
+			servers: 3,
+			sizes:   []string{"1", "5", "rest"},
+			want:    []int{1, 2},
+		},
+		{
+			name:    "servers beyond the last wave",
+			servers: 10,
+			sizes:   []string{"1", "2"},
+			want:    []int{1, 2},
+		},
+		{
+			name:    "rest before the last wave",
+			servers: 10,
+			sizes:   []string{"rest", "1"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid percentage",
+			servers: 10,
+			sizes:   []string{"150%"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid size",
+			servers: 10,
+			sizes:   []string{"0"},
+			wantErr: true,
+		},
+		{
+			name:    "no servers",
+			sizes:   []string{"rest"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			servers := testTargets(tc.servers)
+
+			r, err := New(uuid.New(), "vendor=dell", servers, tc.sizes, 10)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, ErrWaves)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, waveLens(r))
+			assert.Equal(t, servers[0].ServerID, r.Waves[0].Targets[0].ServerID)
+			assert.Equal(t, TargetPending, r.Waves[0].Targets[0].State)
+		})
+	}
+}
+
+func TestRolloutProgress(t *testing.T) {
+	r, err := New(uuid.New(), "", testTargets(5), []string{"1", "rest"}, 25)
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, r.CurrentWave())
+
+	r.Waves[0].Targets[0].State = TargetSucceeded
+	assert.Equal(t, 1, r.CurrentWave())
+
+	wave := r.Waves[1]
+	wave.Targets[0].State = TargetFailed
+	wave.Targets[1].State = TargetSucceeded
+	assert.False(t, r.Exceeded(wave))
+
+	wave.Targets[2].State = TargetFailed
+	assert.InDelta(t, 50, wave.FailureRate(), 0.01)
+	assert.True(t, r.Exceeded(wave))
+	assert.False(t, wave.Done())
+
+	// a completed wave with too many failures remains the current wave
+	wave.Targets[3].State = TargetSucceeded
+	assert.True(t, wave.Done())
+	assert.Equal(t, 1, r.CurrentWave())
+
+	assert.Equal(t, 2, wave.RetryFailed())
+	assert.Equal(t, TargetPending, wave.Targets[0].State)
+	assert.False(t, wave.Done())
+
+	wave.Targets[0].State = TargetSucceeded
+	wave.Targets[2].State = TargetFailed
+	assert.Equal(t, -1, r.CurrentWave())
+}
+
+func TestStore(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	r, err := New(uuid.New(), "vendor=dell,model=r6515", testTargets(2), []string{"1", "rest"}, 0)
+	require.NoError(t, err)
+
+	r.Waves[0].Targets[0].ConditionID = uuid.New()
+	r.Waves[0].Targets[0].State = TargetActive
+
+	require.NoError(t, store.Save(r))
+
+	got, err := store.Load(r.ID)
+	require.NoError(t, err)
+	assert.Equal(t, r.Waves[0].Targets, got.Waves[0].Targets)
+	assert.Equal(t, r.Selector, got.Selector)
+
+	_, err = store.Load(uuid.New())
+	assert.ErrorIs(t, err, ErrState)
+
+	lock, err := store.Lock(r.ID)
+	require.NoError(t, err)
+
+	_, err = store.Lock(r.ID)
+	assert.ErrorIs(t, err, ErrState, "the rollout is locked")
+
+	require.NoError(t, lock.Release())
+}
@@ -0,0 +1,252 @@
+// Package scheduler stores condition submissions to be made at a later time, the jobs are
+// submitted by 'mctl scheduler run' when they are due.
+package scheduler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+
+	"github.com/metal-toolbox/mctl/internal/lockfile"
+)
+
+// jobsDir is the jobs directory relative to the XDG state directory.
+const jobsDir = "mctl/jobs"
+
+var (
+	ErrJob = errors.New("scheduled job error")
+)
+
+// Status is the status of a scheduled job.
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	// StatusSubmitting jobs are being submitted, a job left submitting by an interrupted scheduler
+	// may have created its condition and is not submitted again.
+	StatusSubmitting Status = "submitting"
+	StatusSubmitted  Status = "submitted"
+	StatusFailed     Status = "failed"
+	StatusCancelled  Status = "cancelled"
+	// StatusExpired jobs were not submitted before the end of their window.
+	StatusExpired Status = "expired"
+)
+
+// Job is a condition to be created on a server at a later time.
+type Job struct {
+	ID uuid.UUID `json:"id"`
+	// Description is a short description of the operation - 'power cycle'.
+	Description string       `json:"description"`
+	ServerID    uuid.UUID    `json:"server_id"`
+	Kind        rctypes.Kind `json:"kind"`
+	// Parameters are the condition parameters.
+	Parameters json.RawMessage `json:"parameters"`
+	// At is the earliest time the job is submitted.
+	At time.Time `json:"at"`
+	// Until is the end of the window, a job not submitted by then expires.
+	Until       *time.Time `json:"until,omitempty"`
+	Status      Status     `json:"status"`
+	User        string     `json:"user,omitempty"`
+	ConditionID uuid.UUID  `json:"condition_id,omitempty"`
+	Message     string     `json:"message,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// NewJob returns a pending job submitting the condition at the given time, a window greater than zero
+// is the time after at the job may still be submitted.
+func NewJob(description string, serverID uuid.UUID, kind rctypes.Kind, parameters any, at time.Time, window time.Duration) (*Job, error) {
+	if window < 0 {
+		return nil, errors.Wrap(ErrJob, "window must not be negative")
+	}
+
+	b, err := json.Marshal(parameters)
+	if err != nil {
+		return nil, errors.Wrap(ErrJob, err.Error())
+	}
+
+	now := time.Now()
+	job := &Job{
+		ID:          uuid.New(),
+		Description: description,
+		ServerID:    serverID,
+		Kind:        kind,
+		Parameters:  b,
+		At:          at,
+		Status:      StatusPending,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if window > 0 {
+		until := at.Add(window)
+		job.Until = &until
+	}
+
+	if job.Until != nil && job.Until.Before(now) {
+		return nil, errors.Wrap(ErrJob, "the window ends in the past: "+job.Until.Format(time.RFC3339))
+	}
+
+	return job, nil
+}
+
+// Due returns true when the job is pending and its time has come.
+func (j *Job) Due(now time.Time) bool {
+	return j.Status == StatusPending && !now.Before(j.At)
+}
+
+// Expired returns true when the job is pending and its window has ended.
+func (j *Job) Expired(now time.Time) bool {
+	return j.Status == StatusPending && j.Until != nil && now.After(*j.Until)
+}
+
+// DefaultDir returns the directory of the job files under the XDG state directory.
+func DefaultDir() string {
+	return filepath.Join(xdg.StateHome, jobsDir)
+}
+
+// Store persists jobs as JSON files in a directory.
+type Store struct {
+	dir string
+}
+
+// NewStore returns a Store keeping jobs in dir.
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Save writes the job.
+func (s *Store) Save(job *Job) error {
+	job.UpdatedAt = time.Now()
+
+	b, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		return errors.Wrap(ErrJob, err.Error())
+	}
+
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return errors.Wrap(ErrJob, err.Error())
+	}
+
+	// write to a temporary file and rename so the scheduler never reads a partial job
+	tmp, err := os.CreateTemp(s.dir, ".job-*")
+	if err != nil {
+		return errors.Wrap(ErrJob, err.Error())
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return errors.Wrap(ErrJob, err.Error())
+	}
+
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(ErrJob, err.Error())
+	}
+
+	if err := os.Rename(tmp.Name(), s.path(job.ID)); err != nil {
+		return errors.Wrap(ErrJob, err.Error())
+	}
+
+	return nil
+}
+
+// Load reads the job with the ID.
+func (s *Store) Load(id uuid.UUID) (*Job, error) {
+	b, err := os.ReadFile(s.path(id))
+	if err != nil {
+		return nil, errors.Wrap(ErrJob, err.Error())
+	}
+
+	job := &Job{}
+	if err := json.Unmarshal(b, job); err != nil {
+		return nil, errors.Wrap(ErrJob, id.String()+": "+err.Error())
+	}
+
+	return job, nil
+}
+
+// List returns the jobs ordered by their time, a store without a directory has no jobs.
+func (s *Store) List() ([]*Job, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, errors.Wrap(ErrJob, err.Error())
+	}
+
+	jobs := []*Job{}
+
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() {
+			continue
+		}
+
+		id, err := uuid.Parse(name)
+		if err != nil {
+			continue
+		}
+
+		job, err := s.Load(id)
+		if err != nil {
+			return nil, err
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	sort.SliceStable(jobs, func(i, j int) bool { return jobs[i].At.Before(jobs[j].At) })
+
+	return jobs, nil
+}
+
+// Lock takes the lock on the store, waiting for another process holding it. Jobs are updated
+// with the lock held so a job is not submitted twice or cancelled while it's submitted.
+func (s *Store) Lock() (*lockfile.Lock, error) {
+	lock, err := lockfile.Acquire(filepath.Join(s.dir, ".lock"))
+	if err != nil {
+		return nil, errors.Wrap(ErrJob, err.Error())
+	}
+
+	return lock, nil
+}
+
+// Cancel cancels the pending job with the ID.
+func (s *Store) Cancel(id uuid.UUID) (*Job, error) {
+	lock, err := s.Lock()
+	if err != nil {
+		return nil, err
+	}
+
+	defer lock.Release()
+
+	job, err := s.Load(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.Status != StatusPending {
+		return nil, errors.Wrap(ErrJob, "only pending jobs can be cancelled, job status: "+string(job.Status))
+	}
+
+	job.Status = StatusCancelled
+
+	return job, s.Save(job)
+}
+
+func (s *Store) path(id uuid.UUID) string {
+	return filepath.Join(s.dir, id.String()+".json")
+}
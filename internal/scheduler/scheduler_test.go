@@ -0,0 +1,81 @@
+package scheduler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobDue(t *testing.T) {
+	at := time.Now().Add(time.Hour)
+
+	job, err := NewJob("power cycle", uuid.New(), rctypes.ServerControl, map[string]string{"action": "cycle"}, at, 2*time.Hour)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"action":"cycle"}`, string(job.Parameters))
+	assert.Equal(t, at.Add(2*time.Hour), *job.Until)
+
+	assert.False(t, job.Due(at.Add(-time.Minute)))
+	assert.True(t, job.Due(at))
+	assert.False(t, job.Expired(at.Add(time.Hour)))
+	assert.True(t, job.Expired(at.Add(3*time.Hour)))
+
+	job.Status = StatusSubmitted
+	assert.False(t, job.Due(at))
+	assert.False(t, job.Expired(at.Add(3*time.Hour)))
+
+	// without a window the job never expires
+	job, err = NewJob("power cycle", uuid.New(), rctypes.ServerControl, nil, at, 0)
+	require.NoError(t, err)
+	assert.Nil(t, job.Until)
+	assert.False(t, job.Expired(at.Add(24*time.Hour)))
+
+	_, err = NewJob("power cycle", uuid.New(), rctypes.ServerControl, nil, time.Now().Add(-2*time.Hour), time.Hour)
+	assert.ErrorIs(t, err, ErrJob)
+}
+
+func TestStore(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(dir)
+
+	jobs, err := store.List()
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+
+	later, err := NewJob("install firmware-set", uuid.New(), rctypes.FirmwareInstall, nil, time.Now().Add(2*time.Hour), 0)
+	require.NoError(t, err)
+
+	sooner, err := NewJob("power cycle", uuid.New(), rctypes.ServerControl, nil, time.Now().Add(time.Hour), 0)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Save(later))
+	require.NoError(t, store.Save(sooner))
+
+	// files that are not jobs are ignored
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0o600))
+
+	jobs, err = store.List()
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, sooner.ID, jobs[0].ID)
+	assert.Equal(t, later.ID, jobs[1].ID)
+
+	cancelled, err := store.Cancel(sooner.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCancelled, cancelled.Status)
+
+	loaded, err := store.Load(sooner.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCancelled, loaded.Status)
+
+	_, err = store.Cancel(sooner.ID)
+	assert.ErrorIs(t, err, ErrJob)
+
+	_, err = store.Cancel(uuid.New())
+	assert.ErrorIs(t, err, ErrJob)
+}
@@ -21,12 +21,18 @@ import (
 	_ "github.com/metal-toolbox/mctl/cmd/collect"
 	_ "github.com/metal-toolbox/mctl/cmd/create"
 	_ "github.com/metal-toolbox/mctl/cmd/delete"
+	_ "github.com/metal-toolbox/mctl/cmd/diff"
 	_ "github.com/metal-toolbox/mctl/cmd/edit"
+	_ "github.com/metal-toolbox/mctl/cmd/firmware"
 	_ "github.com/metal-toolbox/mctl/cmd/generate"
 	_ "github.com/metal-toolbox/mctl/cmd/get"
+	_ "github.com/metal-toolbox/mctl/cmd/history"
 	_ "github.com/metal-toolbox/mctl/cmd/install"
+	_ "github.com/metal-toolbox/mctl/cmd/lint"
 	_ "github.com/metal-toolbox/mctl/cmd/list"
 	_ "github.com/metal-toolbox/mctl/cmd/power"
+	_ "github.com/metal-toolbox/mctl/cmd/rollout"
+	_ "github.com/metal-toolbox/mctl/cmd/scheduler"
 )
 
 func main() {
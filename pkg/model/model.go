@@ -22,6 +22,10 @@ type Config struct {
 	FleetDBAPI *ConfigOIDC `mapstructure:"serverservice_api"` // TODO: implement backwards compatibility and rename.
 	Conditions *ConfigOIDC `mapstructure:"conditions_api"`
 	BomService *ConfigOIDC `mapstructure:"bomservice_api"`
+
+	// DestructiveThreshold is the number of objects a destructive command may act on
+	// before it has to be explicitly allowed with --allow-bulk.
+	DestructiveThreshold int `mapstructure:"destructive_threshold"`
 }
 
 type ConfigOIDC struct {